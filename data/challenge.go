@@ -0,0 +1,45 @@
+package data
+
+import "sort"
+
+// ChallengeResult is the game of a player on the shared puzzle. SessionID is empty until the game is finished, so
+// players cannot watch the games of rivals.
+type ChallengeResult struct {
+	// Rank is 0 if the puzzle is not solved yet.
+	Rank      int                 `json:"rank,omitempty"`
+	SessionID string              `json:"sessionID,omitempty"`
+	Username  string              `json:"username,omitempty"`
+	Status    SudokuSessionStatus `json:"status"`
+	SolveTime Duration            `json:"solveTime,omitempty"`
+	Mistakes  int64               `json:"mistakes"`
+	Score     *SudokuScore        `json:"score,omitempty"`
+}
+
+// IsSolved reports whether the player has solved the puzzle.
+func (r ChallengeResult) IsSolved() bool {
+	return r.Status == SudokuSessionSolved
+}
+
+// RankChallengeResults sorts the results by the solve time and then by mistakes. Games in progress are placed at the
+// end without a rank.
+func RankChallengeResults(results []ChallengeResult) {
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.IsSolved() != b.IsSolved() {
+			return a.IsSolved()
+		}
+		if !a.IsSolved() {
+			return false
+		}
+		if a.SolveTime != b.SolveTime {
+			return a.SolveTime < b.SolveTime
+		}
+		return a.Mistakes < b.Mistakes
+	})
+	for i := range results {
+		results[i].Rank = 0
+		if results[i].IsSolved() {
+			results[i].Rank = i + 1
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRankChallengeResults(t *testing.T) {
+	results := []ChallengeResult{
+		{SessionID: "in progress", Status: SudokuSessionInProgress},
+		{SessionID: "slow", Status: SudokuSessionSolved, SolveTime: Duration(10 * time.Minute)},
+		{SessionID: "fast with mistakes", Status: SudokuSessionSolved, SolveTime: Duration(5 * time.Minute), Mistakes: 2},
+		{SessionID: "fast", Status: SudokuSessionSolved, SolveTime: Duration(5 * time.Minute)},
+	}
+	RankChallengeResults(results)
+	want := []struct {
+		sessionID string
+		rank      int
+	}{
+		{"fast", 1},
+		{"fast with mistakes", 2},
+		{"slow", 3},
+		{"in progress", 0},
+	}
+	for i, w := range want {
+		if results[i].SessionID != w.sessionID || results[i].Rank != w.rank {
+			t.Errorf("results[%d] = %s (rank %d), want %s (rank %d)", i, results[i].SessionID, results[i].Rank, w.sessionID, w.rank)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package data
+
+import "time"
+
+// SudokuCell is a cell of the puzzle filled in by players. The version of the cell is incremented on each change.
+// A move made on an outdated version of the cell is rejected, so simultaneous edits of the same cell do not
+// overwrite each other silently.
+type SudokuCell struct {
+	Point Point `json:"point"`
+	// Digit is 0 if the cell is empty.
+	Digit int8 `json:"digit"`
+	// Pencil marks of the empty cell.
+	Marks   []int8 `json:"marks,omitempty"`
+	Version int64  `json:"version"`
+}
+
+// SudokuMove is a change of one cell made by a player.
+type SudokuMove struct {
+	SudokuCell
+	// Sequence number of the move in the session starting from 1.
+	Seq    int64     `json:"seq"`
+	Player string    `json:"player,omitempty"`
+	At     time.Time `json:"at"`
+}
+
+// CoopPlayer is a participant of the cooperative game.
+type CoopPlayer struct {
+	ID       string `json:"id"`
+	Username string `json:"username,omitempty"`
+	// Color of the player's cursor.
+	Color string `json:"color"`
+}
+
+// CoopColors are colors of cursors assigned to players in the order of joining.
+var CoopColors = []string{
+	"#e6194b", "#3cb44b", "#4363d8", "#f58231", "#911eb4", "#42d4f4", "#f032e6", "#9a6324",
+}
@@ -13,11 +13,54 @@ const (
 	EndpointLogout = "/logout"
 	// EndpointUserInfo is a path to the user's info page.
 	EndpointUserInfo = "/info"
+	// EndpointUserHistory is a path to the user's games history and statistics in JSON.
+	EndpointUserHistory = "/info/history"
 	// EndpointSudokuPlay is a path to the puzzle generator page/handler.
 	EndpointSudokuPlay = "/sudoku/play"
-	endpointSudokuGame = "/sudoku/%s"
+	// EndpointSudokuDaily is a path to the handler of the puzzle of the day.
+	EndpointSudokuDaily = "/sudoku/daily"
+	// EndpointSudokuDailyLeaderboard is a path to the leaderboard page of the puzzle of the day.
+	EndpointSudokuDailyLeaderboard = "/sudoku/daily/leaderboard"
+	// EndpointLeaderboard is a path to the leaderboards page.
+	EndpointLeaderboard = "/leaderboard"
+	// EndpointRaceNew is a path to the handler that creates a race.
+	EndpointRaceNew          = "/race/new"
+	endpointSudokuGame       = "/sudoku/%s"
+	endpointSudokuWatch      = "/sudoku/%s/watch?token=%s"
+	endpointSudokuReplay     = "/sudoku/%s/replay"
+	endpointSudokuSeed       = "/sudoku/seed/%d?level=%s&version=%d"
+	endpointRace             = "/race/%s"
+	endpointChallenge        = "/challenge/%s"
+	endpointChallengeResults = "/challenge/%s/results"
 )
 
 func EndpointSudoku(sudokuID string) string {
 	return fmt.Sprintf(endpointSudokuGame, sudokuID)
 }
+
+// EndpointSudokuWatch is a path to the page of spectators of the session. The token is given to spectators by players.
+func EndpointSudokuWatch(sessionID, token string) string {
+	return fmt.Sprintf(endpointSudokuWatch, sessionID, token)
+}
+
+func EndpointSudokuReplay(sessionID string) string {
+	return fmt.Sprintf(endpointSudokuReplay, sessionID)
+}
+
+// EndpointSudokuSeed is a path to the handler that creates a game of the puzzle generated by the seed and the
+// version of the generator.
+func EndpointSudokuSeed(seed int64, level SudokuLevel, version int) string {
+	return fmt.Sprintf(endpointSudokuSeed, seed, level, version)
+}
+
+func EndpointRace(raceID string) string {
+	return fmt.Sprintf(endpointRace, raceID)
+}
+
+func EndpointChallenge(token string) string {
+	return fmt.Sprintf(endpointChallenge, token)
+}
+
+func EndpointChallengeResults(token string) string {
+	return fmt.Sprintf(endpointChallengeResults, token)
+}
@@ -0,0 +1,142 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Duration is a time interval that is encoded in JSON as a number of milliseconds.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).Milliseconds())
+}
+
+func (d *Duration) UnmarshalJSON(bts []byte) error {
+	var ms int64
+	if err := json.Unmarshal(bts, &ms); err != nil {
+		return err
+	}
+	*d = Duration(time.Duration(ms) * time.Millisecond)
+	return nil
+}
+
+// String returns the interval in the format of the game timer: "m:ss" or "h:mm:ss".
+func (d Duration) String() string {
+	seconds := int64(time.Duration(d) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// SudokuHistoryItem is the user's game in the history.
+type SudokuHistoryItem struct {
+	SessionID string              `json:"sessionID"`
+	SudokuID  int64               `json:"sudokuID"`
+	Level     SudokuLevel         `json:"level"`
+	Status    SudokuSessionStatus `json:"status"`
+	CreatedAt time.Time           `json:"createdAt"`
+	// Game time of the solved puzzle.
+	SolveTime Duration `json:"solveTime,omitempty"`
+	// Score of the solved puzzle.
+	Score *SudokuScore `json:"score,omitempty"`
+}
+
+// IsSolved reports whether the game is won.
+func (i SudokuHistoryItem) IsSolved() bool {
+	return i.Status == SudokuSessionSolved
+}
+
+// IsFinished reports whether the game is over. Games in progress are not counted in the win rate and streaks.
+func (i SudokuHistoryItem) IsFinished() bool {
+	return i.Status != SudokuSessionInProgress
+}
+
+// UserStats are aggregates of the user's history.
+type UserStats struct {
+	// Number of finished games.
+	GamesPlayed int `json:"gamesPlayed"`
+	GamesWon    int `json:"gamesWon"`
+	// Number of games in progress, they are not counted in other statistics.
+	GamesInProgress int `json:"gamesInProgress"`
+	// Share of won games of finished games in the range [0,1].
+	WinRate float64 `json:"winRate"`
+	// Statistics by difficulties from the easiest.
+	Levels []UserLevelStats `json:"levels"`
+	// Number of won games in a row up to the last finished game.
+	CurrentStreak int `json:"currentStreak"`
+	// Maximum number of won games in a row. Games in progress do not break streaks.
+	LongestStreak int `json:"longestStreak"`
+}
+
+// WinRatePercent returns the share of won games as a rounded percentage.
+func (s UserStats) WinRatePercent() int {
+	return int(math.Round(s.WinRate * 100))
+}
+
+// UserLevelStats are aggregates of the user's history on one difficulty.
+type UserLevelStats struct {
+	Level           SudokuLevel `json:"level"`
+	GamesPlayed     int         `json:"gamesPlayed"`
+	GamesWon        int         `json:"gamesWon"`
+	GamesInProgress int         `json:"gamesInProgress"`
+	BestTime        Duration    `json:"bestTime,omitempty"`
+	AverageTime     Duration    `json:"averageTime,omitempty"`
+}
+
+// NewUserStats calculates aggregates of the history sorted by creation date from the oldest game.
+func NewUserStats(history []SudokuHistoryItem) UserStats {
+	stats := UserStats{}
+	levels := make(map[SudokuLevel]*UserLevelStats)
+	totalTimes := make(map[SudokuLevel]time.Duration)
+	for _, level := range SudokuLevels {
+		levels[level] = &UserLevelStats{Level: level}
+	}
+
+	streak := 0
+	for _, item := range history {
+		// games of unknown difficulties are only included in the total statistics
+		levelStats, ok := levels[item.Level]
+		if !ok {
+			levelStats = &UserLevelStats{}
+		}
+		if !item.IsFinished() {
+			stats.GamesInProgress++
+			levelStats.GamesInProgress++
+			continue
+		}
+		stats.GamesPlayed++
+		levelStats.GamesPlayed++
+
+		if !item.IsSolved() {
+			streak = 0
+			continue
+		}
+		stats.GamesWon++
+		streak++
+		if streak > stats.LongestStreak {
+			stats.LongestStreak = streak
+		}
+		levelStats.GamesWon++
+		totalTimes[item.Level] += time.Duration(item.SolveTime)
+		if levelStats.BestTime == 0 || item.SolveTime < levelStats.BestTime {
+			levelStats.BestTime = item.SolveTime
+		}
+	}
+	stats.CurrentStreak = streak
+	if stats.GamesPlayed > 0 {
+		stats.WinRate = float64(stats.GamesWon) / float64(stats.GamesPlayed)
+	}
+
+	for _, level := range SudokuLevels {
+		levelStats := levels[level]
+		if levelStats.GamesWon > 0 {
+			levelStats.AverageTime = Duration(totalTimes[level] / time.Duration(levelStats.GamesWon))
+		}
+		stats.Levels = append(stats.Levels, *levelStats)
+	}
+	return stats
+}
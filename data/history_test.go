@@ -0,0 +1,128 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNewUserStats(t *testing.T) {
+	solved := func(level SudokuLevel, solveTime time.Duration) SudokuHistoryItem {
+		return SudokuHistoryItem{Level: level, Status: SudokuSessionSolved, SolveTime: Duration(solveTime)}
+	}
+	inProgress := func(level SudokuLevel) SudokuHistoryItem {
+		return SudokuHistoryItem{Level: level, Status: SudokuSessionInProgress}
+	}
+
+	// lost is a finished game that is not won
+	lost := func(level SudokuLevel) SudokuHistoryItem {
+		return SudokuHistoryItem{Level: level, Status: "lost"}
+	}
+
+	stats := NewUserStats([]SudokuHistoryItem{
+		solved(SudokuLevelEasy, 5*time.Minute),
+		solved(SudokuLevelEasy, 7*time.Minute),
+		solved(SudokuLevelMedium, 10*time.Minute),
+		inProgress(SudokuLevelHard),
+		solved(SudokuLevelEasy, 3*time.Minute),
+		solved(SudokuLevelHard, 30*time.Minute),
+		inProgress(SudokuLevelMedium),
+	})
+	if stats.GamesPlayed != 5 || stats.GamesWon != 5 || stats.GamesInProgress != 2 {
+		t.Errorf("want played/won/in progress 5/5/2. Got: %d/%d/%d", stats.GamesPlayed, stats.GamesWon, stats.GamesInProgress)
+	}
+	if stats.WinRate != 1 {
+		t.Errorf("want win rate 1. Got: %f", stats.WinRate)
+	}
+	// abandoned games do not break streaks
+	if stats.LongestStreak != 5 {
+		t.Errorf("want longest streak 5. Got: %d", stats.LongestStreak)
+	}
+	if stats.CurrentStreak != 5 {
+		t.Errorf("want current streak 5. Got: %d", stats.CurrentStreak)
+	}
+	if len(stats.Levels) != len(SudokuLevels) {
+		t.Fatalf("want %d levels. Got: %d", len(SudokuLevels), len(stats.Levels))
+	}
+	easy := stats.Levels[0]
+	if easy.Level != SudokuLevelEasy || easy.GamesPlayed != 3 || easy.GamesWon != 3 || easy.GamesInProgress != 0 {
+		t.Errorf("wrong easy stats: %+v", easy)
+	}
+	if easy.BestTime != Duration(3*time.Minute) || easy.AverageTime != Duration(5*time.Minute) {
+		t.Errorf("want easy best/average 3:00/5:00. Got: %s/%s", easy.BestTime, easy.AverageTime)
+	}
+	if hard := stats.Levels[2]; hard.GamesPlayed != 1 || hard.GamesWon != 1 || hard.GamesInProgress != 1 || hard.BestTime != Duration(30*time.Minute) {
+		t.Errorf("wrong hard stats: %+v", hard)
+	}
+
+	tests := []struct {
+		name                         string
+		history                      []SudokuHistoryItem
+		played, won, inProgress      int
+		winRate                      float64
+		currentStreak, longestStreak int
+	}{
+		{name: "empty"},
+		{
+			name:       "only in progress",
+			history:    []SudokuHistoryItem{inProgress(SudokuLevelEasy), inProgress(SudokuLevelHard)},
+			inProgress: 2,
+		},
+		{
+			name: "lost between in progress",
+			history: []SudokuHistoryItem{
+				solved(SudokuLevelEasy, time.Minute), inProgress(SudokuLevelEasy), solved(SudokuLevelEasy, time.Minute),
+				lost(SudokuLevelEasy), inProgress(SudokuLevelEasy), solved(SudokuLevelEasy, time.Minute),
+			},
+			played: 4, won: 3, inProgress: 2, winRate: 0.75, currentStreak: 1, longestStreak: 2,
+		},
+		{
+			name:    "last finished is lost",
+			history: []SudokuHistoryItem{solved(SudokuLevelEasy, time.Minute), lost(SudokuLevelEasy), inProgress(SudokuLevelEasy)},
+			played:  2, won: 1, inProgress: 1, winRate: 0.5, currentStreak: 0, longestStreak: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := NewUserStats(tt.history)
+			if stats.GamesPlayed != tt.played || stats.GamesWon != tt.won || stats.GamesInProgress != tt.inProgress {
+				t.Errorf("want played/won/in progress %d/%d/%d. Got: %d/%d/%d", tt.played, tt.won, tt.inProgress,
+					stats.GamesPlayed, stats.GamesWon, stats.GamesInProgress)
+			}
+			if stats.WinRate != tt.winRate {
+				t.Errorf("want win rate %f. Got: %f", tt.winRate, stats.WinRate)
+			}
+			if stats.CurrentStreak != tt.currentStreak || stats.LongestStreak != tt.longestStreak {
+				t.Errorf("want current/longest streak %d/%d. Got: %d/%d", tt.currentStreak, tt.longestStreak,
+					stats.CurrentStreak, stats.LongestStreak)
+			}
+			if len(stats.Levels) != len(SudokuLevels) {
+				t.Errorf("want %d levels. Got: %d", len(SudokuLevels), len(stats.Levels))
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	d := Duration(time.Hour + 2*time.Minute + 3*time.Second + 400*time.Millisecond)
+	if s := d.String(); s != "1:02:03" {
+		t.Errorf("want string 1:02:03. Got: %s", s)
+	}
+	if s := Duration(65 * time.Second).String(); s != "1:05" {
+		t.Errorf("want string 1:05. Got: %s", s)
+	}
+	bts, err := json.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(bts) != "3723400" {
+		t.Errorf("want JSON 3723400. Got: %s", bts)
+	}
+	var got Duration
+	if err := json.Unmarshal(bts, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got != d {
+		t.Errorf("want %d after unmarshal. Got: %d", d, got)
+	}
+}
@@ -0,0 +1,45 @@
+package data
+
+import "fmt"
+
+// DailyLeaderboardItem is the user's result in the leaderboard of the puzzle of the day.
+type DailyLeaderboardItem struct {
+	Rank      int      `json:"rank"`
+	UserID    int64    `json:"userID"`
+	Username  string   `json:"username"`
+	SolveTime Duration `json:"solveTime"`
+	Mistakes  int64    `json:"mistakes"`
+}
+
+// LeaderboardPeriod is a period of time for which the points are summed up in the leaderboard.
+type LeaderboardPeriod string
+
+const (
+	LeaderboardAllTime LeaderboardPeriod = "all"
+	LeaderboardWeekly  LeaderboardPeriod = "week"
+	LeaderboardMonthly LeaderboardPeriod = "month"
+)
+
+// LeaderboardPeriods is a list of all periods of the leaderboards.
+var LeaderboardPeriods = []LeaderboardPeriod{LeaderboardAllTime, LeaderboardWeekly, LeaderboardMonthly}
+
+// ParseLeaderboardPeriod returns the period by its name. An empty string is all-time period.
+func ParseLeaderboardPeriod(s string) (LeaderboardPeriod, error) {
+	if s == "" {
+		return LeaderboardAllTime, nil
+	}
+	for _, period := range LeaderboardPeriods {
+		if string(period) == s {
+			return period, nil
+		}
+	}
+	return "", fmt.Errorf("unknown period '%s'", s)
+}
+
+// LeaderboardItem is the user's total points of completed games in the leaderboard.
+type LeaderboardItem struct {
+	Rank     int    `json:"rank"`
+	UserID   int64  `json:"userID"`
+	Username string `json:"username"`
+	Points   int64  `json:"points"`
+}
@@ -0,0 +1,41 @@
+package data
+
+// RacePlayer is a player of the race and the progress of the player's session.
+type RacePlayer struct {
+	// SessionID is empty until the race is finished, so players cannot watch the games of rivals.
+	SessionID string `json:"sessionID,omitempty"`
+	Username  string `json:"username,omitempty"`
+	// Percentage of correctly filled empty cells of the puzzle.
+	Progress  int      `json:"progress"`
+	Solved    bool     `json:"solved,omitempty"`
+	SolveTime Duration `json:"solveTime,omitempty"`
+}
+
+// RaceState is the state of the race that is sent to all players.
+type RaceState struct {
+	RaceID  string       `json:"raceID"`
+	Players []RacePlayer `json:"players"`
+	// Session ID of the winner. Empty if nobody has solved the puzzle yet.
+	Winner string `json:"winner,omitempty"`
+}
+
+// SudokuProgress returns the percentage of correctly filled cells of the state among the empty cells of the puzzle.
+func SudokuProgress(puzzle, state, board SudokuPuzzle) int {
+	var empty, filled int
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			p := Point{Row: row, Col: col}
+			if puzzle.In(p) != 0 {
+				continue
+			}
+			empty++
+			if v := state.In(p); v != 0 && v == board.In(p) {
+				filled++
+			}
+		}
+	}
+	if empty == 0 {
+		return 100
+	}
+	return filled * 100 / empty
+}
@@ -0,0 +1,35 @@
+package data
+
+import "time"
+
+// SudokuReplay is the recorded game of the session for the replay viewer.
+type SudokuReplay struct {
+	SessionID string              `json:"sessionID"`
+	Puzzle    string              `json:"puzzle"`
+	Status    SudokuSessionStatus `json:"status"`
+	StartedAt time.Time           `json:"startedAt"`
+	SolveTime Duration            `json:"solveTime"`
+	Moves     []SudokuReplayMove  `json:"moves"`
+}
+
+// SudokuReplayMove is a move of the replay with the time since the start of the game.
+type SudokuReplayMove struct {
+	SudokuMove
+	Offset Duration `json:"offset"`
+}
+
+// NewSudokuReplayMoves calculates offsets of moves from the start of the game.
+func NewSudokuReplayMoves(startedAt time.Time, moves []SudokuMove) []SudokuReplayMove {
+	replayMoves := make([]SudokuReplayMove, 0, len(moves))
+	for _, move := range moves {
+		var offset time.Duration
+		if move.At.After(startedAt) {
+			offset = move.At.Sub(startedAt)
+		}
+		replayMoves = append(replayMoves, SudokuReplayMove{
+			SudokuMove: move,
+			Offset:     Duration(offset),
+		})
+	}
+	return replayMoves
+}
@@ -0,0 +1,31 @@
+package data
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNewSudokuReplayMoves(t *testing.T) {
+	start := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	moves := []SudokuMove{
+		{SudokuCell: SudokuCell{Point: Point{Row: 0, Col: 0}, Digit: 5}, Seq: 1, At: start.Add(1500 * time.Millisecond)},
+		// the clock of the move is before the start of the game
+		{SudokuCell: SudokuCell{Point: Point{Row: 8, Col: 8}, Marks: []int8{1, 2}}, Seq: 2, At: start.Add(-time.Second)},
+	}
+	got := NewSudokuReplayMoves(start, moves)
+	if len(got) != 2 {
+		t.Fatalf("want 2 moves. Got: %d", len(got))
+	}
+	if got[0].Offset != Duration(1500*time.Millisecond) || got[1].Offset != 0 {
+		t.Fatalf("wrong offsets: %s, %s", got[0].Offset, got[1].Offset)
+	}
+	bts, err := json.Marshal(got[0])
+	if err != nil {
+		t.Fatalf("failed to marshal move: %v", err)
+	}
+	const want = `{"point":"a1","digit":5,"version":0,"seq":1,"at":"2022-03-01T12:00:01.5Z","offset":1500}`
+	if string(bts) != want {
+		t.Fatalf("json.Marshal() =\n%s\nwant\n%s", bts, want)
+	}
+}
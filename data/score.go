@@ -0,0 +1,72 @@
+package data
+
+import "time"
+
+// SudokuScoreVersion is the version of the formula used for new scores.
+const SudokuScoreVersion = 1
+
+// SudokuScore is the result of a completed game.
+type SudokuScore struct {
+	// Version of the formula. Scores of different versions are not comparable.
+	Version int `json:"version"`
+	// Number of points.
+	Points int64 `json:"points"`
+}
+
+// SudokuSessionStats are counters of the user's actions in the sudoku session.
+type SudokuSessionStats struct {
+	// Number of digits that do not match the solution.
+	Mistakes int64 `json:"mistakes"`
+	// Number of cells revealed by the server.
+	Hints int64 `json:"hints"`
+	// Number of canceled steps.
+	Undos int64 `json:"undos"`
+}
+
+// sudokuScoreFormulas are all formulas by their versions. The formulas of old versions must not be changed.
+var sudokuScoreFormulas = map[int]func(level SudokuLevel, solveTime time.Duration, stats SudokuSessionStats) int64{
+	1: sudokuScoreV1,
+}
+
+// NewSudokuScore calculates the score of the game with the current formula.
+func NewSudokuScore(level SudokuLevel, solveTime time.Duration, stats SudokuSessionStats) SudokuScore {
+	score, _ := NewSudokuScoreVersion(SudokuScoreVersion, level, solveTime, stats)
+	return score
+}
+
+// NewSudokuScoreVersion calculates the score of the game with the formula of the version.
+func NewSudokuScoreVersion(version int, level SudokuLevel, solveTime time.Duration, stats SudokuSessionStats) (SudokuScore, bool) {
+	formula, ok := sudokuScoreFormulas[version]
+	if !ok {
+		return SudokuScore{}, false
+	}
+	return SudokuScore{
+		Version: version,
+		Points:  formula(level, solveTime, stats),
+	}, true
+}
+
+// sudokuScoreV1 gives base points of the level for solving in the target time. A faster solution gives up to twice
+// as many points, a slower one gives less. Each mistake, hint and undo is a penalty. The minimum is a tenth of the
+// base points.
+func sudokuScoreV1(level SudokuLevel, solveTime time.Duration, stats SudokuSessionStats) int64 {
+	var base int64
+	var target time.Duration
+	switch level {
+	case SudokuLevelEasy:
+		base, target = 1000, 10*time.Minute
+	case SudokuLevelHard:
+		base, target = 3000, 20*time.Minute
+	default:
+		base, target = 2000, 15*time.Minute
+	}
+	if solveTime < 0 {
+		solveTime = 0
+	}
+	points := base * 2 * int64(target/time.Second) / int64((target+solveTime)/time.Second)
+	points -= stats.Mistakes*50 + stats.Hints*150 + stats.Undos*10
+	if min := base / 10; points < min {
+		points = min
+	}
+	return points
+}
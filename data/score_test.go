@@ -0,0 +1,69 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSudokuScoreVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   int
+		level     SudokuLevel
+		solveTime time.Duration
+		stats     SudokuSessionStats
+		want      int64
+	}{
+		{
+			name:      "v1 medium in target time",
+			version:   1,
+			level:     SudokuLevelMedium,
+			solveTime: 15 * time.Minute,
+			want:      2000,
+		},
+		{
+			name:      "v1 easy instantly",
+			version:   1,
+			level:     SudokuLevelEasy,
+			solveTime: 0,
+			want:      2000,
+		},
+		{
+			name:      "v1 hard with penalties",
+			version:   1,
+			level:     SudokuLevelHard,
+			solveTime: 60 * time.Minute,
+			stats:     SudokuSessionStats{Mistakes: 2, Hints: 1, Undos: 5},
+			want:      1500 - 100 - 150 - 50,
+		},
+		{
+			name:      "v1 minimum",
+			version:   1,
+			level:     SudokuLevelEasy,
+			solveTime: 10 * time.Hour,
+			stats:     SudokuSessionStats{Mistakes: 100},
+			want:      100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := NewSudokuScoreVersion(tt.version, tt.level, tt.solveTime, tt.stats)
+			if !ok {
+				t.Fatalf("version %d not found", tt.version)
+			}
+			if got.Version != tt.version {
+				t.Errorf("want version %d. Got: %d", tt.version, got.Version)
+			}
+			if got.Points != tt.want {
+				t.Errorf("want points %d. Got: %d", tt.want, got.Points)
+			}
+		})
+	}
+
+	if _, ok := NewSudokuScoreVersion(0, SudokuLevelEasy, 0, SudokuSessionStats{}); ok {
+		t.Errorf("version 0 must not exist")
+	}
+	if got := NewSudokuScore(SudokuLevelMedium, 0, SudokuSessionStats{}); got.Version != SudokuScoreVersion {
+		t.Errorf("NewSudokuScore() uses version %d instead of %d", got.Version, SudokuScoreVersion)
+	}
+}
@@ -19,9 +19,42 @@ type SudokuPuzzle interface {
 	String() string
 	FindUserErrors() []Point
 	FindErrors(target SudokuPuzzle) []Point
+	FindChangedHints(hints SudokuPuzzle) []Point
+	IsCorrectSolve() bool
 	In(point Point) int8
 }
 
+// SudokuLevel is a difficulty of the puzzle.
+type SudokuLevel string
+
+const (
+	SudokuLevelEasy   SudokuLevel = "easy"
+	SudokuLevelMedium SudokuLevel = "medium"
+	SudokuLevelHard   SudokuLevel = "hard"
+)
+
+// SudokuLevels is a list of all difficulties from the easiest.
+var SudokuLevels = []SudokuLevel{SudokuLevelEasy, SudokuLevelMedium, SudokuLevelHard}
+
+// ParseSudokuLevel returns the difficulty by its name. An empty string is the medium difficulty.
+func ParseSudokuLevel(s string) (SudokuLevel, error) {
+	if s == "" {
+		return SudokuLevelMedium, nil
+	}
+	for _, level := range SudokuLevels {
+		if string(level) == s {
+			return level, nil
+		}
+	}
+	return "", fmt.Errorf("unknown level '%s'", s)
+}
+
+// SudokuVariant is a kind of the puzzle by its rules.
+type SudokuVariant string
+
+// SudokuVariantClassic is the classic 9x9 puzzle.
+const SudokuVariantClassic SudokuVariant = "classic"
+
 // DirectionType is a direction of line/"big" line/some kind of field change.
 type DirectionType uint8
 
@@ -48,6 +81,24 @@ func (p Point) MarshalJSON() ([]byte, error) {
 	return json.Marshal(p.String())
 }
 
+func (p *Point) UnmarshalJSON(bts []byte) error {
+	var s string
+	if err := json.Unmarshal(bts, &s); err != nil {
+		return err
+	}
+	point, err := PointFromString(s)
+	if err != nil {
+		return err
+	}
+	*p = point
+	return nil
+}
+
+// IsValid reports whether the point is inside the 9x9 grid.
+func (p Point) IsValid() bool {
+	return 0 <= p.Row && p.Row < 9 && 0 <= p.Col && p.Col < 9
+}
+
 func (p Point) InSameBox(points ...Point) bool {
 	boxRow, boxCol := p.Row/3, p.Col/3
 	for _, ip := range points {
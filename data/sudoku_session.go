@@ -0,0 +1,152 @@
+package data
+
+import "time"
+
+// SudokuSessionStatus is a state of the game in the sudoku session.
+type SudokuSessionStatus string
+
+const (
+	// SudokuSessionInProgress means that the puzzle has not yet been solved.
+	SudokuSessionInProgress SudokuSessionStatus = "in_progress"
+	// SudokuSessionSolved means that the user filled in the puzzle correctly.
+	SudokuSessionSolved SudokuSessionStatus = "solved"
+)
+
+// SudokuSessionTimer is the game timer of the sudoku session. The timer is only changed on the server side, the
+// client receives the elapsed time.
+type SudokuSessionTimer struct {
+	// Date and time of the first request of the puzzle. Zero if the game has not started.
+	StartedAt time.Time `json:"startedAt"`
+	// Date and time of the start of the current running interval. Zero if the timer is paused.
+	ResumedAt time.Time `json:"resumedAt"`
+	// Date and time of the last user action. It is used to automatically pause an idle game.
+	LastActivityAt time.Time `json:"lastActivityAt"`
+	// Game time accumulated in previous intervals.
+	Elapsed time.Duration `json:"elapsed"`
+	// Date and time of the pause requested by the player. Zero if the timer is running or paused automatically.
+	PlayerPausedAt time.Time `json:"playerPausedAt"`
+	// Time of finished pauses requested by the player. It is counted in ranked games, see RankedTime.
+	PlayerPauses time.Duration `json:"playerPauses"`
+	// Date and time of the automatic pause of the idle game. Zero if the timer is running or paused by the player.
+	IdlePausedAt time.Time `json:"idlePausedAt"`
+	// Time of finished automatic pauses. It is counted in ranked games like pauses requested by the player.
+	IdlePauses time.Duration `json:"idlePauses"`
+}
+
+// IsStarted reports whether the game has started.
+func (t SudokuSessionTimer) IsStarted() bool {
+	return !t.StartedAt.IsZero()
+}
+
+// IsPaused reports whether the timer is stopped.
+func (t SudokuSessionTimer) IsPaused() bool {
+	return t.ResumedAt.IsZero()
+}
+
+// IsPausedByPlayer reports whether the timer is stopped on the player's request.
+func (t SudokuSessionTimer) IsPausedByPlayer() bool {
+	return !t.PlayerPausedAt.IsZero()
+}
+
+// Start starts the timer if the game has not started yet.
+func (t *SudokuSessionTimer) Start(now time.Time) {
+	if t.IsStarted() {
+		return
+	}
+	t.StartedAt, t.ResumedAt, t.LastActivityAt = now, now, now
+}
+
+// Pause stops the timer and accumulates the time of the current interval.
+func (t *SudokuSessionTimer) Pause(now time.Time) {
+	if t.IsPaused() {
+		return
+	}
+	if now.After(t.ResumedAt) {
+		t.Elapsed += now.Sub(t.ResumedAt)
+	}
+	t.ResumedAt = time.Time{}
+	t.LastActivityAt = now
+}
+
+// PauseByPlayer stops the timer on the player's request. The board stays on the client during the pause, so the time
+// of such pauses is counted in ranked games.
+func (t *SudokuSessionTimer) PauseByPlayer(now time.Time) {
+	if t.IsPaused() {
+		return
+	}
+	t.Pause(now)
+	t.PlayerPausedAt = now
+}
+
+// Resume starts a new interval of the timer if it is paused.
+func (t *SudokuSessionTimer) Resume(now time.Time) {
+	t.Start(now)
+	if t.IsPaused() {
+		t.PlayerPauses += t.playerPause(now)
+		t.PlayerPausedAt = time.Time{}
+		t.IdlePauses += t.idlePause(now)
+		t.IdlePausedAt = time.Time{}
+		t.ResumedAt = now
+	}
+	t.LastActivityAt = now
+}
+
+// Touch registers the user's activity. If the user has been idle longer than idleTimeout, the time since the last
+// activity is not counted.
+func (t *SudokuSessionTimer) Touch(now time.Time, idleTimeout time.Duration) {
+	t.autoPause(now, idleTimeout)
+	t.Resume(now)
+}
+
+// Duration returns the game time at the moment now.
+func (t SudokuSessionTimer) Duration(now time.Time, idleTimeout time.Duration) time.Duration {
+	t.autoPause(now, idleTimeout)
+	t.Pause(now)
+	return t.Elapsed
+}
+
+// RankedTime returns the time of the game for leaderboards at the moment now. Unlike Duration, pauses requested by the
+// player and automatic pauses of the idle game are counted: the player cannot study the board for free, on pause or
+// without touching it.
+func (t SudokuSessionTimer) RankedTime(now time.Time, idleTimeout time.Duration) time.Duration {
+	t.autoPause(now, idleTimeout)
+	return t.Duration(now, idleTimeout) + t.PlayerPauses + t.playerPause(now) + t.IdlePauses + t.idlePause(now)
+}
+
+// playerPause returns the time of the current pause requested by the player.
+func (t SudokuSessionTimer) playerPause(now time.Time) time.Duration {
+	if t.PlayerPausedAt.IsZero() || !now.After(t.PlayerPausedAt) {
+		return 0
+	}
+	return now.Sub(t.PlayerPausedAt)
+}
+
+// idlePause returns the time of the current automatic pause.
+func (t SudokuSessionTimer) idlePause(now time.Time) time.Duration {
+	if t.IdlePausedAt.IsZero() || !now.After(t.IdlePausedAt) {
+		return 0
+	}
+	return now.Sub(t.IdlePausedAt)
+}
+
+// IsIdle reports whether the timer is running but the user has been idle longer than idleTimeout.
+func (t SudokuSessionTimer) IsIdle(now time.Time, idleTimeout time.Duration) bool {
+	return !t.IsPaused() && idleTimeout > 0 && now.Sub(t.LastActivityAt) > idleTimeout
+}
+
+// PauseIdle pauses the running timer at the moment of the last activity. The time of the pause is counted in ranked
+// games, see RankedTime.
+func (t *SudokuSessionTimer) PauseIdle() {
+	if t.IsPaused() {
+		return
+	}
+	t.Pause(t.LastActivityAt)
+	t.IdlePausedAt = t.LastActivityAt
+}
+
+// autoPause pauses the timer at the moment of the last activity if the user has been idle longer than idleTimeout.
+func (t *SudokuSessionTimer) autoPause(now time.Time, idleTimeout time.Duration) {
+	if t.IsIdle(now, idleTimeout) {
+		t.PauseIdle()
+	}
+}
@@ -0,0 +1,147 @@
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSudokuSessionTimer(t *testing.T) {
+	const idle = time.Minute
+	start := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time {
+		return start.Add(d)
+	}
+
+	var timer SudokuSessionTimer
+	if timer.IsStarted() {
+		t.Fatalf("zero timer is started")
+	}
+	if d := timer.Duration(at(time.Hour), idle); d != 0 {
+		t.Fatalf("duration of zero timer is %s", d)
+	}
+
+	t.Run("Start()", func(t *testing.T) {
+		timer.Start(start)
+		if !timer.IsStarted() || timer.IsPaused() {
+			t.Fatalf("timer is not running after start")
+		}
+		timer.Start(at(10 * time.Second))
+		if !timer.StartedAt.Equal(start) {
+			t.Fatalf("second start changed StartedAt to %s", timer.StartedAt)
+		}
+		if d := timer.Duration(at(30*time.Second), idle); d != 30*time.Second {
+			t.Fatalf("want duration 30s. Got: %s", d)
+		}
+	})
+
+	t.Run("Pause()", func(t *testing.T) {
+		timer.Pause(at(40 * time.Second))
+		if !timer.IsPaused() {
+			t.Fatalf("timer is not paused")
+		}
+		if d := timer.Duration(at(time.Hour), idle); d != 40*time.Second {
+			t.Fatalf("want duration 40s. Got: %s", d)
+		}
+	})
+
+	t.Run("Resume()", func(t *testing.T) {
+		timer.Resume(at(2 * time.Minute))
+		if timer.IsPaused() {
+			t.Fatalf("timer is paused after resume")
+		}
+		if d := timer.Duration(at(2*time.Minute+20*time.Second), idle); d != time.Minute {
+			t.Fatalf("want duration 1m. Got: %s", d)
+		}
+	})
+
+	t.Run("Touch()", func(t *testing.T) {
+		timer.Touch(at(2*time.Minute+50*time.Second), idle)
+		if d := timer.Duration(at(2*time.Minute+50*time.Second), idle); d != 90*time.Second {
+			t.Fatalf("want duration 1m30s. Got: %s", d)
+		}
+		if timer.IsIdle(at(3*time.Minute), idle) {
+			t.Fatalf("timer is idle right after activity")
+		}
+		if !timer.IsIdle(at(4*time.Minute), idle) {
+			t.Fatalf("timer is not idle after the idle timeout")
+		}
+		// the user is idle: the time after the last activity is not counted
+		if d := timer.Duration(at(time.Hour), idle); d != 90*time.Second {
+			t.Fatalf("want duration of idle game 1m30s. Got: %s", d)
+		}
+		timer.Touch(at(time.Hour), idle)
+		if d := timer.Duration(at(time.Hour+10*time.Second), idle); d != 100*time.Second {
+			t.Fatalf("want duration 1m40s. Got: %s", d)
+		}
+	})
+}
+
+func TestSudokuSessionTimer_RankedTime(t *testing.T) {
+	const idle = time.Minute
+	start := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time {
+		return start.Add(d)
+	}
+
+	var timer SudokuSessionTimer
+	timer.Start(start)
+	timer.PauseByPlayer(at(30 * time.Second))
+	if !timer.IsPaused() {
+		t.Fatalf("timer is not paused")
+	}
+	if d := timer.RankedTime(at(50*time.Second), idle); d != 50*time.Second {
+		t.Fatalf("want ranked time of paused game 50s. Got: %s", d)
+	}
+	timer.Touch(at(2*time.Minute), idle)
+	if d := timer.Duration(at(2*time.Minute+10*time.Second), idle); d != 40*time.Second {
+		t.Fatalf("want duration 40s. Got: %s", d)
+	}
+	if d := timer.RankedTime(at(2*time.Minute+10*time.Second), idle); d != 2*time.Minute+10*time.Second {
+		t.Fatalf("want ranked time 2m10s. Got: %s", d)
+	}
+	// the time of the automatic pause is counted
+	timer.Touch(at(time.Hour), idle)
+	if d := timer.RankedTime(at(time.Hour), idle); d != time.Hour {
+		t.Fatalf("want ranked time after idle 1h. Got: %s", d)
+	}
+	if d := timer.Duration(at(time.Hour), idle); d != 30*time.Second {
+		t.Fatalf("want duration after idle 30s. Got: %s", d)
+	}
+}
+
+// The player opens the puzzle, studies it without touching longer than the idle timeout and sends the solution in
+// one step.
+func TestSudokuSessionTimer_RankedTimeIdle(t *testing.T) {
+	const idle = 3 * time.Minute
+	start := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time {
+		return start.Add(d)
+	}
+
+	var timer SudokuSessionTimer
+	timer.Touch(start, idle)
+	// the idle game is paused by the server before the solution
+	paused := timer
+	paused.PauseIdle()
+	for _, tt := range []struct {
+		name  string
+		timer SudokuSessionTimer
+	}{
+		{name: "running", timer: timer},
+		{name: "paused", timer: paused},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			timer := tt.timer
+			if d := timer.RankedTime(at(time.Hour), idle); d != time.Hour {
+				t.Errorf("RankedTime() before the step = %s, want 1h", d)
+			}
+			timer.Touch(at(time.Hour), idle)
+			if d := timer.RankedTime(at(time.Hour+time.Second), idle); d != time.Hour+time.Second {
+				t.Errorf("RankedTime() after the step = %s, want 1h0m1s", d)
+			}
+			if d := timer.Duration(at(time.Hour+time.Second), idle); d != time.Second {
+				t.Errorf("Duration() after the step = %s, want 1s", d)
+			}
+		})
+	}
+}
@@ -3,4 +3,6 @@ package data
 type UserInfo struct {
 	Username string `json:"-"`
 	Name     string `json:"name"`
+	// The best score of completed games with the latest version of the formula.
+	BestScore SudokuScore `json:"bestScore"`
 }
@@ -1,17 +1,25 @@
 module github.com/cnblvr/sudoku
 
-go 1.17
+go 1.21
 
 require (
+	github.com/alicebob/miniredis/v2 v2.37.0
 	github.com/gomodule/redigo v1.8.8
 	github.com/gorilla/mux v1.8.0
 	github.com/gorilla/securecookie v1.1.1
 	github.com/gorilla/websocket v1.5.0
 	github.com/rs/zerolog v1.26.1
-	golang.org/x/crypto v0.0.0-20220313003712-b769efc7c000
+	github.com/satori/go.uuid v1.2.0
+	golang.org/x/crypto v0.24.0
+	google.golang.org/grpc v1.64.1
+	google.golang.org/protobuf v1.33.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/satori/go.uuid v1.2.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
+	golang.org/x/net v0.26.0 // indirect
+	golang.org/x/sys v0.21.0 // indirect
+	golang.org/x/text v0.16.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
 )
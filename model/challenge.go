@@ -0,0 +1,73 @@
+package model
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+)
+
+// tokenSize is the number of random bytes of tokens of shared links.
+const tokenSize = 16
+
+// newToken returns the random token of the shared link.
+func newToken() (string, error) {
+	buf := make([]byte, tokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// SudokuByChallengeToken returns the puzzle shared by the challenge link with the token. Returns false if the token is
+// unknown.
+func SudokuByChallengeToken(conn redis.Conn, token string) (Sudoku, bool, error) {
+	id, err := redis.Int64(conn.Do("GET", keyChallenge(token)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return Sudoku{}, false, nil
+	default:
+		return Sudoku{}, false, err
+	}
+	return SudokuByID(conn, id)
+}
+
+// ChallengeToken returns the random token of the challenge link of the puzzle, so links cannot be enumerated by
+// sequential IDs of puzzles. The token is created on the first call.
+func (s Sudoku) ChallengeToken() (string, error) {
+	token, err := redis.String(s.conn.Do("GET", keySudokuChallengeToken(s.id)))
+	switch err {
+	case nil:
+		return token, nil
+	case redis.ErrNil:
+	default:
+		return "", err
+	}
+	if token, err = newToken(); err != nil {
+		return "", err
+	}
+	if _, err := s.conn.Do("SET", keyChallenge(token), s.id); err != nil {
+		return "", err
+	}
+	isSet, err := redis.Bool(s.conn.Do("SETNX", keySudokuChallengeToken(s.id), token))
+	if err != nil {
+		return "", err
+	}
+	if !isSet {
+		// a parallel request has created the token
+		if _, err := s.conn.Do("DEL", keyChallenge(token)); err != nil {
+			return "", err
+		}
+		return s.ChallengeToken()
+	}
+	return token, nil
+}
+
+func keyChallenge(token string) string {
+	return fmt.Sprintf("challenge:%s", token)
+}
+
+func keySudokuChallengeToken(id int64) string {
+	return fmt.Sprintf("%s:challenge_token", keySudoku(id))
+}
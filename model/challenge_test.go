@@ -0,0 +1,48 @@
+package model
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"regexp"
+	"testing"
+)
+
+func TestSudoku_ChallengeToken(t *testing.T) {
+	conn := testConn(t)
+	sudoku, err := NewSudoku(conn, "1", "1", data.SudokuLevelEasy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := sudoku.ChallengeToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(token) {
+		t.Errorf("ChallengeToken() = %s, want 32 hex digits", token)
+	}
+	if again, err := sudoku.ChallengeToken(); err != nil {
+		t.Fatal(err)
+	} else if again != token {
+		t.Errorf("repeated ChallengeToken() = %s, want %s", again, token)
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		want   int64
+		wantOk bool
+	}{
+		{name: "known", token: token, want: sudoku.ID(), wantOk: true},
+		{name: "unknown", token: "00000000000000000000000000000000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := SudokuByChallengeToken(conn, tt.token)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOk || got.ID() != tt.want {
+				t.Errorf("SudokuByChallengeToken() = %d, %v, want %d, %v", got.ID(), ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
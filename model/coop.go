@@ -0,0 +1,258 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/gomodule/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+	"sort"
+	"time"
+)
+
+// applySudokuMoveScript changes the cell of the state if the version of the cell has not changed since the player
+// saw it. The script returns {applied (0 or 1), version of the cell, sequence number of the move}.
+var applySudokuMoveScript = redis.NewScript(4, `
+local version = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+if version ~= tonumber(ARGV[2]) then
+	return {0, version, 0}
+end
+version = redis.call('HINCRBY', KEYS[1], ARGV[1], 1)
+redis.call('SETRANGE', KEYS[2], ARGV[3], ARGV[4])
+if ARGV[5] == '' then
+	redis.call('HDEL', KEYS[3], ARGV[1])
+else
+	redis.call('HSET', KEYS[3], ARGV[1], ARGV[5])
+end
+local seq = redis.call('RPUSH', KEYS[4], ARGV[6])
+return {1, version, seq}
+`)
+
+// SetCoop makes the session cooperative: several players fill in the same state.
+func (s SudokuSession) SetCoop() error {
+	_, err := s.conn.Do("SET", keySudokuSessionCoop(s.id), 1)
+	return err
+}
+
+// IsCoop reports whether the session is cooperative.
+func (s SudokuSession) IsCoop() (bool, error) {
+	isCoop, err := redis.Bool(s.conn.Do("GET", keySudokuSessionCoop(s.id)))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+	return isCoop, err
+}
+
+// CoopToken returns the random token of the invitation link of the cooperative session. Only clients with the token
+// join the game of another player. The token is created on the first call.
+func (s SudokuSession) CoopToken() (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.conn.Do("SETNX", keySudokuSessionCoopToken(s.id), token); err != nil {
+		return "", err
+	}
+	// a parallel request could have created the token
+	return redis.String(s.conn.Do("GET", keySudokuSessionCoopToken(s.id)))
+}
+
+// JoinCoop adds the player to the cooperative session and assigns the color of the cursor. A player who has already
+// joined keeps the color.
+func (s SudokuSession) JoinCoop(playerID string, username string) (data.CoopPlayer, error) {
+	playerBts, err := redis.Bytes(s.conn.Do("HGET", keySudokuSessionPlayers(s.id), playerID))
+	switch err {
+	case nil:
+		var player data.CoopPlayer
+		if err := json.Unmarshal(playerBts, &player); err != nil {
+			return data.CoopPlayer{}, err
+		}
+		return player, nil
+	case redis.ErrNil:
+	default:
+		return data.CoopPlayer{}, err
+	}
+	n, err := redis.Int(s.conn.Do("HLEN", keySudokuSessionPlayers(s.id)))
+	if err != nil {
+		return data.CoopPlayer{}, err
+	}
+	player := data.CoopPlayer{
+		ID:       playerID,
+		Username: username,
+		Color:    data.CoopColors[n%len(data.CoopColors)],
+	}
+	if playerBts, err = json.Marshal(player); err != nil {
+		return data.CoopPlayer{}, err
+	}
+	isSet, err := redis.Bool(s.conn.Do("HSETNX", keySudokuSessionPlayers(s.id), playerID, playerBts))
+	if err != nil {
+		return data.CoopPlayer{}, err
+	}
+	if !isSet {
+		// a parallel request has joined the player
+		return s.JoinCoop(playerID, username)
+	}
+	return player, nil
+}
+
+// CoopPlayers returns all players who have joined the cooperative session.
+func (s SudokuSession) CoopPlayers() ([]data.CoopPlayer, error) {
+	values, err := redis.ByteSlices(s.conn.Do("HVALS", keySudokuSessionPlayers(s.id)))
+	if err != nil {
+		return nil, err
+	}
+	players := make([]data.CoopPlayer, 0, len(values))
+	for _, playerBts := range values {
+		var player data.CoopPlayer
+		if err := json.Unmarshal(playerBts, &player); err != nil {
+			return nil, err
+		}
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].ID < players[j].ID
+	})
+	return players, nil
+}
+
+// Cells returns the changed cells of the state with pencil marks and versions.
+func (s SudokuSession) Cells() ([]data.SudokuCell, error) {
+	versions, err := redis.Int64Map(s.conn.Do("HGETALL", keySudokuSessionCellVersions(s.id)))
+	if err != nil {
+		return nil, err
+	}
+	marks, err := redis.StringMap(s.conn.Do("HGETALL", keySudokuSessionMarks(s.id)))
+	if err != nil {
+		return nil, err
+	}
+	state, err := s.State()
+	if err != nil {
+		return nil, err
+	}
+	var cells []data.SudokuCell
+	for pointStr, version := range versions {
+		point, err := data.PointFromString(pointStr)
+		if err != nil {
+			return nil, err
+		}
+		cells = append(cells, newSudokuCell(point, state, marks[pointStr], version))
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Point.Row != cells[j].Point.Row {
+			return cells[i].Point.Row < cells[j].Point.Row
+		}
+		return cells[i].Point.Col < cells[j].Point.Col
+	})
+	return cells, nil
+}
+
+// Cell returns the current value of the cell of the state.
+func (s SudokuSession) Cell(point data.Point) (data.SudokuCell, error) {
+	version, err := redis.Int64(s.conn.Do("HGET", keySudokuSessionCellVersions(s.id), point.String()))
+	if err != nil && err != redis.ErrNil {
+		return data.SudokuCell{}, err
+	}
+	marks, err := redis.String(s.conn.Do("HGET", keySudokuSessionMarks(s.id), point.String()))
+	if err != nil && err != redis.ErrNil {
+		return data.SudokuCell{}, err
+	}
+	state, err := s.State()
+	if err != nil {
+		return data.SudokuCell{}, err
+	}
+	return newSudokuCell(point, state, marks, version), nil
+}
+
+// ApplyMove changes the cell of the state if the cell still has the version that the player saw. Returns false if
+// another player has changed the cell.
+func (s SudokuSession) ApplyMove(move data.SudokuMove) (data.SudokuMove, bool, error) {
+	if move.At.IsZero() {
+		move.At = time.Now()
+	}
+	expectedVersion := move.Version
+	// the move is saved with the version of the cell after the move, the sequence number is the index in the list
+	move.Version, move.Seq = expectedVersion+1, 0
+	moveBts, err := json.Marshal(move)
+	if err != nil {
+		return data.SudokuMove{}, false, err
+	}
+	digit := "."
+	if move.Digit > 0 {
+		digit = fmt.Sprint(move.Digit)
+	}
+	values, err := redis.Int64s(applySudokuMoveScript.Do(s.conn,
+		keySudokuSessionCellVersions(s.id),
+		keySudokuSessionState(s.id),
+		keySudokuSessionMarks(s.id),
+		keySudokuSessionMoves(s.id),
+		move.Point.String(),
+		expectedVersion,
+		move.Point.Row*9+move.Point.Col,
+		digit,
+		marksToString(move.Marks),
+		moveBts,
+	))
+	if err != nil {
+		return data.SudokuMove{}, false, err
+	}
+	if len(values) != 3 {
+		return data.SudokuMove{}, false, fmt.Errorf("unexpected result of script: %v", values)
+	}
+	move.Version = values[1]
+	if values[0] == 0 {
+		return move, false, nil
+	}
+	move.Seq = values[2]
+	return move, true, nil
+}
+
+func newSudokuCell(point data.Point, state string, marks string, version int64) data.SudokuCell {
+	cell := data.SudokuCell{
+		Point:   point,
+		Marks:   marksFromString(marks),
+		Version: version,
+	}
+	if i := point.Row*9 + point.Col; i < len(state) && '1' <= state[i] && state[i] <= '9' {
+		cell.Digit = int8(state[i] - '0')
+	}
+	return cell
+}
+
+func marksToString(marks []int8) string {
+	bts := make([]byte, 0, len(marks))
+	for _, m := range marks {
+		bts = append(bts, '0'+byte(m))
+	}
+	return string(bts)
+}
+
+func marksFromString(s string) []int8 {
+	if s == "" {
+		return nil
+	}
+	marks := make([]int8, 0, len(s))
+	for _, ch := range s {
+		marks = append(marks, int8(ch-'0'))
+	}
+	return marks
+}
+
+func keySudokuSessionCoop(id uuid.UUID) string {
+	return fmt.Sprintf("%s:coop", keySudokuSession(id))
+}
+
+func keySudokuSessionCoopToken(id uuid.UUID) string {
+	return fmt.Sprintf("%s:coop_token", keySudokuSession(id))
+}
+
+func keySudokuSessionPlayers(id uuid.UUID) string {
+	return fmt.Sprintf("%s:players", keySudokuSession(id))
+}
+
+func keySudokuSessionCellVersions(id uuid.UUID) string {
+	return fmt.Sprintf("%s:cell_versions", keySudokuSession(id))
+}
+
+func keySudokuSessionMarks(id uuid.UUID) string {
+	return fmt.Sprintf("%s:marks", keySudokuSession(id))
+}
@@ -0,0 +1,212 @@
+package model
+
+import (
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/gomodule/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+	"time"
+)
+
+// dateFormat is the format of the date of the daily puzzle.
+const dateFormat = "2006-01-02"
+
+// maxDailyMistakes is the maximum number of mistakes that affects the rank in the daily leaderboard.
+const maxDailyMistakes = 999
+
+// DailySudoku is the puzzle of the day of one difficulty. All users get the same puzzle.
+type DailySudoku struct {
+	conn  redis.Conn
+	date  string
+	level data.SudokuLevel
+}
+
+// DailySudokuByDate returns the puzzle of the day of the date in UTC.
+func DailySudokuByDate(conn redis.Conn, date time.Time, level data.SudokuLevel) DailySudoku {
+	return DailySudoku{
+		conn:  conn,
+		date:  date.UTC().Format(dateFormat),
+		level: level,
+	}
+}
+
+// Date returns the date of the puzzle in the format YYYY-MM-DD.
+func (d DailySudoku) Date() string {
+	return d.date
+}
+
+func (d DailySudoku) Level() data.SudokuLevel {
+	return d.level
+}
+
+// Sudoku returns the puzzle of the day. Returns false if the puzzle is not created yet.
+func (d DailySudoku) Sudoku() (Sudoku, bool, error) {
+	id, err := redis.Int64(d.conn.Do("GET", keyDailySudoku(d.date, d.level)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return Sudoku{}, false, nil
+	default:
+		return Sudoku{}, false, err
+	}
+	return SudokuByID(d.conn, id)
+}
+
+// SetSudoku saves the puzzle of the day if it is not saved yet. Returns the saved puzzle.
+func (d DailySudoku) SetSudoku(sudoku Sudoku) (Sudoku, error) {
+	isSet, err := redis.Bool(d.conn.Do("SETNX", keyDailySudoku(d.date, d.level), sudoku.id))
+	if err != nil {
+		return Sudoku{}, err
+	}
+	if isSet {
+		if _, err := d.conn.Do("SET", keySudokuDaily(sudoku.id), fmt.Sprintf("%s:%s", d.date, d.level)); err != nil {
+			return Sudoku{}, err
+		}
+		return sudoku, nil
+	}
+	saved, isExists, err := d.Sudoku()
+	if err != nil {
+		return Sudoku{}, err
+	}
+	if !isExists {
+		return Sudoku{}, fmt.Errorf("daily sudoku not found")
+	}
+	return saved, nil
+}
+
+// Session returns the user's attempt to solve the puzzle of the day. The null session if the user has not played.
+func (d DailySudoku) Session(user User) (SudokuSession, error) {
+	id, err := redis.String(d.conn.Do("HGET", keyDailySudokuSessions(d.date, d.level), user.id))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return SudokuSession{}, nil
+	default:
+		return SudokuSession{}, err
+	}
+	return SudokuSessionByIDString(d.conn, id)
+}
+
+// NewSession creates the user's only attempt to solve the puzzle of the day. If the user has already played, the
+// existing attempt is returned.
+func (d DailySudoku) NewSession(user User) (SudokuSession, error) {
+	if user.IsNull() {
+		return SudokuSession{}, fmt.Errorf("user is null")
+	}
+	if session, err := d.Session(user); err != nil || !session.IsNull() {
+		return session, err
+	}
+	sudoku, isExists, err := d.Sudoku()
+	if err != nil {
+		return SudokuSession{}, err
+	}
+	if !isExists {
+		return SudokuSession{}, fmt.Errorf("daily sudoku not found")
+	}
+	session, err := NewSudokuSession(d.conn, sudoku, user)
+	if err != nil {
+		return SudokuSession{}, err
+	}
+	isSet, err := redis.Bool(d.conn.Do("HSETNX", keyDailySudokuSessions(d.date, d.level), user.id, session.id.String()))
+	if err != nil {
+		return SudokuSession{}, err
+	}
+	if !isSet {
+		// a parallel request has created the attempt
+		return d.Session(user)
+	}
+	if _, err := d.conn.Do("SET", keySudokuSessionDaily(session.id), d.date+":"+string(d.level)); err != nil {
+		return SudokuSession{}, err
+	}
+	return session, nil
+}
+
+// AddResult adds the user's solved attempt to the daily leaderboard. Users are ranked by solve time, then by
+// mistakes.
+func (d DailySudoku) AddResult(user User, solveTime time.Duration, mistakes int64) error {
+	if mistakes > maxDailyMistakes {
+		mistakes = maxDailyMistakes
+	}
+	score := solveTime.Milliseconds()*(maxDailyMistakes+1) + mistakes
+	_, err := d.conn.Do("ZADD", keyDailySudokuLeaderboard(d.date, d.level), "NX", score, user.id)
+	return err
+}
+
+// Leaderboard returns count results of the daily leaderboard from the offset.
+func (d DailySudoku) Leaderboard(offset, count int) ([]data.DailyLeaderboardItem, error) {
+	values, err := redis.Values(d.conn.Do("ZRANGE", keyDailySudokuLeaderboard(d.date, d.level),
+		offset, offset+count-1, "WITHSCORES"))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]data.DailyLeaderboardItem, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		userID, err := redis.Int64(values[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		scoreFloat, err := redis.Float64(values[i+1], nil)
+		if err != nil {
+			return nil, err
+		}
+		score := int64(scoreFloat)
+		user := User{conn: d.conn, id: userID}
+		username, err := user.Username()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, data.DailyLeaderboardItem{
+			Rank:      offset + i/2 + 1,
+			UserID:    user.id,
+			Username:  username,
+			SolveTime: data.Duration(time.Duration(score/(maxDailyMistakes+1)) * time.Millisecond),
+			Mistakes:  score % (maxDailyMistakes + 1),
+		})
+	}
+	return items, nil
+}
+
+// Daily returns the puzzle of the day that the session is the attempt of. Returns false if the session is not daily.
+func (s SudokuSession) Daily() (DailySudoku, bool, error) {
+	str, err := redis.String(s.conn.Do("GET", keySudokuSessionDaily(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return DailySudoku{}, false, nil
+	default:
+		return DailySudoku{}, false, err
+	}
+	if len(str) <= len(dateFormat) {
+		return DailySudoku{}, false, fmt.Errorf("invalid daily sudoku '%s'", str)
+	}
+	return DailySudoku{
+		conn:  s.conn,
+		date:  str[:len(dateFormat)],
+		level: data.SudokuLevel(str[len(dateFormat)+1:]),
+	}, true, nil
+}
+
+// IsDaily reports whether the puzzle is the puzzle of the day.
+func (s Sudoku) IsDaily() (bool, error) {
+	return redis.Bool(s.conn.Do("EXISTS", keySudokuDaily(s.id)))
+}
+
+func keyDailySudoku(date string, level data.SudokuLevel) string {
+	return fmt.Sprintf("daily:%s:%s", date, level)
+}
+
+func keyDailySudokuSessions(date string, level data.SudokuLevel) string {
+	return fmt.Sprintf("%s:sessions", keyDailySudoku(date, level))
+}
+
+func keyDailySudokuLeaderboard(date string, level data.SudokuLevel) string {
+	return fmt.Sprintf("%s:leaderboard", keyDailySudoku(date, level))
+}
+
+func keySudokuSessionDaily(id uuid.UUID) string {
+	return fmt.Sprintf("%s:daily", keySudokuSession(id))
+}
+
+func keySudokuDaily(id int64) string {
+	return fmt.Sprintf("%s:daily", keySudoku(id))
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/gomodule/redigo/redis"
+	"time"
+)
+
+// Leaderboard is the rating of users by the sum of points of games completed in the period. Scores of different
+// versions of the formula are in different leaderboards.
+type Leaderboard struct {
+	conn   redis.Conn
+	key    string
+	period data.LeaderboardPeriod
+	level  data.SudokuLevel
+}
+
+// LeaderboardByPeriod returns the leaderboard of the current version of the score formula of the period containing
+// the moment at.
+func LeaderboardByPeriod(conn redis.Conn, period data.LeaderboardPeriod, level data.SudokuLevel, at time.Time) Leaderboard {
+	return Leaderboard{
+		conn:   conn,
+		key:    keyLeaderboard(data.SudokuScoreVersion, period, level, at),
+		period: period,
+		level:  level,
+	}
+}
+
+func (l Leaderboard) Period() data.LeaderboardPeriod {
+	return l.period
+}
+
+func (l Leaderboard) Level() data.SudokuLevel {
+	return l.level
+}
+
+// Count returns the number of users in the leaderboard.
+func (l Leaderboard) Count() (int, error) {
+	return redis.Int(l.conn.Do("ZCARD", l.key))
+}
+
+// Page returns count users of the leaderboard from the offset.
+func (l Leaderboard) Page(offset, count int) ([]data.LeaderboardItem, error) {
+	values, err := redis.Values(l.conn.Do("ZREVRANGE", l.key, offset, offset+count-1, "WITHSCORES"))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]data.LeaderboardItem, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		userID, err := redis.Int64(values[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		points, err := redis.Float64(values[i+1], nil)
+		if err != nil {
+			return nil, err
+		}
+		username, err := User{conn: l.conn, id: userID}.Username()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, data.LeaderboardItem{
+			Rank:     offset + i/2 + 1,
+			UserID:   userID,
+			Username: username,
+			Points:   int64(points),
+		})
+	}
+	return items, nil
+}
+
+// Rank returns the user's place in the leaderboard. Returns false if the user has no completed games in the period.
+func (l Leaderboard) Rank(user User) (data.LeaderboardItem, bool, error) {
+	rank, err := redis.Int(l.conn.Do("ZREVRANK", l.key, user.id))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return data.LeaderboardItem{}, false, nil
+	default:
+		return data.LeaderboardItem{}, false, err
+	}
+	points, err := redis.Float64(l.conn.Do("ZSCORE", l.key, user.id))
+	if err != nil {
+		return data.LeaderboardItem{}, false, err
+	}
+	username, err := user.Username()
+	if err != nil {
+		return data.LeaderboardItem{}, false, err
+	}
+	return data.LeaderboardItem{
+		Rank:     rank + 1,
+		UserID:   user.id,
+		Username: username,
+		Points:   int64(points),
+	}, true, nil
+}
+
+// addLeaderboardsScore adds points of the game completed at the moment at to the leaderboards of all periods.
+func addLeaderboardsScore(conn redis.Conn, user User, level data.SudokuLevel, score data.SudokuScore, at time.Time) error {
+	for _, period := range data.LeaderboardPeriods {
+		if _, err := conn.Do("ZINCRBY", keyLeaderboard(score.Version, period, level, at), score.Points, user.id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func keyLeaderboard(version int, period data.LeaderboardPeriod, level data.SudokuLevel, at time.Time) string {
+	at = at.UTC()
+	switch period {
+	case data.LeaderboardWeekly:
+		year, week := at.ISOWeek()
+		return fmt.Sprintf("leaderboard:v%d:%s:week:%d-W%02d", version, level, year, week)
+	case data.LeaderboardMonthly:
+		return fmt.Sprintf("leaderboard:v%d:%s:month:%s", version, level, at.Format("2006-01"))
+	default:
+		return fmt.Sprintf("leaderboard:v%d:%s:all", version, level)
+	}
+}
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"testing"
+	"time"
+)
+
+func TestKeyLeaderboard(t *testing.T) {
+	at := time.Date(2022, 1, 2, 23, 30, 0, 0, time.FixedZone("UTC-3", -3*60*60))
+	tests := []struct {
+		period data.LeaderboardPeriod
+		want   string
+	}{
+		{period: data.LeaderboardAllTime, want: "leaderboard:v1:hard:all"},
+		{period: data.LeaderboardWeekly, want: "leaderboard:v1:hard:week:2022-W01"},
+		{period: data.LeaderboardMonthly, want: "leaderboard:v1:hard:month:2022-01"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			if got := keyLeaderboard(1, tt.period, data.SudokuLevelHard, at); got != tt.want {
+				t.Errorf("keyLeaderboard() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUser_AddScoreReplay(t *testing.T) {
+	conn := testConn(t)
+	user, err := NewUser(conn, "username")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sudoku, err := NewSudoku(conn, "1", "1", data.SudokuLevelHard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solvedAt := time.Now()
+	// the puzzle is replayed with the known solution
+	for _, points := range []int64{100, 500} {
+		session, err := NewSudokuSession(conn, sudoku, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := session.Solve(solvedAt); err != nil {
+			t.Fatal(err)
+		}
+		if err := user.AddScore(session, data.SudokuScore{Version: data.SudokuScoreVersion, Points: points}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	item, ok, err := LeaderboardByPeriod(conn, data.LeaderboardAllTime, data.SudokuLevelHard, solvedAt).Rank(user)
+	if err != nil || !ok {
+		t.Fatalf("Rank() = %v, %v", ok, err)
+	}
+	if item.Points != 100 {
+		t.Errorf("leaderboard points = %d, want 100", item.Points)
+	}
+	info, err := user.UserInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.BestScore.Points != 100 {
+		t.Errorf("best score = %d, want 100", info.BestScore.Points)
+	}
+	scores, err := user.Scores()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(scores) != 2 {
+		t.Errorf("len(Scores()) = %d, want 2", len(scores))
+	}
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"testing"
+)
+
+// testConn returns the connection to the in-memory Redis server of the test.
+func testConn(t *testing.T) redis.Conn {
+	t.Helper()
+	conn, err := redis.Dial("tcp", miniredis.RunT(t).Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
@@ -0,0 +1,95 @@
+package model
+
+import (
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/gomodule/redigo/redis"
+)
+
+// PuzzleBank is the queue of pre-generated puzzles of one variant and difficulty. New games take puzzles from the
+// bank instead of generating them during the request.
+type PuzzleBank struct {
+	conn    redis.Conn
+	variant data.SudokuVariant
+	level   data.SudokuLevel
+}
+
+// PuzzleBankOf returns the bank of puzzles of the variant and the difficulty.
+func PuzzleBankOf(conn redis.Conn, variant data.SudokuVariant, level data.SudokuLevel) PuzzleBank {
+	return PuzzleBank{
+		conn:    conn,
+		variant: variant,
+		level:   level,
+	}
+}
+
+func (b PuzzleBank) Variant() data.SudokuVariant {
+	return b.variant
+}
+
+func (b PuzzleBank) Level() data.SudokuLevel {
+	return b.level
+}
+
+// Len returns the number of puzzles in the bank.
+func (b PuzzleBank) Len() (int64, error) {
+	return redis.Int64(b.conn.Do("LLEN", keyPuzzleBank(b.variant, b.level)))
+}
+
+// Push adds the puzzle to the end of the bank.
+func (b PuzzleBank) Push(sudoku Sudoku) error {
+	_, err := b.conn.Do("RPUSH", keyPuzzleBank(b.variant, b.level), sudoku.id)
+	return err
+}
+
+// popPuzzleBankScript removes and returns the oldest puzzle of the bank KEYS[1] that is not in the set of played
+// puzzles KEYS[2] and whose canonical form is not in the set of played canonical forms KEYS[3]. Played puzzles are
+// only checked if ARGV[1] is 1. Keys of puzzles are built by the script like keySudoku and keySudokuCanonical because
+// the puzzles are not known in advance. Puzzles deleted from the database are removed from the bank.
+var popPuzzleBankScript = redis.NewScript(3, `
+for _, id in ipairs(redis.call('LRANGE', KEYS[1], 0, -1)) do
+	if redis.call('EXISTS', 'sudoku:' .. id) == 0 then
+		redis.call('LREM', KEYS[1], 1, id)
+	else
+		local isPlayed = false
+		if ARGV[1] == '1' then
+			local canonical = redis.call('GET', 'sudoku:' .. id .. ':canonical')
+			isPlayed = redis.call('SISMEMBER', KEYS[2], id) == 1 or
+				(canonical and canonical ~= '' and redis.call('SISMEMBER', KEYS[3], canonical) == 1)
+		end
+		if not isPlayed then
+			redis.call('LREM', KEYS[1], 1, id)
+			return id
+		end
+	end
+end
+return false
+`)
+
+// Pop takes the oldest puzzle from the bank that the user has not played. Returns false if the bank is empty or the
+// user has played all puzzles of the bank. The puzzle is taken atomically, so parallel requests get different puzzles.
+func (b PuzzleBank) Pop(user User) (Sudoku, bool, error) {
+	checkPlayed := 0
+	if !user.IsNull() {
+		checkPlayed = 1
+	}
+	id, err := redis.Int64(popPuzzleBankScript.Do(b.conn,
+		keyPuzzleBank(b.variant, b.level), keyUserPlayedSudokus(user.id), keyUserPlayedCanonicals(user.id),
+		checkPlayed,
+	))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return Sudoku{}, false, nil
+	default:
+		return Sudoku{}, false, err
+	}
+	return Sudoku{
+		conn: b.conn,
+		id:   id,
+	}, true, nil
+}
+
+func keyPuzzleBank(variant data.SudokuVariant, level data.SudokuLevel) string {
+	return fmt.Sprintf("puzzle_bank:%s:%s", variant, level)
+}
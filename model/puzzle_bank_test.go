@@ -0,0 +1,93 @@
+package model
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"testing"
+)
+
+func TestPuzzleBank(t *testing.T) {
+	conn := testConn(t)
+	bank := PuzzleBankOf(conn, data.SudokuVariantClassic, data.SudokuLevelEasy)
+	var sudokus []Sudoku
+	for i := 0; i < 2; i++ {
+		sudoku, err := NewSudoku(conn, "1", "1", data.SudokuLevelEasy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sudokus = append(sudokus, sudoku)
+	}
+	// the puzzle deleted from the database is skipped
+	if err := bank.Push(Sudoku{conn: conn, id: 100}); err != nil {
+		t.Fatal(err)
+	}
+	for _, sudoku := range sudokus {
+		if err := bank.Push(sudoku); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, err := bank.Len(); err != nil || n != 3 {
+		t.Errorf("Len() = %d, %v, want 3", n, err)
+	}
+	other := PuzzleBankOf(conn, data.SudokuVariantClassic, data.SudokuLevelHard)
+	if _, ok, err := other.Pop(User{}); err != nil || ok {
+		t.Errorf("Pop() of another level = %v, %v, want false", ok, err)
+	}
+
+	for _, want := range sudokus {
+		got, ok, err := bank.Pop(User{})
+		if err != nil || !ok || got.ID() != want.ID() {
+			t.Errorf("Pop() = %d, %v, %v, want %d", got.ID(), ok, err, want.ID())
+		}
+	}
+	if _, ok, err := bank.Pop(User{}); err != nil || ok {
+		t.Errorf("Pop() of the empty bank = %v, %v, want false", ok, err)
+	}
+	if n, err := bank.Len(); err != nil || n != 0 {
+		t.Errorf("Len() = %d, %v, want 0", n, err)
+	}
+}
+
+func TestPuzzleBank_PopPlayed(t *testing.T) {
+	conn := testConn(t)
+	user, err := NewUser(conn, "username")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bank := PuzzleBankOf(conn, data.SudokuVariantClassic, data.SudokuLevelEasy)
+	var sudokus []Sudoku
+	for _, canonical := range []string{"a", "b", "b", "c"} {
+		sudoku, err := NewSudoku(conn, "1", "1", data.SudokuLevelEasy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sudoku.SetCanonical(canonical); err != nil {
+			t.Fatal(err)
+		}
+		if err := bank.Push(sudoku); err != nil {
+			t.Fatal(err)
+		}
+		sudokus = append(sudokus, sudoku)
+	}
+	// the second puzzle is played, the third one is the same puzzle
+	for _, played := range sudokus[:2] {
+		if err := user.AddPlayedSudoku(played); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, ok, err := bank.Pop(user)
+	if err != nil || !ok || got.ID() != sudokus[3].ID() {
+		t.Errorf("Pop() = %d, %v, %v, want %d", got.ID(), ok, err, sudokus[3].ID())
+	}
+	if _, ok, err := bank.Pop(user); err != nil || ok {
+		t.Errorf("Pop() of played puzzles = %v, %v, want false", ok, err)
+	}
+	// played puzzles stay in the bank for other players
+	got, ok, err = bank.Pop(User{})
+	if err != nil || !ok || got.ID() != sudokus[0].ID() {
+		t.Errorf("anonymous Pop() = %d, %v, %v, want %d", got.ID(), ok, err, sudokus[0].ID())
+	}
+	if n, err := bank.Len(); err != nil || n != 2 {
+		t.Errorf("Len() = %d, %v, want 2", n, err)
+	}
+}
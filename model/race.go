@@ -0,0 +1,186 @@
+package model
+
+import (
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Race is a game where several players solve the same puzzle in their own sessions. The first correct solver wins.
+type Race struct {
+	conn     redis.Conn
+	id       uuid.UUID
+	sudokuID int64
+}
+
+// NewRace creates the race on the puzzle.
+func NewRace(conn redis.Conn, sudoku Sudoku) (Race, error) {
+	if sudoku.IsNull() {
+		return Race{}, fmt.Errorf("sudoku is null")
+	}
+	id := uuid.NewV4()
+	if _, err := conn.Do("SET", keyRace(id), sudoku.id); err != nil {
+		return Race{}, err
+	}
+	return Race{
+		conn:     conn,
+		id:       id,
+		sudokuID: sudoku.id,
+	}, nil
+}
+
+// RaceByIDString returns the race. The null race if it is not found.
+func RaceByIDString(conn redis.Conn, idStr string) (Race, error) {
+	id, err := uuid.FromString(idStr)
+	if err != nil {
+		return Race{}, err
+	}
+	sudokuID, err := redis.Int64(conn.Do("GET", keyRace(id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return Race{}, nil
+	default:
+		return Race{}, err
+	}
+	return Race{
+		conn:     conn,
+		id:       id,
+		sudokuID: sudokuID,
+	}, nil
+}
+
+func (r Race) ID() uuid.UUID {
+	return r.id
+}
+
+func (r Race) IsNull() bool {
+	return uuid.Equal(r.id, uuid.Nil)
+}
+
+func (r Race) Sudoku() Sudoku {
+	return Sudoku{
+		conn: r.conn,
+		id:   r.sudokuID,
+	}
+}
+
+// Join returns the player's session in the race. A logged-in user always gets the same session, an anonymous player
+// gets the session by its ID from the previous join. Otherwise, a new session is created.
+func (r Race) Join(user User, prevSessionID string) (SudokuSession, error) {
+	if !user.IsNull() {
+		sessionID, err := redis.String(r.conn.Do("HGET", keyRaceUsers(r.id), user.id))
+		switch err {
+		case nil:
+			return SudokuSessionByIDString(r.conn, sessionID)
+		case redis.ErrNil:
+		default:
+			return SudokuSession{}, err
+		}
+	} else if prevSessionID != "" {
+		isMember, err := r.HasSession(prevSessionID)
+		if err != nil {
+			return SudokuSession{}, err
+		}
+		if isMember {
+			return SudokuSessionByIDString(r.conn, prevSessionID)
+		}
+	}
+
+	session, err := NewSudokuSession(r.conn, r.Sudoku(), user)
+	if err != nil {
+		return SudokuSession{}, err
+	}
+	if !user.IsNull() {
+		isSet, err := redis.Bool(r.conn.Do("HSETNX", keyRaceUsers(r.id), user.id, session.id.String()))
+		if err != nil {
+			return SudokuSession{}, err
+		}
+		if !isSet {
+			// a parallel request has joined the user
+			return r.Join(user, "")
+		}
+	}
+	if _, err := r.conn.Do("SADD", keyRaceSessions(r.id), session.id.String()); err != nil {
+		return SudokuSession{}, err
+	}
+	if _, err := r.conn.Do("SET", keySudokuSessionRace(session.id), r.id.String()); err != nil {
+		return SudokuSession{}, err
+	}
+	return session, nil
+}
+
+// HasSession reports whether the session belongs to a player of the race.
+func (r Race) HasSession(sessionID string) (bool, error) {
+	return redis.Bool(r.conn.Do("SISMEMBER", keyRaceSessions(r.id), sessionID))
+}
+
+// Sessions returns sessions of all players of the race.
+func (r Race) Sessions() ([]SudokuSession, error) {
+	ids, err := redis.Strings(r.conn.Do("SMEMBERS", keyRaceSessions(r.id)))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]SudokuSession, 0, len(ids))
+	for _, id := range ids {
+		session, err := SudokuSessionByIDString(r.conn, id)
+		if err != nil {
+			return nil, err
+		}
+		if !session.IsNull() {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// SetWinner saves the session as the winner if the race has no winner yet. Returns true if the session won.
+func (r Race) SetWinner(session SudokuSession) (bool, error) {
+	return redis.Bool(r.conn.Do("SETNX", keyRaceWinner(r.id), session.id.String()))
+}
+
+// Winner returns the session of the winner. The null session if nobody has solved the puzzle yet.
+func (r Race) Winner() (SudokuSession, error) {
+	id, err := redis.String(r.conn.Do("GET", keyRaceWinner(r.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return SudokuSession{}, nil
+	default:
+		return SudokuSession{}, err
+	}
+	return SudokuSessionByIDString(r.conn, id)
+}
+
+// Race returns the race that the session is played in. The null race if the session is not in a race.
+func (s SudokuSession) Race() (Race, error) {
+	id, err := redis.String(s.conn.Do("GET", keySudokuSessionRace(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return Race{}, nil
+	default:
+		return Race{}, err
+	}
+	return RaceByIDString(s.conn, id)
+}
+
+func keyRace(id uuid.UUID) string {
+	return fmt.Sprintf("race:%s", id.String())
+}
+
+func keyRaceSessions(id uuid.UUID) string {
+	return fmt.Sprintf("%s:sessions", keyRace(id))
+}
+
+func keyRaceUsers(id uuid.UUID) string {
+	return fmt.Sprintf("%s:users", keyRace(id))
+}
+
+func keyRaceWinner(id uuid.UUID) string {
+	return fmt.Sprintf("%s:winner", keyRace(id))
+}
+
+func keySudokuSessionRace(id uuid.UUID) string {
+	return fmt.Sprintf("%s:race", keySudokuSession(id))
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"github.com/cnblvr/sudoku/data"
 	"github.com/gomodule/redigo/redis"
 )
 
@@ -25,6 +26,20 @@ func SudokuByID(conn redis.Conn, id int64) (Sudoku, bool, error) {
 	}, true, nil
 }
 
+// SudokuBySeed returns the puzzle generated by the seed with the level and the version of the generator. Returns
+// false if such puzzle is not saved.
+func SudokuBySeed(conn redis.Conn, seed int64, level data.SudokuLevel, version int) (Sudoku, bool, error) {
+	id, err := redis.Int64(conn.Do("GET", keySudokuBySeed(seed, level, version)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return Sudoku{}, false, nil
+	default:
+		return Sudoku{}, false, err
+	}
+	return SudokuByID(conn, id)
+}
+
 func (s Sudoku) ID() int64 {
 	return s.id
 }
@@ -41,7 +56,114 @@ func (s Sudoku) Board() (string, error) {
 	return redis.String(s.conn.Do("GET", keySudokuBoard(s.id)))
 }
 
-func NewSudoku(conn redis.Conn, board string, puzzle string) (Sudoku, error) {
+// Level returns the difficulty of the puzzle. Puzzles without the saved difficulty are medium.
+func (s Sudoku) Level() (data.SudokuLevel, error) {
+	level, err := redis.String(s.conn.Do("GET", keySudokuLevel(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return data.SudokuLevelMedium, nil
+	default:
+		return "", err
+	}
+	return data.SudokuLevel(level), nil
+}
+
+// Seed returns the seed and the version of the generator of the puzzle. Returns false if the seed is not saved.
+func (s Sudoku) Seed() (int64, int, bool, error) {
+	values, err := redis.Values(s.conn.Do("MGET", keySudokuSeed(s.id), keySudokuGeneratorVersion(s.id)))
+	if err != nil {
+		return 0, 0, false, err
+	}
+	var (
+		seed, version int64
+		isSeed        bool
+	)
+	if values[0] != nil {
+		if seed, err = redis.Int64(values[0], nil); err != nil {
+			return 0, 0, false, err
+		}
+		isSeed = true
+	}
+	if values[1] != nil {
+		if version, err = redis.Int64(values[1], nil); err != nil {
+			return 0, 0, false, err
+		}
+	}
+	return seed, int(version), isSeed, nil
+}
+
+// SetSeed saves the seed and the version of the generator of the puzzle. The first puzzle of the seed, the level and
+// the version is found by SudokuBySeed.
+func (s Sudoku) SetSeed(seed int64, version int) error {
+	level, err := s.Level()
+	if err != nil {
+		return err
+	}
+	if _, err := s.conn.Do("SET", keySudokuSeed(s.id), seed); err != nil {
+		return err
+	}
+	if _, err := s.conn.Do("SET", keySudokuGeneratorVersion(s.id), version); err != nil {
+		return err
+	}
+	_, err = s.conn.Do("SETNX", keySudokuBySeed(seed, level, version), s.id)
+	return err
+}
+
+// Canonical returns the canonical form of the puzzle. Same puzzles have the same canonical forms regardless of
+// rotations, permutations of lines and renumbering of digits. An empty string if the form is not saved.
+func (s Sudoku) Canonical() (string, error) {
+	canonical, err := redis.String(s.conn.Do("GET", keySudokuCanonical(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return "", nil
+	default:
+		return "", err
+	}
+	return canonical, nil
+}
+
+// SetCanonical saves the canonical form of the puzzle.
+func (s Sudoku) SetCanonical(canonical string) error {
+	_, err := s.conn.Do("SET", keySudokuCanonical(s.id), canonical)
+	return err
+}
+
+// Sessions returns all sessions of the puzzle in the order of creation.
+func (s Sudoku) Sessions() ([]SudokuSession, error) {
+	ids, err := redis.Strings(s.conn.Do("ZRANGE", keySudokuSessions(s.id), 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]SudokuSession, 0, len(ids))
+	for _, id := range ids {
+		session, err := SudokuSessionByIDString(s.conn, id)
+		if err != nil {
+			return nil, err
+		}
+		if !session.IsNull() {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+// UserSession returns the first session of the puzzle played by the user. The null session if the user has not
+// played the puzzle.
+func (s Sudoku) UserSession(user User) (SudokuSession, error) {
+	id, err := redis.String(s.conn.Do("HGET", keySudokuUserSessions(s.id), user.id))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return SudokuSession{}, nil
+	default:
+		return SudokuSession{}, err
+	}
+	return SudokuSessionByIDString(s.conn, id)
+}
+
+func NewSudoku(conn redis.Conn, board string, puzzle string, level data.SudokuLevel) (Sudoku, error) {
 	id, err := redis.Int64(conn.Do("INCR", keyLastSudokuID()))
 	if err != nil {
 		return Sudoku{}, err
@@ -53,6 +175,9 @@ func NewSudoku(conn redis.Conn, board string, puzzle string) (Sudoku, error) {
 	if _, err := conn.Do("SET", keySudokuBoard(id), board); err != nil {
 		return Sudoku{}, err
 	}
+	if _, err := conn.Do("SET", keySudokuLevel(id), level); err != nil {
+		return Sudoku{}, err
+	}
 
 	return Sudoku{
 		conn: conn,
@@ -71,3 +196,31 @@ func keyLastSudokuID() string {
 func keySudokuBoard(id int64) string {
 	return fmt.Sprintf("%s:board", keySudoku(id))
 }
+
+func keySudokuLevel(id int64) string {
+	return fmt.Sprintf("%s:level", keySudoku(id))
+}
+
+func keySudokuSeed(id int64) string {
+	return fmt.Sprintf("%s:seed", keySudoku(id))
+}
+
+func keySudokuGeneratorVersion(id int64) string {
+	return fmt.Sprintf("%s:generator_version", keySudoku(id))
+}
+
+func keySudokuBySeed(seed int64, level data.SudokuLevel, version int) string {
+	return fmt.Sprintf("sudoku_by_seed:v%d:%s:%d", version, level, seed)
+}
+
+func keySudokuCanonical(id int64) string {
+	return fmt.Sprintf("%s:canonical", keySudoku(id))
+}
+
+func keySudokuSessions(id int64) string {
+	return fmt.Sprintf("%s:sessions", keySudoku(id))
+}
+
+func keySudokuUserSessions(id int64) string {
+	return fmt.Sprintf("%s:user_sessions", keySudoku(id))
+}
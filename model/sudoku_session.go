@@ -2,12 +2,18 @@ package model
 
 import (
 	"crypto/md5"
+	"encoding/json"
 	"fmt"
+	"github.com/cnblvr/sudoku/data"
 	"github.com/gomodule/redigo/redis"
 	uuid "github.com/satori/go.uuid"
 	"strconv"
+	"time"
 )
 
+// maxSudokuSessionUndoStates is the maximum number of states that can be undone.
+const maxSudokuSessionUndoStates = 100
+
 type SudokuSession struct {
 	conn     redis.Conn
 	id       uuid.UUID
@@ -55,18 +61,100 @@ func NewSudokuSession(conn redis.Conn, sudoku Sudoku, user User) (SudokuSession,
 	if _, err := conn.Do("SET", keySudokuSession(id), sudoku.id); err != nil {
 		return SudokuSession{}, err
 	}
+	createdAt := time.Now().UTC()
+	if _, err := conn.Do("SET", keySudokuSessionCreatedAt(id), createdAt.Format(dateTimeFormat)); err != nil {
+		return SudokuSession{}, err
+	}
+	if _, err := conn.Do("ZADD", keySudokuSessions(sudoku.id), createdAt.UnixMilli(), id.String()); err != nil {
+		return SudokuSession{}, err
+	}
 	if !user.IsNull() {
 		if _, err := conn.Do("SET", keySudokuSessionUserID(id), user.id); err != nil {
 			return SudokuSession{}, err
 		}
+		if _, err := conn.Do("ZADD", keyUserSudokuSessions(user.id), createdAt.UnixMilli(), id.String()); err != nil {
+			return SudokuSession{}, err
+		}
+		if _, err := conn.Do("HSETNX", keySudokuUserSessions(sudoku.id), user.id, id.String()); err != nil {
+			return SudokuSession{}, err
+		}
+		if err := user.AddPlayedSudoku(sudoku); err != nil {
+			return SudokuSession{}, err
+		}
+	}
+	if _, err := conn.Do("SET", keySudokuSessionStatus(id), data.SudokuSessionInProgress); err != nil {
+		return SudokuSession{}, err
+	}
+	puzzle, err := sudoku.Puzzle()
+	if err != nil {
+		return SudokuSession{}, err
+	}
+	if _, err := conn.Do("SET", keySudokuSessionState(id), puzzle); err != nil {
+		return SudokuSession{}, err
 	}
 
 	return SudokuSession{
-		conn: conn,
-		id:   id,
+		conn:     conn,
+		id:       id,
+		sudokuID: sudoku.id,
+	}, nil
+}
+
+// User returns the owner of the session. The null user if the session is anonymous.
+func (s SudokuSession) User() (User, error) {
+	userID, err := redis.Int64(s.conn.Do("GET", keySudokuSessionUserID(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return User{}, nil
+	default:
+		return User{}, err
+	}
+	return User{
+		conn: s.conn,
+		id:   userID,
 	}, nil
 }
 
+// Claim attaches the anonymous session to the user: the session is added to the user's history with its score. The
+// score goes to the leaderboards of the periods when the session was solved, not claimed. Returns false if the session
+// already has an owner.
+func (s SudokuSession) Claim(user User) (bool, error) {
+	if user.IsNull() {
+		return false, fmt.Errorf("user is null")
+	}
+	isClaimed, err := redis.Bool(s.conn.Do("SETNX", keySudokuSessionUserID(s.id), user.id))
+	if err != nil {
+		return false, err
+	}
+	if !isClaimed {
+		return false, nil
+	}
+	createdAt, err := s.CreatedAt()
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.conn.Do("ZADD", keyUserSudokuSessions(user.id), createdAt.UnixMilli(), s.id.String()); err != nil {
+		return false, err
+	}
+	if _, err := s.conn.Do("HSETNX", keySudokuUserSessions(s.sudokuID), user.id, s.id.String()); err != nil {
+		return false, err
+	}
+	if err := user.AddPlayedSudoku(s.Sudoku()); err != nil {
+		return false, err
+	}
+	score, isScored, err := s.Score()
+	if err != nil {
+		return false, err
+	}
+	if isScored {
+		if err := user.AddScore(s, score); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
 func (s SudokuSession) Sudoku() Sudoku {
 	return Sudoku{
 		conn: s.conn,
@@ -82,6 +170,382 @@ func (s SudokuSession) IsNull() bool {
 	return s.id.String() == "00000000-0000-0000-0000-000000000000"
 }
 
+// CreatedAt returns the date and time the session was created. Zero time for sessions created before it was saved.
+func (s SudokuSession) CreatedAt() (time.Time, error) {
+	str, err := redis.String(s.conn.Do("GET", keySudokuSessionCreatedAt(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return time.Time{}, nil
+	default:
+		return time.Time{}, err
+	}
+	return time.Parse(dateTimeFormat, str)
+}
+
+// HistoryItem returns the session as the game in the user's history.
+func (s SudokuSession) HistoryItem() (data.SudokuHistoryItem, error) {
+	history, err := SudokuSessionsHistory(s.conn, []SudokuSession{s})
+	if err != nil {
+		return data.SudokuHistoryItem{}, err
+	}
+	return history[0], nil
+}
+
+// SudokuSessionsHistory returns the sessions as games in the user's history. Games of all sessions are loaded by one
+// request.
+func SudokuSessionsHistory(conn redis.Conn, sessions []SudokuSession) ([]data.SudokuHistoryItem, error) {
+	if len(sessions) == 0 {
+		return []data.SudokuHistoryItem{}, nil
+	}
+	const fields = 5
+	keys := make([]interface{}, 0, len(sessions)*fields)
+	for _, s := range sessions {
+		keys = append(keys,
+			keySudokuLevel(s.sudokuID),
+			keySudokuSessionStatus(s.id),
+			keySudokuSessionCreatedAt(s.id),
+			keySudokuSessionSolveTime(s.id),
+			keySudokuSessionScore(s.id),
+		)
+	}
+	values, err := redis.Values(conn.Do("MGET", keys...))
+	if err != nil {
+		return nil, err
+	}
+	history := make([]data.SudokuHistoryItem, 0, len(sessions))
+	for i, s := range sessions {
+		v := values[i*fields : (i+1)*fields]
+		item := data.SudokuHistoryItem{
+			SessionID: s.id.String(),
+			SudokuID:  s.sudokuID,
+			Status:    data.SudokuSessionInProgress,
+		}
+		level, err := redis.String(v[0], nil)
+		if err != nil {
+			return nil, err
+		}
+		item.Level = data.SudokuLevel(level)
+		if v[1] != nil {
+			status, err := redis.String(v[1], nil)
+			if err != nil {
+				return nil, err
+			}
+			item.Status = data.SudokuSessionStatus(status)
+		}
+		if v[2] != nil {
+			createdAt, err := redis.String(v[2], nil)
+			if err != nil {
+				return nil, err
+			}
+			if item.CreatedAt, err = time.Parse(dateTimeFormat, createdAt); err != nil {
+				return nil, err
+			}
+		}
+		if v[3] != nil {
+			ms, err := redis.Int64(v[3], nil)
+			if err != nil {
+				return nil, err
+			}
+			item.SolveTime = data.Duration(time.Duration(ms) * time.Millisecond)
+		}
+		if v[4] != nil {
+			scoreBts, err := redis.Bytes(v[4], nil)
+			if err != nil {
+				return nil, err
+			}
+			var score data.SudokuScore
+			if err := json.Unmarshal(scoreBts, &score); err != nil {
+				return nil, err
+			}
+			item.Score = &score
+		}
+		history = append(history, item)
+	}
+	return history, nil
+}
+
+// Status returns the state of the game. Sessions without the saved status are in progress.
+func (s SudokuSession) Status() (data.SudokuSessionStatus, error) {
+	status, err := redis.String(s.conn.Do("GET", keySudokuSessionStatus(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return data.SudokuSessionInProgress, nil
+	default:
+		return "", err
+	}
+	return data.SudokuSessionStatus(status), nil
+}
+
+// SolvedAt returns the date and time the puzzle was solved. Zero time if the puzzle is not solved yet.
+func (s SudokuSession) SolvedAt() (time.Time, error) {
+	str, err := redis.String(s.conn.Do("GET", keySudokuSessionSolvedAt(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return time.Time{}, nil
+	default:
+		return time.Time{}, err
+	}
+	return time.Parse(dateTimeFormat, str)
+}
+
+// SolveTime returns the game time spent on solving the puzzle. Zero if the puzzle is not solved yet.
+func (s SudokuSession) SolveTime() (time.Duration, error) {
+	ms, err := redis.Int64(s.conn.Do("GET", keySudokuSessionSolveTime(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return 0, nil
+	default:
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// solveSudokuSessionScript changes the status of the session to solved and saves the stopped timer, the solve time
+// and the completion time if the session is not solved yet. The script returns 1 if the session is solved by this call.
+var solveSudokuSessionScript = redis.NewScript(4, `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1])
+redis.call('SET', KEYS[2], ARGV[2])
+redis.call('SET', KEYS[3], ARGV[3])
+redis.call('SET', KEYS[4], ARGV[4])
+return 1
+`)
+
+// Solve stops the timer, saves the completion time and changes the status of the session to solved. Returns false if
+// the session has already been solved, for example by a parallel request, and nothing is changed.
+func (s SudokuSession) Solve(solvedAt time.Time) (bool, error) {
+	timer, err := s.Timer()
+	if err != nil {
+		return false, err
+	}
+	timer.Pause(solvedAt)
+	timerBts, err := json.Marshal(timer)
+	if err != nil {
+		return false, err
+	}
+	return redis.Bool(solveSudokuSessionScript.Do(s.conn,
+		keySudokuSessionStatus(s.id), keySudokuSessionTimer(s.id), keySudokuSessionSolveTime(s.id), keySudokuSessionSolvedAt(s.id),
+		data.SudokuSessionSolved, timerBts, timer.Elapsed.Milliseconds(), solvedAt.UTC().Format(dateTimeFormat),
+	))
+}
+
+// Timer returns the game timer of the session. The timer is not started if it has not been saved yet.
+func (s SudokuSession) Timer() (data.SudokuSessionTimer, error) {
+	timerBts, err := redis.Bytes(s.conn.Do("GET", keySudokuSessionTimer(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return data.SudokuSessionTimer{}, nil
+	default:
+		return data.SudokuSessionTimer{}, err
+	}
+	var timer data.SudokuSessionTimer
+	if err := json.Unmarshal(timerBts, &timer); err != nil {
+		return data.SudokuSessionTimer{}, err
+	}
+	return timer, nil
+}
+
+// SetTimer saves the game timer of the session.
+func (s SudokuSession) SetTimer(timer data.SudokuSessionTimer) error {
+	timerBts, err := json.Marshal(timer)
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.Do("SET", keySudokuSessionTimer(s.id), timerBts)
+	return err
+}
+
+// pauseIdleTimerScript saves the paused timer if the saved timer has not changed since it was read. The script returns
+// 1 if the timer is saved.
+var pauseIdleTimerScript = redis.NewScript(1, `
+if redis.call('GET', KEYS[1]) ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[2])
+return 1
+`)
+
+// PauseIdleTimer pauses the running timer at the moment of the last activity if the user has been idle longer than
+// idleTimeout. The timer is only changed if no request has changed it since it was read, so the fresh activity of the
+// player is never overwritten. Returns the saved timer and true if the timer is paused by this call.
+func (s SudokuSession) PauseIdleTimer(now time.Time, idleTimeout time.Duration) (data.SudokuSessionTimer, bool, error) {
+	timerBts, err := redis.Bytes(s.conn.Do("GET", keySudokuSessionTimer(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return data.SudokuSessionTimer{}, false, nil
+	default:
+		return data.SudokuSessionTimer{}, false, err
+	}
+	var timer data.SudokuSessionTimer
+	if err := json.Unmarshal(timerBts, &timer); err != nil {
+		return data.SudokuSessionTimer{}, false, err
+	}
+	if !timer.IsIdle(now, idleTimeout) {
+		return timer, false, nil
+	}
+	paused := timer
+	paused.PauseIdle()
+	pausedBts, err := json.Marshal(paused)
+	if err != nil {
+		return data.SudokuSessionTimer{}, false, err
+	}
+	isPaused, err := redis.Bool(pauseIdleTimerScript.Do(s.conn, keySudokuSessionTimer(s.id), timerBts, pausedBts))
+	if err != nil {
+		return data.SudokuSessionTimer{}, false, err
+	}
+	if !isPaused {
+		return timer, false, nil
+	}
+	return paused, true, nil
+}
+
+// State returns the current state of the puzzle filled in by the user. Empty if the user has not made any steps.
+func (s SudokuSession) State() (string, error) {
+	state, err := redis.String(s.conn.Do("GET", keySudokuSessionState(s.id)))
+	if err == redis.ErrNil {
+		return "", nil
+	}
+	return state, err
+}
+
+// SetState saves the new state of the puzzle. The previous state is kept for undo.
+func (s SudokuSession) SetState(state string) error {
+	prev, err := s.State()
+	if err != nil {
+		return err
+	}
+	if prev != "" && prev != state {
+		if _, err := s.conn.Do("LPUSH", keySudokuSessionUndoStates(s.id), prev); err != nil {
+			return err
+		}
+		if _, err := s.conn.Do("LTRIM", keySudokuSessionUndoStates(s.id), 0, maxSudokuSessionUndoStates-1); err != nil {
+			return err
+		}
+	}
+	_, err = s.conn.Do("SET", keySudokuSessionState(s.id), state)
+	return err
+}
+
+// UndoState restores the previous state of the puzzle. Returns false if there is nothing to undo.
+func (s SudokuSession) UndoState() (string, bool, error) {
+	prev, err := redis.String(s.conn.Do("LPOP", keySudokuSessionUndoStates(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return "", false, nil
+	default:
+		return "", false, err
+	}
+	if _, err := s.conn.Do("SET", keySudokuSessionState(s.id), prev); err != nil {
+		return "", false, err
+	}
+	return prev, true, nil
+}
+
+// AddMoves saves the moves of the state. Returns the moves with sequence numbers.
+func (s SudokuSession) AddMoves(moves []data.SudokuMove) ([]data.SudokuMove, error) {
+	for i, move := range moves {
+		move.Seq = 0
+		moveBts, err := json.Marshal(move)
+		if err != nil {
+			return nil, err
+		}
+		if moves[i].Seq, err = redis.Int64(s.conn.Do("RPUSH", keySudokuSessionMoves(s.id), moveBts)); err != nil {
+			return nil, err
+		}
+	}
+	return moves, nil
+}
+
+// Moves returns the recorded moves of the state starting from the sequence number fromSeq.
+func (s SudokuSession) Moves(fromSeq int64) ([]data.SudokuMove, error) {
+	if fromSeq < 1 {
+		fromSeq = 1
+	}
+	values, err := redis.ByteSlices(s.conn.Do("LRANGE", keySudokuSessionMoves(s.id), fromSeq-1, -1))
+	if err != nil {
+		return nil, err
+	}
+	moves := make([]data.SudokuMove, 0, len(values))
+	for i, moveBts := range values {
+		var move data.SudokuMove
+		if err := json.Unmarshal(moveBts, &move); err != nil {
+			return nil, err
+		}
+		move.Seq = fromSeq + int64(i)
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+// Stats returns counters of the user's actions.
+func (s SudokuSession) Stats() (data.SudokuSessionStats, error) {
+	m, err := redis.Int64Map(s.conn.Do("HGETALL", keySudokuSessionStats(s.id)))
+	if err != nil {
+		return data.SudokuSessionStats{}, err
+	}
+	return data.SudokuSessionStats{
+		Mistakes: m["mistakes"],
+		Hints:    m["hints"],
+		Undos:    m["undos"],
+	}, nil
+}
+
+// IncrMistakes increases the number of mistakes by n.
+func (s SudokuSession) IncrMistakes(n int64) error {
+	return s.incrStats("mistakes", n)
+}
+
+// IncrHints increases the number of used hints by one.
+func (s SudokuSession) IncrHints() error {
+	return s.incrStats("hints", 1)
+}
+
+// IncrUndos increases the number of canceled steps by one.
+func (s SudokuSession) IncrUndos() error {
+	return s.incrStats("undos", 1)
+}
+
+func (s SudokuSession) incrStats(field string, n int64) error {
+	_, err := s.conn.Do("HINCRBY", keySudokuSessionStats(s.id), field, n)
+	return err
+}
+
+// Score returns the score of the completed game. Returns false if the game is not scored.
+func (s SudokuSession) Score() (data.SudokuScore, bool, error) {
+	scoreBts, err := redis.Bytes(s.conn.Do("GET", keySudokuSessionScore(s.id)))
+	switch err {
+	case nil:
+	case redis.ErrNil:
+		return data.SudokuScore{}, false, nil
+	default:
+		return data.SudokuScore{}, false, err
+	}
+	var score data.SudokuScore
+	if err := json.Unmarshal(scoreBts, &score); err != nil {
+		return data.SudokuScore{}, false, err
+	}
+	return score, true, nil
+}
+
+// SetScore saves the score of the completed game.
+func (s SudokuSession) SetScore(score data.SudokuScore) error {
+	scoreBts, err := json.Marshal(score)
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.Do("SET", keySudokuSessionScore(s.id), scoreBts)
+	return err
+}
+
 func keySudokuSession(id uuid.UUID) string {
 	return fmt.Sprintf("sudoku_session:%s", id.String())
 }
@@ -93,3 +557,43 @@ func keyLastSudokuSessionSeed() string {
 func keySudokuSessionUserID(id uuid.UUID) string {
 	return fmt.Sprintf("%s:user_id", keySudokuSession(id))
 }
+
+func keySudokuSessionStatus(id uuid.UUID) string {
+	return fmt.Sprintf("%s:status", keySudokuSession(id))
+}
+
+func keySudokuSessionSolvedAt(id uuid.UUID) string {
+	return fmt.Sprintf("%s:solved_at", keySudokuSession(id))
+}
+
+func keySudokuSessionSolveTime(id uuid.UUID) string {
+	return fmt.Sprintf("%s:solve_time", keySudokuSession(id))
+}
+
+func keySudokuSessionTimer(id uuid.UUID) string {
+	return fmt.Sprintf("%s:timer", keySudokuSession(id))
+}
+
+func keySudokuSessionState(id uuid.UUID) string {
+	return fmt.Sprintf("%s:state", keySudokuSession(id))
+}
+
+func keySudokuSessionUndoStates(id uuid.UUID) string {
+	return fmt.Sprintf("%s:undo_states", keySudokuSession(id))
+}
+
+func keySudokuSessionStats(id uuid.UUID) string {
+	return fmt.Sprintf("%s:stats", keySudokuSession(id))
+}
+
+func keySudokuSessionScore(id uuid.UUID) string {
+	return fmt.Sprintf("%s:score", keySudokuSession(id))
+}
+
+func keySudokuSessionCreatedAt(id uuid.UUID) string {
+	return fmt.Sprintf("%s:created_at", keySudokuSession(id))
+}
+
+func keySudokuSessionMoves(id uuid.UUID) string {
+	return fmt.Sprintf("%s:moves", keySudokuSession(id))
+}
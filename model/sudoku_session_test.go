@@ -0,0 +1,255 @@
+package model
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"github.com/gomodule/redigo/redis"
+	"testing"
+	"time"
+)
+
+// testSudokuSession returns a new session of the anonymous user.
+func testSudokuSession(t *testing.T) SudokuSession {
+	t.Helper()
+	conn := testConn(t)
+	sudoku, err := NewSudoku(conn, "1", "1", data.SudokuLevelEasy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := NewSudokuSession(conn, sudoku, User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func TestSudokuSession_Solve(t *testing.T) {
+	session := testSudokuSession(t)
+	startedAt := time.Date(2022, 1, 2, 15, 0, 0, 0, time.UTC)
+	timer := data.SudokuSessionTimer{}
+	timer.Start(startedAt)
+	if err := session.SetTimer(timer); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		solvedAt time.Time
+		want     bool
+	}{
+		{name: "first", solvedAt: startedAt.Add(time.Minute), want: true},
+		{name: "repeated", solvedAt: startedAt.Add(2 * time.Minute), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := session.Solve(tt.solvedAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Solve() = %v, want %v", got, tt.want)
+			}
+			if status, err := session.Status(); err != nil {
+				t.Fatal(err)
+			} else if status != data.SudokuSessionSolved {
+				t.Errorf("Status() = %s, want %s", status, data.SudokuSessionSolved)
+			}
+			if solveTime, err := session.SolveTime(); err != nil {
+				t.Fatal(err)
+			} else if solveTime != time.Minute {
+				t.Errorf("SolveTime() = %s, want %s", solveTime, time.Minute)
+			}
+		})
+	}
+}
+
+func TestUser_History(t *testing.T) {
+	conn := testConn(t)
+	user, err := NewUser(conn, "username")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history, err := user.History(); err != nil {
+		t.Fatal(err)
+	} else if len(history) != 0 {
+		t.Fatalf("History() of new user = %v, want empty", history)
+	}
+
+	sudoku, err := NewSudoku(conn, "1", "1", data.SudokuLevelHard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solved, err := NewSudokuSession(conn, sudoku, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	startedAt := time.Now()
+	timer := data.SudokuSessionTimer{}
+	timer.Start(startedAt)
+	if err := solved.SetTimer(timer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := solved.Solve(startedAt.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	score := data.SudokuScore{Version: 1, Points: 100}
+	if err := solved.SetScore(score); err != nil {
+		t.Fatal(err)
+	}
+	inProgress, err := NewSudokuSession(conn, sudoku, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	history, err := user.History()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("len(History()) = %d, want 2", len(history))
+	}
+	// both sessions can be created in the same millisecond
+	items := make(map[string]data.SudokuHistoryItem)
+	for _, item := range history {
+		items[item.SessionID] = item
+	}
+	tests := []struct {
+		name      string
+		sessionID string
+		status    data.SudokuSessionStatus
+		solveTime time.Duration
+		score     *data.SudokuScore
+	}{
+		{name: "solved", sessionID: solved.ID().String(), status: data.SudokuSessionSolved, solveTime: time.Minute, score: &score},
+		{name: "in progress", sessionID: inProgress.ID().String(), status: data.SudokuSessionInProgress},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item, ok := items[tt.sessionID]
+			if !ok {
+				t.Fatalf("session %s not found in History()", tt.sessionID)
+			}
+			if item.Level != data.SudokuLevelHard {
+				t.Errorf("Level = %s, want %s", item.Level, data.SudokuLevelHard)
+			}
+			if item.Status != tt.status {
+				t.Errorf("Status = %s, want %s", item.Status, tt.status)
+			}
+			if item.CreatedAt.IsZero() {
+				t.Errorf("CreatedAt is zero")
+			}
+			if time.Duration(item.SolveTime) != tt.solveTime {
+				t.Errorf("SolveTime = %s, want %s", time.Duration(item.SolveTime), tt.solveTime)
+			}
+			if (item.Score == nil) != (tt.score == nil) || item.Score != nil && *item.Score != *tt.score {
+				t.Errorf("Score = %v, want %v", item.Score, tt.score)
+			}
+		})
+	}
+}
+
+func TestSudokuSession_Claim(t *testing.T) {
+	conn := testConn(t)
+	user, err := NewUser(conn, "username")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sudoku, err := NewSudoku(conn, "1", "1", data.SudokuLevelEasy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := NewSudokuSession(conn, sudoku, User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	solvedAt := time.Date(2022, 1, 2, 15, 0, 0, 0, time.UTC)
+	timer := data.SudokuSessionTimer{}
+	timer.Start(solvedAt.Add(-time.Minute))
+	if err := session.SetTimer(timer); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.Solve(solvedAt); err != nil {
+		t.Fatal(err)
+	}
+	score := data.SudokuScore{Version: 1, Points: 100}
+	if err := session.SetScore(score); err != nil {
+		t.Fatal(err)
+	}
+
+	if isClaimed, err := session.Claim(user); err != nil {
+		t.Fatal(err)
+	} else if !isClaimed {
+		t.Fatalf("Claim() = false, want true")
+	}
+	if isClaimed, err := session.Claim(user); err != nil {
+		t.Fatal(err)
+	} else if isClaimed {
+		t.Errorf("repeated Claim() = true, want false")
+	}
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want int64
+	}{
+		{name: "week of solve", at: solvedAt, want: score.Points},
+		{name: "week of claim", at: time.Now(), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := redis.Int64(conn.Do("ZSCORE", keyLeaderboard(score.Version, data.LeaderboardWeekly, data.SudokuLevelEasy, tt.at), user.id))
+			if err != nil && err != redis.ErrNil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("weekly leaderboard score = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSudokuSession_PauseIdleTimer(t *testing.T) {
+	const idle = time.Minute
+	session := testSudokuSession(t)
+	startedAt := time.Date(2022, 1, 2, 15, 0, 0, 0, time.UTC)
+	if _, isPaused, err := session.PauseIdleTimer(startedAt, idle); err != nil {
+		t.Fatal(err)
+	} else if isPaused {
+		t.Fatalf("PauseIdleTimer() of not started timer = true, want false")
+	}
+	timer := data.SudokuSessionTimer{}
+	timer.Start(startedAt)
+	if err := session.SetTimer(timer); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{name: "active", now: startedAt.Add(30 * time.Second), want: false},
+		{name: "idle", now: startedAt.Add(time.Hour), want: true},
+		{name: "paused", now: startedAt.Add(2 * time.Hour), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, isPaused, err := session.PauseIdleTimer(tt.now, idle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isPaused != tt.want {
+				t.Errorf("PauseIdleTimer() = %v, want %v", isPaused, tt.want)
+			}
+			saved, err := session.Timer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved != got {
+				t.Errorf("PauseIdleTimer() = %+v, saved %+v", got, saved)
+			}
+			if tt.want && saved.Elapsed != 0 {
+				t.Errorf("Elapsed = %s, want the time until the last activity 0s", saved.Elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"testing"
+)
+
+func TestSudokuBySeed(t *testing.T) {
+	conn := testConn(t)
+	if _, isExists, err := SudokuBySeed(conn, 42, data.SudokuLevelEasy, 1); err != nil || isExists {
+		t.Fatalf("SudokuBySeed() of unknown seed = %v, %v, want false", isExists, err)
+	}
+	var sudokus []Sudoku
+	for i := 0; i < 2; i++ {
+		sudoku, err := NewSudoku(conn, "1", "1", data.SudokuLevelEasy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sudoku.SetSeed(42, 1); err != nil {
+			t.Fatal(err)
+		}
+		sudokus = append(sudokus, sudoku)
+	}
+	// both puzzles remember the seed, the seed refers to the first one
+	for _, sudoku := range sudokus {
+		seed, version, isSeed, err := sudoku.Seed()
+		if err != nil || !isSeed || seed != 42 || version != 1 {
+			t.Errorf("Seed() = %d, %d, %v, %v, want 42, 1", seed, version, isSeed, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		seed     int64
+		level    data.SudokuLevel
+		version  int
+		isExists bool
+	}{
+		{name: "same", seed: 42, level: data.SudokuLevelEasy, version: 1, isExists: true},
+		{name: "another seed", seed: 43, level: data.SudokuLevelEasy, version: 1},
+		{name: "another level", seed: 42, level: data.SudokuLevelHard, version: 1},
+		{name: "another version", seed: 42, level: data.SudokuLevelEasy, version: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sudoku, isExists, err := SudokuBySeed(conn, tt.seed, tt.level, tt.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isExists != tt.isExists {
+				t.Fatalf("SudokuBySeed() isExists = %v, want %v", isExists, tt.isExists)
+			}
+			if isExists && sudoku.ID() != sudokus[0].ID() {
+				t.Errorf("SudokuBySeed() = %d, want %d", sudoku.ID(), sudokus[0].ID())
+			}
+		})
+	}
+
+	seed, version, isSeed, err := Sudoku{conn: conn, id: 100}.Seed()
+	if err != nil || isSeed || seed != 0 || version != 0 {
+		t.Errorf("Seed() without the seed = %d, %d, %v, %v, want false", seed, version, isSeed, err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/cnblvr/sudoku/data"
 	"github.com/gomodule/redigo/redis"
+	uuid "github.com/satori/go.uuid"
 	"time"
 )
 
@@ -116,6 +117,148 @@ func (u User) SetUserInfo(info data.UserInfo) error {
 	return err
 }
 
+// AddScore saves the score of the completed sudoku session in the user's history, updates the best score and adds
+// points to the leaderboards. Only the user's first session of the puzzle is rewarded: the solution of a replayed
+// puzzle is known, so its points would be farmed.
+func (u User) AddScore(session SudokuSession, score data.SudokuScore) error {
+	scoreBts, err := json.Marshal(score)
+	if err != nil {
+		return err
+	}
+	if _, err := u.conn.Do("HSET", keyUserScores(u.id), session.id.String(), scoreBts); err != nil {
+		return err
+	}
+	first, err := session.Sudoku().UserSession(u)
+	if err != nil {
+		return err
+	}
+	if first.id != session.id {
+		return nil
+	}
+	level, err := session.Sudoku().Level()
+	if err != nil {
+		return err
+	}
+	solvedAt, err := session.SolvedAt()
+	if err != nil {
+		return err
+	}
+	if err := addLeaderboardsScore(u.conn, u, level, score, solvedAt); err != nil {
+		return err
+	}
+	info, err := u.UserInfo()
+	if err != nil {
+		return err
+	}
+	if score.Version > info.BestScore.Version ||
+		score.Version == info.BestScore.Version && score.Points > info.BestScore.Points {
+		info.BestScore = score
+		return u.SetUserInfo(info)
+	}
+	return nil
+}
+
+// Scores returns the scores of the user's completed sudoku sessions by session ID.
+func (u User) Scores() (map[string]data.SudokuScore, error) {
+	m, err := redis.StringMap(u.conn.Do("HGETALL", keyUserScores(u.id)))
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[string]data.SudokuScore, len(m))
+	for sessionID, scoreStr := range m {
+		var score data.SudokuScore
+		if err := json.Unmarshal([]byte(scoreStr), &score); err != nil {
+			return nil, err
+		}
+		scores[sessionID] = score
+	}
+	return scores, nil
+}
+
+// AddPlayedSudoku remembers that the user has played the puzzle. Puzzles are remembered by IDs and by canonical forms,
+// so the same puzzle saved under another ID is also played.
+func (u User) AddPlayedSudoku(sudoku Sudoku) error {
+	if _, err := u.conn.Do("SADD", keyUserPlayedSudokus(u.id), sudoku.id); err != nil {
+		return err
+	}
+	canonical, err := sudoku.Canonical()
+	if err != nil {
+		return err
+	}
+	if canonical == "" {
+		return nil
+	}
+	_, err = u.conn.Do("SADD", keyUserPlayedCanonicals(u.id), canonical)
+	return err
+}
+
+// HasPlayedSudoku reports whether the user has played the puzzle or the same puzzle under another ID.
+func (u User) HasPlayedSudoku(sudoku Sudoku) (bool, error) {
+	isPlayed, err := redis.Bool(u.conn.Do("SISMEMBER", keyUserPlayedSudokus(u.id), sudoku.id))
+	if err != nil || isPlayed {
+		return isPlayed, err
+	}
+	canonical, err := sudoku.Canonical()
+	if err != nil {
+		return false, err
+	}
+	if canonical == "" {
+		return false, nil
+	}
+	return redis.Bool(u.conn.Do("SISMEMBER", keyUserPlayedCanonicals(u.id), canonical))
+}
+
+// SudokuSessions returns the user's sudoku sessions from the oldest.
+func (u User) SudokuSessions() ([]SudokuSession, error) {
+	ids, err := redis.Strings(u.conn.Do("ZRANGE", keyUserSudokuSessions(u.id), 0, -1))
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	keys := make([]interface{}, 0, len(ids))
+	uuids := make([]uuid.UUID, 0, len(ids))
+	for _, idStr := range ids {
+		id, err := uuid.FromString(idStr)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, keySudokuSession(id))
+		uuids = append(uuids, id)
+	}
+	sudokuIDs, err := redis.Values(u.conn.Do("MGET", keys...))
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]SudokuSession, 0, len(ids))
+	for i, id := range uuids {
+		if sudokuIDs[i] == nil {
+			continue
+		}
+		sudokuID, err := redis.Int64(sudokuIDs[i], nil)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, SudokuSession{
+			conn:     u.conn,
+			id:       id,
+			sudokuID: sudokuID,
+		})
+	}
+	return sessions, nil
+}
+
+// History returns the user's games from the oldest. The history is loaded by a fixed number of requests regardless of
+// the number of games.
+func (u User) History() ([]data.SudokuHistoryItem, error) {
+	sessions, err := u.SudokuSessions()
+	if err != nil {
+		return nil, err
+	}
+	return SudokuSessionsHistory(u.conn, sessions)
+}
+
 // ID returns user's id.
 func (u User) ID() int64 {
 	return u.id
@@ -234,3 +377,19 @@ func keyUserCreatedAt(id int64) string {
 func keyUserInfo(id int64) string {
 	return fmt.Sprintf("%s:info", keyUser(id))
 }
+
+func keyUserScores(id int64) string {
+	return fmt.Sprintf("%s:scores", keyUser(id))
+}
+
+func keyUserSudokuSessions(id int64) string {
+	return fmt.Sprintf("%s:sudoku_sessions", keyUser(id))
+}
+
+func keyUserPlayedSudokus(id int64) string {
+	return fmt.Sprintf("%s:played_sudokus", keyUser(id))
+}
+
+func keyUserPlayedCanonicals(id int64) string {
+	return fmt.Sprintf("%s:played_canonicals", keyUser(id))
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"fmt"
+	"github.com/gomodule/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WatchToken returns the random token of the spectators' link of the session. Only clients with the token watch the
+// game of another player. The token is created on the first call.
+func (s SudokuSession) WatchToken() (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	if _, err := s.conn.Do("SETNX", keySudokuSessionWatchToken(s.id), token); err != nil {
+		return "", err
+	}
+	// a parallel request could have created the token
+	return redis.String(s.conn.Do("GET", keySudokuSessionWatchToken(s.id)))
+}
+
+func keySudokuSessionWatchToken(id uuid.UUID) string {
+	return fmt.Sprintf("%s:watch_token", keySudokuSession(id))
+}
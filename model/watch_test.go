@@ -0,0 +1,22 @@
+package model
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSudokuSession_WatchToken(t *testing.T) {
+	session := testSudokuSession(t)
+	token, err := session.WatchToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(token) {
+		t.Errorf("WatchToken() = %s, want 32 hex digits", token)
+	}
+	if again, err := session.WatchToken(); err != nil {
+		t.Fatal(err)
+	} else if again != token {
+		t.Errorf("repeated WatchToken() = %s, want %s", again, token)
+	}
+}
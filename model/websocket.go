@@ -0,0 +1,211 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/gomodule/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+	"time"
+)
+
+// WebsocketResumeTTL is the time during which the client can resume the dropped connection. The saved state of the
+// connection expires after this time since the last change.
+const WebsocketResumeTTL = 2 * time.Minute
+
+// maxWebsocketResumeEvents is the number of the last events of the connection that are saved for resuming.
+const maxWebsocketResumeEvents = 200
+
+// WebsocketResume is the saved state of a websocket connection: the user, topics, joined co-op games, anonymous
+// sessions and the last events of the connection. The client resumes the dropped connection by its token and receives
+// the missed events.
+type WebsocketResume struct {
+	conn  redis.Conn
+	token uuid.UUID
+}
+
+// WebsocketResumeByToken returns the saved state of the connection.
+func WebsocketResumeByToken(conn redis.Conn, token uuid.UUID) WebsocketResume {
+	return WebsocketResume{
+		conn:  conn,
+		token: token,
+	}
+}
+
+func (r WebsocketResume) Token() uuid.UUID {
+	return r.token
+}
+
+// WebsocketEvent is the numbered event of the connection with the token.
+type WebsocketEvent struct {
+	Token uuid.UUID
+	Seq   int64
+	Frame []byte
+}
+
+// AddWebsocketEvents saves events of connections for resuming in one transaction. Only the last events of each
+// connection are kept.
+func AddWebsocketEvents(conn redis.Conn, events []WebsocketEvent) error {
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+	tokens := make(map[uuid.UUID]struct{})
+	for _, event := range events {
+		if err := conn.Send("ZADD", keyWebsocketResumeEvents(event.Token), event.Seq, event.Frame); err != nil {
+			return err
+		}
+		tokens[event.Token] = struct{}{}
+	}
+	for token := range tokens {
+		key := keyWebsocketResumeEvents(token)
+		if err := conn.Send("ZREMRANGEBYRANK", key, 0, -maxWebsocketResumeEvents-1); err != nil {
+			return err
+		}
+		if err := conn.Send("EXPIRE", key, int(WebsocketResumeTTL.Seconds())); err != nil {
+			return err
+		}
+	}
+	_, err := conn.Do("EXEC")
+	return err
+}
+
+// Events returns the saved events with sequence numbers greater than seq in order of the numbers.
+func (r WebsocketResume) Events(seq int64) ([][]byte, error) {
+	return redis.ByteSlices(r.conn.Do("ZRANGEBYSCORE", keyWebsocketResumeEvents(r.token), fmt.Sprintf("(%d", seq), "+inf"))
+}
+
+// Ack removes the events with sequence numbers up to seq that the client has received.
+func (r WebsocketResume) Ack(seq int64) error {
+	_, err := r.conn.Do("ZREMRANGEBYSCORE", keyWebsocketResumeEvents(r.token), "-inf", seq)
+	return err
+}
+
+// AddTopic saves the topic the connection is subscribed to.
+func (r WebsocketResume) AddTopic(topic string) error {
+	return r.updateTopics("SADD", topic)
+}
+
+// RemoveTopic removes the topic the connection is unsubscribed from.
+func (r WebsocketResume) RemoveTopic(topic string) error {
+	return r.updateTopics("SREM", topic)
+}
+
+func (r WebsocketResume) updateTopics(cmd string, topic string) error {
+	return r.update(keyWebsocketResumeTopics(r.token), cmd, topic)
+}
+
+// update executes the command with the key and extends the expiration of the key.
+func (r WebsocketResume) update(key string, cmd string, args ...interface{}) error {
+	if _, err := r.conn.Do(cmd, append([]interface{}{key}, args...)...); err != nil {
+		return err
+	}
+	_, err := r.conn.Do("EXPIRE", key, int(WebsocketResumeTTL.Seconds()))
+	return err
+}
+
+// Topics returns the topics of the connection. Returns no topics if the state has expired.
+func (r WebsocketResume) Topics() ([]string, error) {
+	return redis.Strings(r.conn.Do("SMEMBERS", keyWebsocketResumeTopics(r.token)))
+}
+
+// SetUser binds the connection to the user. The ID of the anonymous user is 0.
+func (r WebsocketResume) SetUser(userID int64) error {
+	return r.update(keyWebsocketResume(r.token), "HSET", "user", userID)
+}
+
+// UserID returns the user of the connection. Returns false if the state has expired.
+func (r WebsocketResume) UserID() (int64, bool, error) {
+	userID, err := redis.Int64(r.conn.Do("HGET", keyWebsocketResume(r.token), "user"))
+	switch err {
+	case nil:
+		return userID, true, nil
+	case redis.ErrNil:
+		return 0, false, nil
+	default:
+		return 0, false, err
+	}
+}
+
+// AddAnonymousSessions saves anonymous sessions that the connection plays.
+func (r WebsocketResume) AddAnonymousSessions(sessionIDs ...string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	args := make([]interface{}, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		args = append(args, id)
+	}
+	return r.update(keyWebsocketResumeAnonymous(r.token), "SADD", args...)
+}
+
+// AnonymousSessions returns IDs of anonymous sessions that the connection plays.
+func (r WebsocketResume) AnonymousSessions() ([]string, error) {
+	return redis.Strings(r.conn.Do("SMEMBERS", keyWebsocketResumeAnonymous(r.token)))
+}
+
+// SetCoopPlayer saves the player of the connection in the cooperative session.
+func (r WebsocketResume) SetCoopPlayer(sessionID string, player data.CoopPlayer) error {
+	playerBts, err := json.Marshal(player)
+	if err != nil {
+		return err
+	}
+	return r.update(keyWebsocketResumeCoop(r.token), "HSET", sessionID, playerBts)
+}
+
+// CoopPlayers returns players of the connection in cooperative sessions by session ID.
+func (r WebsocketResume) CoopPlayers() (map[string]data.CoopPlayer, error) {
+	values, err := redis.StringMap(r.conn.Do("HGETALL", keyWebsocketResumeCoop(r.token)))
+	if err != nil {
+		return nil, err
+	}
+	players := make(map[string]data.CoopPlayer, len(values))
+	for sessionID, value := range values {
+		var player data.CoopPlayer
+		if err := json.Unmarshal([]byte(value), &player); err != nil {
+			return nil, err
+		}
+		players[sessionID] = player
+	}
+	return players, nil
+}
+
+// Keep extends the expiration of the saved state by WebsocketResumeTTL. It is called periodically while the client is
+// connected and when the connection is dropped.
+func (r WebsocketResume) Keep() error {
+	if err := r.conn.Send("MULTI"); err != nil {
+		return err
+	}
+	for _, key := range []string{
+		keyWebsocketResume(r.token),
+		keyWebsocketResumeEvents(r.token),
+		keyWebsocketResumeTopics(r.token),
+		keyWebsocketResumeAnonymous(r.token),
+		keyWebsocketResumeCoop(r.token),
+	} {
+		if err := r.conn.Send("EXPIRE", key, int(WebsocketResumeTTL.Seconds())); err != nil {
+			return err
+		}
+	}
+	_, err := r.conn.Do("EXEC")
+	return err
+}
+
+func keyWebsocketResume(token uuid.UUID) string {
+	return fmt.Sprintf("websocket:%s", token.String())
+}
+
+func keyWebsocketResumeEvents(token uuid.UUID) string {
+	return fmt.Sprintf("%s:events", keyWebsocketResume(token))
+}
+
+func keyWebsocketResumeTopics(token uuid.UUID) string {
+	return fmt.Sprintf("%s:topics", keyWebsocketResume(token))
+}
+
+func keyWebsocketResumeAnonymous(token uuid.UUID) string {
+	return fmt.Sprintf("%s:anonymous", keyWebsocketResume(token))
+}
+
+func keyWebsocketResumeCoop(token uuid.UUID) string {
+	return fmt.Sprintf("%s:coop", keyWebsocketResume(token))
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"github.com/gomodule/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+	"reflect"
+	"testing"
+)
+
+func TestAddWebsocketEvents(t *testing.T) {
+	conn := testConn(t)
+	first, second := WebsocketResumeByToken(conn, uuid.NewV4()), WebsocketResumeByToken(conn, uuid.NewV4())
+	var events []WebsocketEvent
+	for seq := int64(1); seq <= maxWebsocketResumeEvents+10; seq++ {
+		events = append(events, WebsocketEvent{Token: first.Token(), Seq: seq, Frame: []byte{byte(seq)}})
+	}
+	events = append(events, WebsocketEvent{Token: second.Token(), Seq: 1, Frame: []byte("second")})
+	if err := AddWebsocketEvents(conn, events); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := first.Events(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != maxWebsocketResumeEvents || got[0][0] != 11 {
+		t.Errorf("Events(0) returns %d events from %d, want %d events from 11", len(got), got[0][0], maxWebsocketResumeEvents)
+	}
+	if got, err := second.Events(0); err != nil {
+		t.Fatal(err)
+	} else if len(got) != 1 || string(got[0]) != "second" {
+		t.Errorf("Events(0) of the second connection = %q, want [second]", got)
+	}
+	if err := first.Ack(200); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := first.Events(0); err != nil {
+		t.Fatal(err)
+	} else if len(got) != 10 {
+		t.Errorf("Events(0) after Ack(200) returns %d events, want 10", len(got))
+	}
+	ttl, err := redis.Int(conn.Do("TTL", keyWebsocketResumeEvents(first.Token())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl <= 0 {
+		t.Errorf("events of the connection do not expire")
+	}
+}
+
+func TestWebsocketResume(t *testing.T) {
+	conn := testConn(t)
+	resume := WebsocketResumeByToken(conn, uuid.NewV4())
+	if _, isExists, err := resume.UserID(); err != nil {
+		t.Fatal(err)
+	} else if isExists {
+		t.Errorf("UserID() of the new connection exists")
+	}
+
+	player := data.CoopPlayer{ID: "player", Color: data.CoopColors[0]}
+	if err := resume.SetUser(42); err != nil {
+		t.Fatal(err)
+	}
+	if err := resume.AddTopic("all"); err != nil {
+		t.Fatal(err)
+	}
+	if err := resume.AddAnonymousSessions("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := resume.SetCoopPlayer("session", player); err != nil {
+		t.Fatal(err)
+	}
+
+	if userID, isExists, err := resume.UserID(); err != nil {
+		t.Fatal(err)
+	} else if !isExists || userID != 42 {
+		t.Errorf("UserID() = %d, %v, want 42, true", userID, isExists)
+	}
+	if topics, err := resume.Topics(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(topics, []string{"all"}) {
+		t.Errorf("Topics() = %v, want [all]", topics)
+	}
+	if sessions, err := resume.AnonymousSessions(); err != nil {
+		t.Fatal(err)
+	} else if len(sessions) != 2 {
+		t.Errorf("AnonymousSessions() = %v, want [a b]", sessions)
+	}
+	if players, err := resume.CoopPlayers(); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(players, map[string]data.CoopPlayer{"session": player}) {
+		t.Errorf("CoopPlayers() = %v, want map[session:%v]", players, player)
+	}
+
+	if _, err := conn.Do("PERSIST", keyWebsocketResume(resume.Token())); err != nil {
+		t.Fatal(err)
+	}
+	if err := resume.Keep(); err != nil {
+		t.Fatal(err)
+	}
+	ttl, err := redis.Int(conn.Do("TTL", keyWebsocketResume(resume.Token())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ttl != int(WebsocketResumeTTL.Seconds()) {
+		t.Errorf("TTL after Keep() = %d, want %d", ttl, int(WebsocketResumeTTL.Seconds()))
+	}
+}
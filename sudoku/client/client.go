@@ -0,0 +1,216 @@
+// Package client is the Go client of the sudoku service. It calls methods of the websocket API over the websocket
+// connection or over the REST API and receives events pushed by the server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+)
+
+// APIPrefix is the path prefix of the REST API version supported by the client.
+const APIPrefix = "/api/v1"
+
+// Route binds a method of the websocket API to the REST API. Variables of the path in braces are taken from fields of
+// the request with the same JSON names, other fields are sent in the JSON body.
+type Route struct {
+	HTTPMethod string
+	// Path relative to APIPrefix.
+	Path string
+}
+
+// Routes are routes of the REST API by methods of the websocket API.
+var Routes = map[string]Route{
+	"createGame": {HTTPMethod: http.MethodPost, Path: "/games"},
+	"getPuzzle":  {HTTPMethod: http.MethodGet, Path: "/games/{sessionID}"},
+	"makeStep":   {HTTPMethod: http.MethodPost, Path: "/games/{sessionID}/steps"},
+	"getHint":    {HTTPMethod: http.MethodPost, Path: "/games/{sessionID}/hints"},
+	"undo":       {HTTPMethod: http.MethodPost, Path: "/games/{sessionID}/undo"},
+	"pause":      {HTTPMethod: http.MethodPost, Path: "/games/{sessionID}/pause"},
+	"resume":     {HTTPMethod: http.MethodPost, Path: "/games/{sessionID}/resume"},
+	"getProfile": {HTTPMethod: http.MethodGet, Path: "/users/me"},
+	"getHistory": {HTTPMethod: http.MethodGet, Path: "/users/me/history"},
+}
+
+// Client is the client of the service. Cookies of the user and of anonymous games are kept by the client and shared
+// by the REST API and websocket connections.
+type Client struct {
+	Methods
+	baseURL *url.URL
+	http    *http.Client
+}
+
+// New returns the client of the service at the base URL, for example "http://localhost:8080".
+func New(baseURL string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("scheme of the base URL must be http or https")
+	}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		baseURL: u,
+		http:    &http.Client{Jar: jar},
+	}
+	c.Methods = Methods{call: c.Call}
+	return c, nil
+}
+
+// Login authenticates the user by the login form. Requests and connections made after the login are performed on
+// behalf of the user.
+func (c *Client) Login(ctx context.Context, username, password string) error {
+	form := url.Values{}
+	form.Set("_username", username)
+	form.Set("_password", password)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url(data.EndpointLogin).String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	// the form is shown again with the error if the username or the password is wrong
+	if !c.IsAuthorized() {
+		return fmt.Errorf("failed to login: %s", resp.Status)
+	}
+	return nil
+}
+
+// Logout forgets the user.
+func (c *Client) Logout(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url(data.EndpointLogout).String(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// IsAuthorized reports whether the client has logged in.
+func (c *Client) IsAuthorized() bool {
+	for _, cookie := range c.http.Jar.Cookies(c.baseURL) {
+		if cookie.Name == "auth" && cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Call performs the method over the REST API. The method must have a route in Routes.
+func (c *Client) Call(ctx context.Context, method string, req, resp interface{}) error {
+	route, ok := Routes[method]
+	if !ok {
+		return fmt.Errorf("method '%s' is not available over the REST API", method)
+	}
+
+	// variables of the path are taken from the request
+	fields := make(map[string]json.RawMessage)
+	if req != nil {
+		bts, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(bts, &fields); err != nil {
+			return fmt.Errorf("request must be an object: %w", err)
+		}
+	}
+	path := route.Path
+	for name, value := range fields {
+		variable := "{" + name + "}"
+		if !strings.Contains(path, variable) {
+			continue
+		}
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			return fmt.Errorf("field '%s' must be a string", name)
+		}
+		path = strings.ReplaceAll(path, variable, url.PathEscape(s))
+		delete(fields, name)
+	}
+	if strings.Contains(path, "{") {
+		return fmt.Errorf("request has no variables of the path '%s'", route.Path)
+	}
+
+	var body io.Reader
+	if route.HTTPMethod != http.MethodGet {
+		bts, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(bts)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, route.HTTPMethod, c.url(APIPrefix+path).String(), body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	httpResp, err := c.http.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error *Error `json:"error"`
+		}
+		if err := json.NewDecoder(httpResp.Body).Decode(&apiErr); err != nil || apiErr.Error == nil {
+			return fmt.Errorf("unexpected response: %s", httpResp.Status)
+		}
+		return apiErr.Error
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+// url returns the URL of the path of the service.
+func (c *Client) url(path string) *url.URL {
+	u := *c.baseURL
+	u.Path = strings.TrimSuffix(u.Path, "/") + path
+	return &u
+}
+
+// Error is the error of the method returned by the server.
+type Error struct {
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+// ErrorCode is a stable code of the error of the method.
+type ErrorCode string
+
+const (
+	ErrorValidation   ErrorCode = "validation"
+	ErrorNotFound     ErrorCode = "not_found"
+	ErrorUnauthorized ErrorCode = "unauthorized"
+	ErrorInternal     ErrorCode = "internal"
+	ErrorRateLimited  ErrorCode = "rate_limited"
+	ErrorTimeout      ErrorCode = "timeout"
+)
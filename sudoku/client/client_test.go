@@ -0,0 +1,150 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestServer returns the server that answers getPuzzle by the websocket connection and the REST API. The method
+// flood pushes more events than the client keeps before the response.
+func newTestServer(t *testing.T) *httptest.Server {
+	upgrader := websocket.Upgrader{Subprotocols: []string{Subprotocol}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		_ = conn.WriteJSON(message{Event: "connected", Seq: 1, Body: json.RawMessage(`{"token":"abc","version":2}`)})
+		for {
+			var req message
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			resp := message{Method: req.Method, Echo: req.Echo}
+			switch req.Method {
+			case "getPuzzle":
+				resp.Body = json.RawMessage(`{"puzzle":"1.3","timer":{"elapsed":5,"paused":true}}`)
+			case "flood":
+				for i := 0; i < eventsBuffer; i++ {
+					_ = conn.WriteJSON(message{Event: "timerTick", Seq: int64(i + 2), Body: json.RawMessage(`{}`)})
+				}
+			default:
+				resp.Error = &Error{Code: ErrorValidation, Message: "unknown method"}
+			}
+			_ = conn.WriteJSON([]message{resp})
+		}
+	})
+	mux.HandleFunc(APIPrefix+"/games/abc/steps", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if r.Method != http.MethodPost || req["state"] != "123" || req["sessionID"] != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"code":"validation","message":"state is invalid"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"win":true,"timer":{"elapsed":7,"paused":true}}`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestConn(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := c.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := <-conn.Events()
+	if event.Name != "connected" || conn.Token() != "abc" {
+		t.Errorf("first event = %+v, token %q", event, conn.Token())
+	}
+
+	puzzle, err := conn.GetPuzzle(ctx, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if puzzle.Puzzle != "1.3" || puzzle.Timer != (Timer{Elapsed: 5, Paused: true}) {
+		t.Errorf("GetPuzzle() = %+v", puzzle)
+	}
+
+	var apiErr *Error
+	if _, err := conn.Undo(ctx, "abc"); !errors.As(err, &apiErr) || apiErr.Code != ErrorValidation {
+		t.Errorf("Undo() error = %v, want validation", err)
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-conn.Events(); ok {
+		t.Errorf("events are not closed")
+	}
+}
+
+func TestClientCall(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	ctx := context.Background()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.MakeStep(ctx, MakeStepRequest{SessionID: "abc", State: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Win || resp.Timer.Elapsed != 7 {
+		t.Errorf("MakeStep() = %+v", resp)
+	}
+
+	var apiErr *Error
+	if _, err := c.MakeStep(ctx, MakeStepRequest{SessionID: "abc", State: "1"}); !errors.As(err, &apiErr) || apiErr.Code != ErrorValidation {
+		t.Errorf("MakeStep() error = %v, want validation", err)
+	}
+	if _, err := c.Health(ctx); err == nil {
+		t.Errorf("Health() over the REST API must fail")
+	}
+}
+
+func TestConnDropsEvents(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, err := New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := c.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	// events are not read, the response is received anyway
+	if err := conn.Call(ctx, "flood", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := conn.Dropped(); got != 1 {
+		t.Errorf("Dropped() = %d, want 1", got)
+	}
+	if event := <-conn.Events(); event.Name != "connected" {
+		t.Errorf("first event = %+v, want connected", event)
+	}
+}
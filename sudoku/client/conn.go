@@ -0,0 +1,266 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Subprotocol is the version of the websocket protocol supported by the client.
+const Subprotocol = "sudoku.v2"
+
+// closeWait is the time to wait for the server to close the connection after the close message.
+const closeWait = time.Second
+
+// eventsBuffer is the number of events kept for the reader of Conn.Events.
+const eventsBuffer = 64
+
+// Event is the message pushed by the server.
+type Event struct {
+	Name string
+	// Seq is the sequence number of the event of the connection.
+	Seq  int64
+	Body json.RawMessage
+}
+
+// Decode unmarshals the body of the event, for example into SessionMovesEvent.
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Body, v)
+}
+
+// message is the frame of the websocket protocol.
+type message struct {
+	Method string          `json:"method,omitempty"`
+	Event  string          `json:"event,omitempty"`
+	Echo   string          `json:"echo,omitempty"`
+	Error  *Error          `json:"error,omitempty"`
+	Seq    int64           `json:"seq,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// Conn is the websocket connection to the service. Methods are called concurrently, pushed events are received from
+// Events.
+type Conn struct {
+	Methods
+	conn *websocket.Conn
+
+	writeMx sync.Mutex
+
+	mx      sync.Mutex
+	echo    int64
+	pending map[string]chan message
+	token   string
+	err     error
+	dropped int64
+
+	events chan Event
+	done   chan struct{}
+}
+
+// Connect opens the websocket connection with cookies of the client.
+func (c *Client) Connect(ctx context.Context) (*Conn, error) {
+	u := c.url("/ws")
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+	} else {
+		u.Scheme = "ws"
+	}
+	dialer := websocket.Dialer{
+		Subprotocols: []string{Subprotocol},
+		Jar:          c.http.Jar,
+	}
+	wsConn, resp, err := dialer.DialContext(ctx, u.String(), http.Header{})
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("failed to connect: %s: %w", resp.Status, err)
+		}
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	if wsConn.Subprotocol() != Subprotocol {
+		wsConn.Close()
+		return nil, fmt.Errorf("server does not support the protocol %s", Subprotocol)
+	}
+	conn := &Conn{
+		conn:    wsConn,
+		pending: make(map[string]chan message),
+		events:  make(chan Event, eventsBuffer),
+		done:    make(chan struct{}),
+	}
+	conn.Methods = Methods{call: conn.Call}
+	go conn.readLoop()
+	return conn, nil
+}
+
+// Events returns events pushed by the server. The channel is closed when the connection is closed. Events that do not
+// fit in the buffer of the channel are dropped, so responses are received even if events are not read. Gaps in Seq
+// of events show the dropped events.
+func (c *Conn) Events() <-chan Event {
+	return c.events
+}
+
+// Dropped returns the number of events dropped because the channel of events was full.
+func (c *Conn) Dropped() int64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.dropped
+}
+
+// Token returns the token of the connection for resuming it on the server.
+func (c *Conn) Token() string {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.token
+}
+
+// Done is closed when the connection is closed.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason of closing the connection.
+func (c *Conn) Err() error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.err
+}
+
+// Close closes the connection normally, the server forgets subscriptions of the connection.
+func (c *Conn) Close() error {
+	c.writeMx.Lock()
+	err := c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	c.writeMx.Unlock()
+	if err != nil {
+		return c.conn.Close()
+	}
+	select {
+	case <-c.done:
+	case <-time.After(closeWait):
+		return c.conn.Close()
+	}
+	return nil
+}
+
+// Call performs the method over the websocket connection. resp can be nil if the response is not needed.
+func (c *Conn) Call(ctx context.Context, method string, req, resp interface{}) error {
+	var body json.RawMessage
+	if req != nil {
+		bts, err := json.Marshal(req)
+		if err != nil {
+			return err
+		}
+		body = bts
+	}
+
+	c.mx.Lock()
+	if c.err != nil {
+		c.mx.Unlock()
+		return c.err
+	}
+	c.echo++
+	echo := strconv.FormatInt(c.echo, 10)
+	done := make(chan message, 1)
+	c.pending[echo] = done
+	c.mx.Unlock()
+	defer func() {
+		c.mx.Lock()
+		delete(c.pending, echo)
+		c.mx.Unlock()
+	}()
+
+	bts, err := json.Marshal(message{Method: method, Echo: echo, Body: body})
+	if err != nil {
+		return err
+	}
+	c.writeMx.Lock()
+	err = c.conn.WriteMessage(websocket.TextMessage, bts)
+	c.writeMx.Unlock()
+	if err != nil {
+		return err
+	}
+
+	select {
+	case msg := <-done:
+		if msg.Error != nil {
+			return msg.Error
+		}
+		if resp == nil || len(msg.Body) == 0 {
+			return nil
+		}
+		return json.Unmarshal(msg.Body, resp)
+	case <-c.done:
+		return c.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// readLoop dispatches responses to callers by echo and events to the channel of events.
+func (c *Conn) readLoop() {
+	var err error
+	defer func() {
+		c.mx.Lock()
+		c.err = fmt.Errorf("connection closed: %w", err)
+		c.mx.Unlock()
+		close(c.events)
+		close(c.done)
+		c.conn.Close()
+	}()
+	for {
+		var frame []byte
+		_, frame, err = c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msgs []message
+		if trimmed := bytes.TrimSpace(frame); len(trimmed) > 0 && trimmed[0] == '[' {
+			err = json.Unmarshal(trimmed, &msgs)
+		} else {
+			var msg message
+			err = json.Unmarshal(frame, &msg)
+			msgs = append(msgs, msg)
+		}
+		if err != nil {
+			return
+		}
+		for _, msg := range msgs {
+			if msg.Event != "" {
+				c.handleEvent(msg)
+				continue
+			}
+			c.mx.Lock()
+			done, ok := c.pending[msg.Echo]
+			c.mx.Unlock()
+			if ok {
+				done <- msg
+			}
+		}
+	}
+}
+
+func (c *Conn) handleEvent(msg message) {
+	if msg.Event == "connected" {
+		var connected ConnectedEvent
+		if err := json.Unmarshal(msg.Body, &connected); err == nil {
+			c.mx.Lock()
+			c.token = connected.Token
+			c.mx.Unlock()
+		}
+	}
+	select {
+	case c.events <- Event{
+		Name: msg.Event,
+		Seq:  msg.Seq,
+		Body: msg.Body,
+	}:
+	default:
+		c.mx.Lock()
+		c.dropped++
+		c.mx.Unlock()
+	}
+}
@@ -0,0 +1,44 @@
+package client
+
+import (
+	"github.com/cnblvr/sudoku/data"
+)
+
+// ConnectedEvent is the first event of the connection.
+type ConnectedEvent struct {
+	Token   string `json:"token"`
+	Version int    `json:"version"`
+}
+
+// TimerTickEvent synchronizes the game time of the session.
+type TimerTickEvent struct {
+	SessionID string `json:"sessionID"`
+	Timer     Timer  `json:"timer"`
+}
+
+// SessionExpiredEvent is sent when the timer of the idle game is paused by the server.
+type SessionExpiredEvent struct {
+	SessionID string `json:"sessionID"`
+	Timer     Timer  `json:"timer"`
+}
+
+// SessionMovesEvent is sent to spectators of the session after each change of the state.
+type SessionMovesEvent struct {
+	Moves []data.SudokuMove `json:"moves"`
+	Win   bool              `json:"win,omitempty"`
+	Timer Timer             `json:"timer"`
+}
+
+// LeaderboardUpdatedEvent is sent to subscribers of the leaderboard when the user's points are changed.
+type LeaderboardUpdatedEvent struct {
+	Level  data.SudokuLevel       `json:"level"`
+	Period data.LeaderboardPeriod `json:"period"`
+	UserID int64                  `json:"userID"`
+}
+
+// UserNotificationEvent is a notification of the user on all their connections.
+type UserNotificationEvent struct {
+	Kind      string `json:"kind"`
+	SessionID string `json:"sessionID,omitempty"`
+	Score     int64  `json:"score,omitempty"`
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"github.com/cnblvr/sudoku/data"
+	"time"
+)
+
+// Methods are typed methods of the websocket API. Methods without a typed wrapper are called by Call of the
+// connection or the client.
+type Methods struct {
+	call func(ctx context.Context, method string, req, resp interface{}) error
+}
+
+// Timer is the game time of the session.
+type Timer struct {
+	// Game time in milliseconds.
+	Elapsed int64 `json:"elapsed"`
+	// Flag indicates if the timer is stopped.
+	Paused bool `json:"paused"`
+}
+
+// Health returns "OK" if the service works with its database. Only over the websocket connection.
+func (m Methods) Health(ctx context.Context) (string, error) {
+	var resp string
+	err := m.call(ctx, "health", nil, &resp)
+	return resp, err
+}
+
+type CreateGameRequest struct {
+	Level data.SudokuLevel `json:"level,omitempty"`
+	Mode  string           `json:"mode,omitempty"`
+}
+
+type CreateGameResponse struct {
+	SessionID string           `json:"sessionID"`
+	Level     data.SudokuLevel `json:"level"`
+}
+
+// CreateGame starts a new game of the level. Anonymous clients create games only by the REST API, which saves the game
+// in the cookie.
+func (m Methods) CreateGame(ctx context.Context, req CreateGameRequest) (CreateGameResponse, error) {
+	var resp CreateGameResponse
+	err := m.call(ctx, "createGame", req, &resp)
+	return resp, err
+}
+
+type sessionRequest struct {
+	SessionID string `json:"sessionID"`
+}
+
+type GetPuzzleResponse struct {
+	Puzzle string `json:"puzzle"`
+	State  string `json:"state,omitempty"`
+	Timer  Timer  `json:"timer"`
+}
+
+// GetPuzzle returns the puzzle and the state of the session.
+func (m Methods) GetPuzzle(ctx context.Context, sessionID string) (GetPuzzleResponse, error) {
+	var resp GetPuzzleResponse
+	err := m.call(ctx, "getPuzzle", sessionRequest{SessionID: sessionID}, &resp)
+	return resp, err
+}
+
+type MakeStepRequest struct {
+	SessionID string `json:"sessionID"`
+	// State of 81 cells, '.' or '0' are empty cells.
+	State string `json:"state"`
+}
+
+type MakeStepResponse struct {
+	Errors []data.Point `json:"errors,omitempty"`
+	Win    bool         `json:"win,omitempty"`
+	Time   int64        `json:"time,omitempty"`
+	Score  int64        `json:"score,omitempty"`
+	Timer  Timer        `json:"timer"`
+}
+
+// MakeStep saves the new state of the session.
+func (m Methods) MakeStep(ctx context.Context, req MakeStepRequest) (MakeStepResponse, error) {
+	var resp MakeStepResponse
+	err := m.call(ctx, "makeStep", req, &resp)
+	return resp, err
+}
+
+type GetHintResponse struct {
+	Point data.Point `json:"point"`
+	Digit int8       `json:"digit"`
+	State string     `json:"state"`
+	Win   bool       `json:"win,omitempty"`
+	Time  int64      `json:"time,omitempty"`
+	Score int64      `json:"score,omitempty"`
+	Timer Timer      `json:"timer"`
+}
+
+// GetHint fills a cell of the session with the digit of the solution.
+func (m Methods) GetHint(ctx context.Context, sessionID string) (GetHintResponse, error) {
+	var resp GetHintResponse
+	err := m.call(ctx, "getHint", sessionRequest{SessionID: sessionID}, &resp)
+	return resp, err
+}
+
+type UndoResponse struct {
+	State string `json:"state"`
+	Timer Timer  `json:"timer"`
+}
+
+// Undo returns the session to the previous state.
+func (m Methods) Undo(ctx context.Context, sessionID string) (UndoResponse, error) {
+	var resp UndoResponse
+	err := m.call(ctx, "undo", sessionRequest{SessionID: sessionID}, &resp)
+	return resp, err
+}
+
+type timerResponse struct {
+	Timer Timer `json:"timer"`
+}
+
+// Pause stops the timer of the session.
+func (m Methods) Pause(ctx context.Context, sessionID string) (Timer, error) {
+	var resp timerResponse
+	err := m.call(ctx, "pause", sessionRequest{SessionID: sessionID}, &resp)
+	return resp.Timer, err
+}
+
+// Resume starts the timer of the session.
+func (m Methods) Resume(ctx context.Context, sessionID string) (Timer, error) {
+	var resp timerResponse
+	err := m.call(ctx, "resume", sessionRequest{SessionID: sessionID}, &resp)
+	return resp.Timer, err
+}
+
+type GetProfileResponse struct {
+	ID        int64            `json:"id"`
+	Username  string           `json:"username"`
+	Name      string           `json:"name"`
+	BestScore data.SudokuScore `json:"bestScore"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+// GetProfile returns the profile of the authorized user.
+func (m Methods) GetProfile(ctx context.Context) (GetProfileResponse, error) {
+	var resp GetProfileResponse
+	err := m.call(ctx, "getProfile", struct{}{}, &resp)
+	return resp, err
+}
+
+type GetHistoryResponse struct {
+	History []data.SudokuHistoryItem `json:"history"`
+	Stats   data.UserStats           `json:"stats"`
+}
+
+// GetHistory returns games and statistics of the authorized user.
+func (m Methods) GetHistory(ctx context.Context) (GetHistoryResponse, error) {
+	var resp GetHistoryResponse
+	err := m.call(ctx, "getHistory", struct{}{}, &resp)
+	return resp, err
+}
+
+type topicRequest struct {
+	Topic string `json:"topic"`
+}
+
+// Subscribe subscribes the connection to events of the topic, for example "leaderboard:hard:week". Only over the
+// websocket connection.
+func (m Methods) Subscribe(ctx context.Context, topic string) error {
+	return m.call(ctx, "subscribe", topicRequest{Topic: topic}, nil)
+}
+
+// Unsubscribe unsubscribes the connection from events of the topic. Only over the websocket connection.
+func (m Methods) Unsubscribe(ctx context.Context, topic string) error {
+	return m.call(ctx, "unsubscribe", topicRequest{Topic: topic}, nil)
+}
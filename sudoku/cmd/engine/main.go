@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/cnblvr/sudoku/sudoku/enginepb"
+	"github.com/cnblvr/sudoku/sudoku/internal/engine"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	// Logger initialization
+	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMicro
+	log.Logger = zerolog.New(zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+		w.TimeFormat = "2006-01-02 15:04:05.000000Z"
+	})).With().Timestamp().Caller().Logger()
+
+	lis, err := net.Listen("tcp", ":9090") // todo port from env vars
+	if err != nil {
+		log.Fatal().Err(err).Msg("net.Listen failed")
+	}
+	server := grpc.NewServer()
+	enginepb.RegisterEngineServer(server, engine.NewServer())
+	go func() {
+		if err := server.Serve(lis); err != nil {
+			log.Fatal().Err(err).Msg("grpc.Server.Serve failed")
+		}
+	}()
+	log.Info().Str("addr", lis.Addr().String()).Msg("engine is started")
+
+	// Graceful shutdown
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+	log.Info().Msg("shutting down")
+	server.GracefulStop()
+}
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"context"
 	sudoku "github.com/cnblvr/sudoku/sudoku/internal"
 	"github.com/cnblvr/sudoku/sudoku/static"
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
@@ -47,15 +52,67 @@ func main() {
 	rPages.Path("/signup").Methods(http.MethodGet, http.MethodPost).HandlerFunc(srv.HandleSignup)
 	// User's info page and handler
 	rAuth.Path("/info").Methods(http.MethodGet, http.MethodPost).HandlerFunc(srv.HandleUserInfo)
+	// User's games history and statistics in JSON
+	rAuth.Path("/info/history").Methods(http.MethodGet).HandlerFunc(srv.HandleUserHistory)
 	// Puzzle create game page
 	rPages.Path("/sudoku/play").Methods(http.MethodGet).HandlerFunc(srv.HandleSudokuCreate)
+	// Puzzle generated by the seed
+	rPages.Path("/sudoku/seed/{seed:[0-9]+}").Methods(http.MethodGet).HandlerFunc(srv.HandleSudokuSeed)
+	// Puzzle of the day handler
+	rAuth.Path("/sudoku/daily").Methods(http.MethodGet).HandlerFunc(srv.HandleSudokuDaily)
+	// Leaderboard page of the puzzle of the day
+	rPages.Path("/sudoku/daily/leaderboard").Methods(http.MethodGet).HandlerFunc(srv.HandleSudokuDailyLeaderboard)
+	// Leaderboards page
+	rPages.Path("/leaderboard").Methods(http.MethodGet).HandlerFunc(srv.HandleLeaderboard)
 	// Puzzle page
-	rPages.Path("/sudoku/{session_id}").Methods(http.MethodGet).HandlerFunc(srv.HandleSudoku)
+	rPages.Path("/sudoku/{session_id:[0-9a-f-]{36}}").Methods(http.MethodGet).HandlerFunc(srv.HandleSudoku)
+	// Puzzle page for spectators
+	rPages.Path("/sudoku/{session_id:[0-9a-f-]{36}}/watch").Methods(http.MethodGet).HandlerFunc(srv.HandleSudokuWatch)
+	// Replay page of the finished game
+	rPages.Path("/sudoku/{session_id:[0-9a-f-]{36}}/replay").Methods(http.MethodGet).HandlerFunc(srv.HandleSudokuReplay)
+
+	// Race create handler
+	rPages.Path("/race/new").Methods(http.MethodGet).HandlerFunc(srv.HandleRaceCreate)
+	// Race page
+	rPages.Path("/race/{race_id:[0-9a-f-]{36}}").Methods(http.MethodGet).HandlerFunc(srv.HandleRace)
+
+	// Challenge link: a new game on the shared puzzle
+	rPages.Path("/challenge/{token:[0-9a-f]{32}}").Methods(http.MethodGet).HandlerFunc(srv.HandleChallenge)
+	// Times of all players of the shared puzzle
+	rPages.Path("/challenge/{token:[0-9a-f]{32}}/results").Methods(http.MethodGet).HandlerFunc(srv.HandleChallengeResults)
 
 	// Websocket handler
 	rPages.Path("/ws").Methods(http.MethodGet).HandlerFunc(srv.HandleWebsocket)
 
-	if err := http.ListenAndServe(":8080", r); err != nil {
-		log.Fatal().Err(err).Msg("http.ListenAndServe failed")
+	// Router rAPI for the REST API. Anonymous clients are identified by cookies like on pages.
+	rAPI := r.PathPrefix(sudoku.APIPrefix).Subrouter()
+	rAPI.Use(srv.MiddlewareCookies)
+	for _, route := range sudoku.APIRoutes {
+		rAPI.Path(route.Path).Methods(route.HTTPMethod).HandlerFunc(srv.HandleAPI(route.Method))
+	}
+	// Documents of the REST API and the websocket API
+	r.Path("/api/openapi.json").Methods(http.MethodGet).HandlerFunc(srv.HandleOpenAPI)
+	r.Path("/api/asyncapi.json").Methods(http.MethodGet).HandlerFunc(srv.HandleAsyncAPI)
+
+	server := &http.Server{
+		Addr:    ":8080",
+		Handler: r,
+	}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal().Err(err).Msg("http.ListenAndServe failed")
+		}
+	}()
+
+	// Graceful shutdown
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	<-stop
+	log.Info().Msg("shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	srv.Shutdown()
+	if err := server.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("http.Server.Shutdown failed")
 	}
 }
@@ -0,0 +1,258 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.3
+// source: engine.proto
+
+package enginepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Engine_Generate_FullMethodName       = "/sudoku.engine.v1.Engine/Generate"
+	Engine_GenerateStream_FullMethodName = "/sudoku.engine.v1.Engine/GenerateStream"
+	Engine_Solve_FullMethodName          = "/sudoku.engine.v1.Engine/Solve"
+	Engine_Grade_FullMethodName          = "/sudoku.engine.v1.Engine/Grade"
+)
+
+// EngineClient is the client API for Engine service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type EngineClient interface {
+	// Generate returns a new puzzle of the difficulty.
+	Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*Puzzle, error)
+	// GenerateStream returns puzzles for the bulk request. Puzzles are sent as soon as they are generated, so their
+	// order can differ from the order of seeds.
+	GenerateStream(ctx context.Context, in *GenerateStreamRequest, opts ...grpc.CallOption) (Engine_GenerateStreamClient, error)
+	// Solve returns the only solution of the puzzle and steps of the solution.
+	Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (*SolveResponse, error)
+	// Grade returns the difficulty of the puzzle by techniques required for the solution.
+	Grade(ctx context.Context, in *GradeRequest, opts ...grpc.CallOption) (*GradeResponse, error)
+}
+
+type engineClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewEngineClient(cc grpc.ClientConnInterface) EngineClient {
+	return &engineClient{cc}
+}
+
+func (c *engineClient) Generate(ctx context.Context, in *GenerateRequest, opts ...grpc.CallOption) (*Puzzle, error) {
+	out := new(Puzzle)
+	err := c.cc.Invoke(ctx, Engine_Generate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *engineClient) GenerateStream(ctx context.Context, in *GenerateStreamRequest, opts ...grpc.CallOption) (Engine_GenerateStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Engine_ServiceDesc.Streams[0], Engine_GenerateStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &engineGenerateStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Engine_GenerateStreamClient interface {
+	Recv() (*Puzzle, error)
+	grpc.ClientStream
+}
+
+type engineGenerateStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *engineGenerateStreamClient) Recv() (*Puzzle, error) {
+	m := new(Puzzle)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *engineClient) Solve(ctx context.Context, in *SolveRequest, opts ...grpc.CallOption) (*SolveResponse, error) {
+	out := new(SolveResponse)
+	err := c.cc.Invoke(ctx, Engine_Solve_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *engineClient) Grade(ctx context.Context, in *GradeRequest, opts ...grpc.CallOption) (*GradeResponse, error) {
+	out := new(GradeResponse)
+	err := c.cc.Invoke(ctx, Engine_Grade_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// EngineServer is the server API for Engine service.
+// All implementations must embed UnimplementedEngineServer
+// for forward compatibility
+type EngineServer interface {
+	// Generate returns a new puzzle of the difficulty.
+	Generate(context.Context, *GenerateRequest) (*Puzzle, error)
+	// GenerateStream returns puzzles for the bulk request. Puzzles are sent as soon as they are generated, so their
+	// order can differ from the order of seeds.
+	GenerateStream(*GenerateStreamRequest, Engine_GenerateStreamServer) error
+	// Solve returns the only solution of the puzzle and steps of the solution.
+	Solve(context.Context, *SolveRequest) (*SolveResponse, error)
+	// Grade returns the difficulty of the puzzle by techniques required for the solution.
+	Grade(context.Context, *GradeRequest) (*GradeResponse, error)
+	mustEmbedUnimplementedEngineServer()
+}
+
+// UnimplementedEngineServer must be embedded to have forward compatible implementations.
+type UnimplementedEngineServer struct {
+}
+
+func (UnimplementedEngineServer) Generate(context.Context, *GenerateRequest) (*Puzzle, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Generate not implemented")
+}
+func (UnimplementedEngineServer) GenerateStream(*GenerateStreamRequest, Engine_GenerateStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method GenerateStream not implemented")
+}
+func (UnimplementedEngineServer) Solve(context.Context, *SolveRequest) (*SolveResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Solve not implemented")
+}
+func (UnimplementedEngineServer) Grade(context.Context, *GradeRequest) (*GradeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Grade not implemented")
+}
+func (UnimplementedEngineServer) mustEmbedUnimplementedEngineServer() {}
+
+// UnsafeEngineServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to EngineServer will
+// result in compilation errors.
+type UnsafeEngineServer interface {
+	mustEmbedUnimplementedEngineServer()
+}
+
+func RegisterEngineServer(s grpc.ServiceRegistrar, srv EngineServer) {
+	s.RegisterService(&Engine_ServiceDesc, srv)
+}
+
+func _Engine_Generate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GenerateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServer).Generate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Engine_Generate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServer).Generate(ctx, req.(*GenerateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Engine_GenerateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(GenerateStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EngineServer).GenerateStream(m, &engineGenerateStreamServer{stream})
+}
+
+type Engine_GenerateStreamServer interface {
+	Send(*Puzzle) error
+	grpc.ServerStream
+}
+
+type engineGenerateStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *engineGenerateStreamServer) Send(m *Puzzle) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Engine_Solve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SolveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServer).Solve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Engine_Solve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServer).Solve(ctx, req.(*SolveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Engine_Grade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GradeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EngineServer).Grade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Engine_Grade_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EngineServer).Grade(ctx, req.(*GradeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Engine_ServiceDesc is the grpc.ServiceDesc for Engine service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Engine_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sudoku.engine.v1.Engine",
+	HandlerType: (*EngineServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Generate",
+			Handler:    _Engine_Generate_Handler,
+		},
+		{
+			MethodName: "Solve",
+			Handler:    _Engine_Solve_Handler,
+		},
+		{
+			MethodName: "Grade",
+			Handler:    _Engine_Grade_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GenerateStream",
+			Handler:       _Engine_GenerateStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "engine.proto",
+}
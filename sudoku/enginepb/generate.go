@@ -0,0 +1,4 @@
+// Package enginepb contains protobuf messages and the gRPC service of the puzzle engine.
+package enginepb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative engine.proto
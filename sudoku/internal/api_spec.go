@@ -0,0 +1,391 @@
+package sudoku
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// APISpecs are the OpenAPI document of the REST API and the AsyncAPI document of the websocket API. The documents are
+// generated from the request and response types, the test of the package fails if they are outdated.
+//
+//go:embed api
+var APISpecs embed.FS
+
+const (
+	apiSpecOpenAPI  = "api/openapi.json"
+	apiSpecAsyncAPI = "api/asyncapi.json"
+)
+
+// HandleOpenAPI returns the OpenAPI document of the REST API.
+func (srv *Service) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	serveAPISpec(w, apiSpecOpenAPI)
+}
+
+// HandleAsyncAPI returns the AsyncAPI document of the websocket API.
+func (srv *Service) HandleAsyncAPI(w http.ResponseWriter, r *http.Request) {
+	serveAPISpec(w, apiSpecAsyncAPI)
+}
+
+func serveAPISpec(w http.ResponseWriter, name string) {
+	bts, err := APISpecs.ReadFile(name)
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(bts)
+}
+
+// jsonSchema is the subset of JSON Schema used in the API documents.
+type jsonSchema struct {
+	Ref                  string                 `json:"$ref,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	OneOf                []*jsonSchema          `json:"oneOf,omitempty"`
+}
+
+// apiSchemaTypes are types with their own JSON encoding.
+var apiSchemaTypes = map[reflect.Type]jsonSchema{
+	reflect.TypeOf(time.Time{}):                {Type: "string", Format: "date-time"},
+	reflect.TypeOf(json.RawMessage{}):          {},
+	reflect.TypeOf(data.Duration(0)):           {Type: "integer", Format: "int64", Description: "Milliseconds."},
+	reflect.TypeOf(data.Point{}):               {Type: "string", Pattern: "^[a-i][1-9]$", Description: "Row letter and column number."},
+	reflect.TypeOf(data.SudokuLevel("")):       {Type: "string", Enum: apiEnum(data.SudokuLevels)},
+	reflect.TypeOf(data.LeaderboardPeriod("")): {Type: "string", Enum: apiEnum(data.LeaderboardPeriods)},
+	reflect.TypeOf(data.SudokuSessionStatus("")): {
+		Type: "string", Enum: []string{string(data.SudokuSessionInProgress), string(data.SudokuSessionSolved)},
+	},
+	reflect.TypeOf(websocketErrorCode("")): {
+		Type: "string", Enum: apiEnum([]websocketErrorCode{
+			websocketErrorValidation, websocketErrorNotFound, websocketErrorUnauthorized,
+			websocketErrorInternal, websocketErrorRateLimited, websocketErrorTimeout,
+		}),
+	},
+}
+
+func apiEnum(values interface{}) []string {
+	v := reflect.ValueOf(values)
+	enum := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		enum = append(enum, v.Index(i).String())
+	}
+	return enum
+}
+
+// apiSchemas collects schemas of named struct types as components of the document.
+type apiSchemas map[string]*jsonSchema
+
+// apiSchemaName returns the name of the component of the type: the name of the type without the prefix 'websocket'.
+func apiSchemaName(t reflect.Type) string {
+	name := strings.TrimPrefix(t.Name(), "websocket")
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// schema returns the schema of the type. Named structs are referenced as components.
+func (s apiSchemas) schema(t reflect.Type) *jsonSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if schema, ok := apiSchemaTypes[t]; ok {
+		return &schema
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Int, reflect.Uint:
+		return &jsonSchema{Type: "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return &jsonSchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}
+	case reflect.String:
+		return &jsonSchema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &jsonSchema{Type: "array", Items: s.schema(t.Elem())}
+	case reflect.Map:
+		return &jsonSchema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		name := apiSchemaName(t)
+		if _, ok := s[name]; !ok {
+			s[name] = nil // recursive types
+			s[name] = s.object(t)
+		}
+		return &jsonSchema{Ref: "#/components/schemas/" + name}
+	default:
+		// interfaces are any values
+		return &jsonSchema{}
+	}
+}
+
+// object returns the schema of the struct by rules of encoding/json. Fields without omitempty are required.
+func (s apiSchemas) object(t reflect.Type) *jsonSchema {
+	schema := &jsonSchema{
+		Type:       "object",
+		Properties: make(map[string]*jsonSchema),
+	}
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts := tag, ""
+			if i := strings.IndexByte(tag, ','); i >= 0 {
+				name, opts = tag[:i], tag[i+1:]
+			}
+			if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+				addFields(field.Type)
+				continue
+			}
+			if field.PkgPath != "" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			schema.Properties[name] = s.schema(field.Type)
+			if !strings.Contains(opts, "omitempty") {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+	addFields(t)
+	sort.Strings(schema.Required)
+	return schema
+}
+
+// apiMethodErrors returns codes of errors of the method.
+func apiMethodErrors(method string) []websocketErrorCode {
+	reqType, _ := websocketPool.Types(method)
+	req := reflect.New(reqType).Interface().(websocketRequest)
+	return append(append([]websocketErrorCode(nil), websocketCommonErrors...), req.Errors()...)
+}
+
+// apiPathVar matches variables of the path of the route with their patterns. Patterns can contain repetitions in
+// braces.
+var apiPathVar = regexp.MustCompile(`\{([A-Za-z]+)(:(?:[^{}]|\{[^{}]*})*)?}`)
+
+// generateOpenAPI returns the OpenAPI document of APIRoutes.
+func generateOpenAPI() ([]byte, error) {
+	schemas := make(apiSchemas)
+	errorSchema := &jsonSchema{
+		Type: "object",
+		Properties: map[string]*jsonSchema{
+			"error": schemas.schema(reflect.TypeOf(websocketError{})),
+		},
+		Required: []string{"error"},
+	}
+	paths := make(map[string]map[string]interface{})
+	for _, route := range APIRoutes {
+		reqType, respType := websocketPool.Types(route.Method)
+		if reqType == nil {
+			return nil, fmt.Errorf("method '%s' of route '%s' is not registered", route.Method, route.Path)
+		}
+		path := APIPrefix + apiPathVar.ReplaceAllString(route.Path, "{$1}")
+		operation := map[string]interface{}{
+			"operationId": route.Method,
+		}
+
+		// variables of the path are removed from the body
+		body := schemas.object(reqType)
+		var parameters []interface{}
+		for _, match := range apiPathVar.FindAllStringSubmatch(route.Path, -1) {
+			name := match[1]
+			param, ok := body.Properties[name]
+			if !ok {
+				return nil, fmt.Errorf("variable '%s' of route '%s' is not a field of the request", name, route.Path)
+			}
+			parameters = append(parameters, map[string]interface{}{
+				"name":     name,
+				"in":       "path",
+				"required": true,
+				"schema":   param,
+			})
+			delete(body.Properties, name)
+			body.Required = apiWithout(body.Required, name)
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if len(body.Properties) > 0 {
+			if route.HTTPMethod == http.MethodGet {
+				return nil, fmt.Errorf("route '%s' has a body in GET request", route.Path)
+			}
+			operation["requestBody"] = map[string]interface{}{
+				"required": len(body.Required) > 0,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": body},
+				},
+			}
+		}
+
+		responses := map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "OK",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": schemas.schema(respType)},
+				},
+			},
+		}
+		codesByStatus := make(map[int][]string)
+		for _, code := range apiMethodErrors(route.Method) {
+			status, ok := apiErrorStatuses[code]
+			if !ok {
+				return nil, fmt.Errorf("error '%s' of method '%s' has no HTTP status", code, route.Method)
+			}
+			codesByStatus[status] = append(codesByStatus[status], string(code))
+		}
+		for status, codes := range codesByStatus {
+			responses[fmt.Sprint(status)] = map[string]interface{}{
+				"description": "Error codes: " + strings.Join(codes, ", "),
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{"schema": &jsonSchema{Ref: "#/components/schemas/ErrorResponse"}},
+				},
+			}
+		}
+		operation["responses"] = responses
+
+		if paths[path] == nil {
+			paths[path] = make(map[string]interface{})
+		}
+		paths[path][strings.ToLower(route.HTTPMethod)] = operation
+	}
+	schemas["ErrorResponse"] = errorSchema
+
+	return json.MarshalIndent(map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":       "Sudoku REST API",
+			"version":     "1",
+			"description": "Requests are executed by the same methods as in the websocket API. Anonymous sessions are identified by the 'sessions' cookie, users by the 'auth' cookie.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}, "", "  ")
+}
+
+// generateAsyncAPI returns the AsyncAPI document of the websocket methods and events.
+func generateAsyncAPI() ([]byte, error) {
+	schemas := make(apiSchemas)
+	messages := make(map[string]interface{})
+	var publish, subscribe []*jsonSchema
+	message := func(name string, envelope *jsonSchema) {
+		messages[name] = map[string]interface{}{
+			"name":    name,
+			"payload": envelope,
+		}
+	}
+
+	for _, method := range websocketPool.Methods() {
+		reqType, respType := websocketPool.Types(method)
+		name := apiSchemaName(reqType)
+		message(name, &jsonSchema{
+			Type: "object",
+			Properties: map[string]*jsonSchema{
+				"method": {Type: "string", Enum: []string{method}},
+				"echo":   {Type: "string"},
+				"body":   schemas.schema(reqType),
+			},
+			Required: []string{"method"},
+		})
+		publish = append(publish, &jsonSchema{Ref: "#/components/messages/" + name})
+
+		name = apiSchemaName(respType)
+		var codes []string
+		for _, code := range apiMethodErrors(method) {
+			codes = append(codes, string(code))
+		}
+		message(name, &jsonSchema{
+			Type: "object",
+			Properties: map[string]*jsonSchema{
+				"method": {Type: "string", Enum: []string{method}},
+				"echo":   {Type: "string"},
+				"error":  {Ref: "#/components/schemas/Error", Description: "Error codes: " + strings.Join(codes, ", ")},
+				"body":   schemas.schema(respType),
+			},
+			Required: []string{"method"},
+		})
+		subscribe = append(subscribe, &jsonSchema{Ref: "#/components/messages/" + name})
+	}
+	schemas.schema(reflect.TypeOf(websocketError{}))
+
+	names, types := websocketEvents.Events()
+	for i, event := range names {
+		name := apiSchemaName(types[i])
+		message(name, &jsonSchema{
+			Type: "object",
+			Properties: map[string]*jsonSchema{
+				"event": {Type: "string", Enum: []string{event}},
+				"seq":   {Type: "integer", Format: "int64"},
+				"body":  schemas.schema(types[i]),
+			},
+			Required: []string{"event"},
+		})
+		subscribe = append(subscribe, &jsonSchema{Ref: "#/components/messages/" + name})
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"asyncapi": "2.6.0",
+		"info": map[string]interface{}{
+			"title":   "Sudoku websocket API",
+			"version": "2",
+			"description": "The version of the protocol is chosen by the subprotocol " + strings.Join(websocketSubprotocols, " or ") +
+				". Since the second version a frame can contain an array of requests answered by an array of responses.",
+		},
+		"servers": map[string]interface{}{
+			"sudoku": map[string]interface{}{
+				"url":      "localhost:8080",
+				"protocol": "ws",
+			},
+		},
+		"channels": map[string]interface{}{
+			"/ws": map[string]interface{}{
+				"publish": map[string]interface{}{
+					"message": map[string]interface{}{"oneOf": publish},
+				},
+				"subscribe": map[string]interface{}{
+					"message": map[string]interface{}{"oneOf": subscribe},
+				},
+			},
+		},
+		"components": map[string]interface{}{
+			"messages": messages,
+			"schemas":  schemas,
+		},
+	}, "", "  ")
+}
+
+func apiWithout(values []string, value string) []string {
+	var out []string
+	for _, v := range values {
+		if v != value {
+			out = append(out, v)
+		}
+	}
+	return out
+}
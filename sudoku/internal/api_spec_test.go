@@ -0,0 +1,290 @@
+package sudoku
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/sudoku/client"
+	"github.com/gorilla/websocket"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+var updateAPISpecs = flag.Bool("update", false, "update the API documents")
+
+// TestAPISpecs fails if the API documents differ from the request and response types. Run with -update after the
+// change of the API.
+func TestAPISpecs(t *testing.T) {
+	tests := []struct {
+		name     string
+		generate func() ([]byte, error)
+	}{
+		{name: apiSpecOpenAPI, generate: generateOpenAPI},
+		{name: apiSpecAsyncAPI, generate: generateAsyncAPI},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.generate()
+			if err != nil {
+				t.Fatalf("failed to generate: %v", err)
+			}
+			got = append(got, '\n')
+			if *updateAPISpecs {
+				if err := os.WriteFile(filepath.FromSlash(tt.name), got, 0644); err != nil {
+					t.Fatalf("failed to update: %v", err)
+				}
+				return
+			}
+			want, err := APISpecs.ReadFile(tt.name)
+			if err != nil {
+				t.Fatalf("failed to read: %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s is outdated, run 'go test ./sudoku/internal/ -run TestAPISpecs -update'", tt.name)
+			}
+		})
+	}
+}
+
+// TestClientRoutes fails if routes of the Go client differ from routes of the REST API.
+func TestClientRoutes(t *testing.T) {
+	if client.APIPrefix != APIPrefix {
+		t.Errorf("client.APIPrefix = %s, want %s", client.APIPrefix, APIPrefix)
+	}
+	if len(client.Routes) != len(APIRoutes) {
+		t.Errorf("client has %d routes, want %d", len(client.Routes), len(APIRoutes))
+	}
+	for _, route := range APIRoutes {
+		want := client.Route{
+			HTTPMethod: route.HTTPMethod,
+			Path:       apiPathVar.ReplaceAllString(route.Path, "{$1}"),
+		}
+		if got := client.Routes[route.Method]; got != want {
+			t.Errorf("client route of %s = %+v, want %+v", route.Method, got, want)
+		}
+	}
+}
+
+// apiExample fills the value with non-zero values that are valid by rules of apiSchemaTypes.
+func apiExample(v reflect.Value) {
+	t := v.Type()
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		v.Set(reflect.ValueOf(time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)))
+		return
+	case reflect.TypeOf(json.RawMessage{}):
+		v.Set(reflect.ValueOf(json.RawMessage(`{}`)))
+		return
+	case reflect.TypeOf(data.Duration(0)):
+		v.Set(reflect.ValueOf(data.Duration(time.Millisecond)))
+		return
+	}
+	if schema, ok := apiSchemaTypes[t]; ok && len(schema.Enum) > 0 {
+		v.SetString(schema.Enum[0])
+		return
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		v.Set(reflect.New(t.Elem()))
+		apiExample(v.Elem())
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(t, 1, 1))
+		apiExample(v.Index(0))
+	case reflect.Map:
+		key, elem := reflect.New(t.Key()).Elem(), reflect.New(t.Elem()).Elem()
+		apiExample(key)
+		apiExample(elem)
+		v.Set(reflect.MakeMap(t))
+		v.SetMapIndex(key, elem)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath == "" {
+				apiExample(v.Field(i))
+			}
+		}
+	}
+}
+
+// jsonEqual returns true if JSON documents are equal regardless of the order of fields.
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("failed to unmarshal %s: %v", b, err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// TestClientBodies fails if bodies of requests, responses and events of the Go client differ from types of the
+// service. Requests of the client are decoded strictly into requests of the service, responses and events of the
+// service filled with example values are decoded by the client without losing fields.
+func TestClientBodies(t *testing.T) {
+	type call struct {
+		method string
+		body   json.RawMessage
+	}
+	calls := make(chan call, 1)
+	upgrader := websocket.Upgrader{Subprotocols: []string{client.Subprotocol}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("failed to upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		for {
+			var req websocketMessage
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			calls <- call{method: req.Method, body: req.Body}
+			_, respType := websocketPool.Types(req.Method)
+			if respType == nil {
+				t.Errorf("unknown method %s", req.Method)
+				return
+			}
+			resp := reflect.New(respType)
+			apiExample(resp.Elem())
+			body, err := json.Marshal(resp.Interface())
+			if err != nil {
+				t.Errorf("failed to marshal the response of %s: %v", req.Method, err)
+				return
+			}
+			_ = conn.WriteJSON(websocketMessage{Method: req.Method, Echo: req.Echo, Body: body})
+		}
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := client.New(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := c.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	timerResponse := func(timer client.Timer, err error) (interface{}, error) {
+		return struct {
+			Timer client.Timer `json:"timer"`
+		}{Timer: timer}, err
+	}
+	topicResponse := func(err error) (interface{}, error) {
+		return struct {
+			Topic string `json:"topic"`
+		}{Topic: "x"}, err
+	}
+	tests := []struct {
+		method string
+		call   func() (interface{}, error)
+	}{
+		{method: "health", call: func() (interface{}, error) { return conn.Health(ctx) }},
+		{method: "createGame", call: func() (interface{}, error) {
+			return conn.CreateGame(ctx, client.CreateGameRequest{Level: data.SudokuLevels[0], Mode: "race"})
+		}},
+		{method: "getPuzzle", call: func() (interface{}, error) { return conn.GetPuzzle(ctx, "x") }},
+		{method: "makeStep", call: func() (interface{}, error) {
+			return conn.MakeStep(ctx, client.MakeStepRequest{SessionID: "x", State: "x"})
+		}},
+		{method: "getHint", call: func() (interface{}, error) { return conn.GetHint(ctx, "x") }},
+		{method: "undo", call: func() (interface{}, error) { return conn.Undo(ctx, "x") }},
+		{method: "pause", call: func() (interface{}, error) { return timerResponse(conn.Pause(ctx, "x")) }},
+		{method: "resume", call: func() (interface{}, error) { return timerResponse(conn.Resume(ctx, "x")) }},
+		{method: "getProfile", call: func() (interface{}, error) { return conn.GetProfile(ctx) }},
+		{method: "getHistory", call: func() (interface{}, error) { return conn.GetHistory(ctx) }},
+		{method: "subscribe", call: func() (interface{}, error) { return topicResponse(conn.Subscribe(ctx, "x")) }},
+		{method: "unsubscribe", call: func() (interface{}, error) { return topicResponse(conn.Unsubscribe(ctx, "x")) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			resp, err := tt.call()
+			if err != nil {
+				t.Fatalf("call error = %v", err)
+			}
+			got := <-calls
+			if got.method != tt.method {
+				t.Fatalf("method = %s, want %s", got.method, tt.method)
+			}
+			reqType, respType := websocketPool.Types(tt.method)
+			if len(got.body) > 0 {
+				dec := json.NewDecoder(bytes.NewReader(got.body))
+				dec.DisallowUnknownFields()
+				if err := dec.Decode(reflect.New(reqType).Interface()); err != nil {
+					t.Errorf("request %s does not match the service: %v", got.body, err)
+				}
+			}
+			for _, field := range (apiSchemas{}).schema(reqType).Required {
+				var fields map[string]json.RawMessage
+				if err := json.Unmarshal(got.body, &fields); err != nil || fields[field] == nil {
+					t.Errorf("request %s has no required field %s", got.body, field)
+				}
+			}
+			want := reflect.New(respType)
+			apiExample(want.Elem())
+			wantBody, _ := json.Marshal(want.Interface())
+			gotBody, _ := json.Marshal(resp)
+			if !jsonEqual(t, gotBody, wantBody) {
+				t.Errorf("response = %s, want %s", gotBody, wantBody)
+			}
+		})
+	}
+	if methods := len(tests); methods != len(client.Routes)+3 {
+		t.Errorf("%d methods are tested, the client has %d", methods, len(client.Routes)+3)
+	}
+
+	events := []struct {
+		name   string
+		client interface{}
+	}{
+		{name: "connected", client: &client.ConnectedEvent{}},
+		{name: "timerTick", client: &client.TimerTickEvent{}},
+		{name: "sessionExpired", client: &client.SessionExpiredEvent{}},
+		{name: "sessionMoves", client: &client.SessionMovesEvent{}},
+		{name: "leaderboardUpdated", client: &client.LeaderboardUpdatedEvent{}},
+		{name: "userNotification", client: &client.UserNotificationEvent{}},
+	}
+	names, types := websocketEvents.Events()
+	eventTypes := make(map[string]reflect.Type)
+	for i, name := range names {
+		eventTypes[name] = types[i]
+	}
+	for _, tt := range events {
+		t.Run(tt.name, func(t *testing.T) {
+			eventType, ok := eventTypes[tt.name]
+			if !ok {
+				t.Fatalf("event %s is not registered", tt.name)
+			}
+			event := reflect.New(eventType)
+			apiExample(event.Elem())
+			want, _ := json.Marshal(event.Interface())
+			if err := json.Unmarshal(want, tt.client); err != nil {
+				t.Fatalf("failed to decode %s: %v", want, err)
+			}
+			got, _ := json.Marshal(tt.client)
+			if !jsonEqual(t, got, want) {
+				t.Errorf("event = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/sudoku/enginepb"
+	"github.com/cnblvr/sudoku/sudoku/internal/sudoku_classic"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// maxStreamCount is the maximum number of puzzles of one bulk request.
+const maxStreamCount = 1000
+
+// maxSeed bounds seeds of puzzles. The generator seeds rand.NewSource that reduces the seed modulo 2^31-1, so greater
+// seeds would repeat puzzles of smaller ones.
+const maxSeed = 1<<31 - 1
+
+// maxSolveTime is the time limit of solving one puzzle. The search of solutions of puzzles with few digits is long.
+const maxSolveTime = 5 * time.Second
+
+// Server is the gRPC service of the sudoku_classic engine.
+type Server struct {
+	enginepb.UnimplementedEngineServer
+	// Number of puzzles generated at the same time for one bulk request
+	workers int
+
+	rndMx sync.Mutex
+	rnd   *rand.Rand
+}
+
+// NewServer returns the engine service.
+func NewServer() *Server {
+	return &Server{
+		workers: runtime.NumCPU(),
+		rnd:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Generate returns a new puzzle of the difficulty. The seed is below maxSeed, zero means a random seed.
+func (s *Server) Generate(ctx context.Context, req *enginepb.GenerateRequest) (*enginepb.Puzzle, error) {
+	level, err := levelFromDifficulty(req.GetDifficulty())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	seed := req.GetSeed()
+	if seed < 0 || seed >= maxSeed {
+		return nil, status.Errorf(codes.InvalidArgument, "seed must be from 0 to %d", maxSeed-1)
+	}
+	if seed == 0 {
+		seed = s.randomSeed(1)
+	}
+	return generatePuzzle(seed, level), nil
+}
+
+// GenerateStream sends puzzles of consecutive seeds as soon as workers generate them. All seeds are below maxSeed.
+func (s *Server) GenerateStream(req *enginepb.GenerateStreamRequest, stream enginepb.Engine_GenerateStreamServer) error {
+	level, err := levelFromDifficulty(req.GetDifficulty())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if req.GetCount() <= 0 || req.GetCount() > maxStreamCount {
+		return status.Errorf(codes.InvalidArgument, "count must be from 1 to %d", maxStreamCount)
+	}
+	seed := req.GetSeed()
+	if seed < 0 || seed > maxSeed-int64(req.GetCount()) {
+		return status.Errorf(codes.InvalidArgument, "seeds must be from 0 to %d", maxSeed-1)
+	}
+	if seed == 0 {
+		seed = s.randomSeed(int64(req.GetCount()))
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	seeds := make(chan int64)
+	go func() {
+		defer close(seeds)
+		for i := int64(0); i < int64(req.GetCount()); i++ {
+			select {
+			case seeds <- seed + i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	puzzles := make(chan *enginepb.Puzzle)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seed := range seeds {
+				select {
+				case puzzles <- generatePuzzle(seed, level):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(puzzles)
+	}()
+
+	for puzzle := range puzzles {
+		if err := stream.Send(puzzle); err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// Solve returns the only solution of the puzzle and steps of the solution.
+func (s *Server) Solve(ctx context.Context, req *enginepb.SolveRequest) (*enginepb.SolveResponse, error) {
+	puzzle, err := puzzleFromGrid(req.GetPuzzle())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ctx, cancel := context.WithTimeout(ctx, maxSolveTime)
+	defer cancel()
+	solution, steps, err := sudoku_classic.SolveContext(ctx, puzzle)
+	if err != nil {
+		return nil, solveError(ctx, err)
+	}
+	return &enginepb.SolveResponse{
+		Solution: &enginepb.Grid{Cells: solution.String()},
+		Steps:    solveStepsToProto(steps),
+	}, nil
+}
+
+// Grade returns the difficulty of the puzzle by techniques required for the solution.
+func (s *Server) Grade(ctx context.Context, req *enginepb.GradeRequest) (*enginepb.GradeResponse, error) {
+	puzzle, err := puzzleFromGrid(req.GetPuzzle())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	ctx, cancel := context.WithTimeout(ctx, maxSolveTime)
+	defer cancel()
+	level, steps, err := sudoku_classic.GradeContext(ctx, puzzle)
+	if err != nil {
+		return nil, solveError(ctx, err)
+	}
+	return &enginepb.GradeResponse{
+		Difficulty: difficultyFromLevel(level),
+		Hints:      int32(81 - len(steps)),
+		Steps:      solveStepsToProto(steps),
+	}, nil
+}
+
+// solveError returns the status of the error of the solver: the puzzle is invalid or the time limit is exceeded.
+func solveError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+// randomSeed returns a random first seed of count consecutive seeds below maxSeed.
+func (s *Server) randomSeed(count int64) int64 {
+	s.rndMx.Lock()
+	defer s.rndMx.Unlock()
+	return s.rnd.Int63n(maxSeed - count + 1)
+}
+
+// generatePuzzle creates the puzzle by the current version of the generator.
+func generatePuzzle(seed int64, level data.SudokuLevel) *enginepb.Puzzle {
+	sudoku := sudoku_classic.NewSudoku(seed, level)
+	return &enginepb.Puzzle{
+		Seed:             seed,
+		Difficulty:       difficultyFromLevel(level),
+		Puzzle:           &enginepb.Grid{Cells: sudoku.Puzzle().String()},
+		Solution:         &enginepb.Grid{Cells: sudoku.Board().String()},
+		GeneratorVersion: sudoku_classic.GeneratorVersion,
+	}
+}
+
+// puzzleFromGrid returns the puzzle of the grid. Cells are digits, '0' or '.'.
+func puzzleFromGrid(grid *enginepb.Grid) (data.SudokuPuzzle, error) {
+	cells := grid.GetCells()
+	if len(cells) != 81 {
+		return nil, fmt.Errorf("grid must have 81 cells")
+	}
+	for i := 0; i < len(cells); i++ {
+		if (cells[i] < '0' || cells[i] > '9') && cells[i] != '.' {
+			return nil, fmt.Errorf("cell %d is not a digit", i)
+		}
+	}
+	return sudoku_classic.PuzzleFromString(cells), nil
+}
+
+func levelFromDifficulty(difficulty enginepb.Difficulty) (data.SudokuLevel, error) {
+	switch difficulty {
+	case enginepb.Difficulty_DIFFICULTY_EASY:
+		return data.SudokuLevelEasy, nil
+	case enginepb.Difficulty_DIFFICULTY_UNSPECIFIED, enginepb.Difficulty_DIFFICULTY_MEDIUM:
+		return data.SudokuLevelMedium, nil
+	case enginepb.Difficulty_DIFFICULTY_HARD:
+		return data.SudokuLevelHard, nil
+	default:
+		return "", fmt.Errorf("unknown difficulty %d", difficulty)
+	}
+}
+
+func difficultyFromLevel(level data.SudokuLevel) enginepb.Difficulty {
+	switch level {
+	case data.SudokuLevelEasy:
+		return enginepb.Difficulty_DIFFICULTY_EASY
+	case data.SudokuLevelMedium:
+		return enginepb.Difficulty_DIFFICULTY_MEDIUM
+	case data.SudokuLevelHard:
+		return enginepb.Difficulty_DIFFICULTY_HARD
+	default:
+		return enginepb.Difficulty_DIFFICULTY_UNSPECIFIED
+	}
+}
+
+var techniques = map[sudoku_classic.Technique]enginepb.Technique{
+	sudoku_classic.TechniqueNakedSingle:  enginepb.Technique_TECHNIQUE_NAKED_SINGLE,
+	sudoku_classic.TechniqueHiddenSingle: enginepb.Technique_TECHNIQUE_HIDDEN_SINGLE,
+	sudoku_classic.TechniqueGuess:        enginepb.Technique_TECHNIQUE_GUESS,
+}
+
+func solveStepsToProto(steps []sudoku_classic.SolveStep) []*enginepb.SolveStep {
+	out := make([]*enginepb.SolveStep, 0, len(steps))
+	for _, step := range steps {
+		out = append(out, &enginepb.SolveStep{
+			Point:     &enginepb.Point{Row: int32(step.Point.Row), Col: int32(step.Point.Col)},
+			Digit:     int32(step.Digit),
+			Technique: techniques[step.Technique],
+		})
+	}
+	return out
+}
@@ -0,0 +1,315 @@
+package engine
+
+import (
+	"context"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/sudoku/enginepb"
+	"github.com/cnblvr/sudoku/sudoku/internal/sudoku_classic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"io"
+	"net"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testClient returns the client of the engine served in memory.
+func testClient(t *testing.T, srv *Server) enginepb.EngineClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := grpc.NewServer()
+	enginepb.RegisterEngineServer(grpcServer, srv)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return enginepb.NewEngineClient(conn)
+}
+
+func TestServer_Generate(t *testing.T) {
+	client := testClient(t, NewServer())
+	want := sudoku_classic.NewSudoku(2, data.SudokuLevelEasy)
+
+	tests := []struct {
+		name     string
+		req      *enginepb.GenerateRequest
+		wantCode codes.Code
+	}{
+		{name: "seed", req: &enginepb.GenerateRequest{Seed: 2, Difficulty: enginepb.Difficulty_DIFFICULTY_EASY}},
+		{name: "random seed", req: &enginepb.GenerateRequest{Difficulty: enginepb.Difficulty_DIFFICULTY_EASY}},
+		{name: "maximum seed", req: &enginepb.GenerateRequest{Seed: maxSeed - 1, Difficulty: enginepb.Difficulty_DIFFICULTY_EASY}},
+		{name: "too big seed", req: &enginepb.GenerateRequest{Seed: maxSeed, Difficulty: enginepb.Difficulty_DIFFICULTY_EASY}, wantCode: codes.InvalidArgument},
+		{name: "negative seed", req: &enginepb.GenerateRequest{Seed: -1, Difficulty: enginepb.Difficulty_DIFFICULTY_EASY}, wantCode: codes.InvalidArgument},
+		{name: "unknown difficulty", req: &enginepb.GenerateRequest{Difficulty: 100}, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.Generate(context.Background(), tt.req)
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Generate() error = %v, want code %s", err, tt.wantCode)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+			if got.GetGeneratorVersion() != sudoku_classic.GeneratorVersion {
+				t.Errorf("Generate() generator version = %d, want %d", got.GetGeneratorVersion(), sudoku_classic.GeneratorVersion)
+			}
+			if got.GetDifficulty() != tt.req.GetDifficulty() {
+				t.Errorf("Generate() difficulty = %s, want %s", got.GetDifficulty(), tt.req.GetDifficulty())
+			}
+			if tt.req.GetSeed() == 0 {
+				if got.GetSeed() < 0 || got.GetSeed() >= maxSeed {
+					t.Errorf("Generate() seed = %d, want [0, %d)", got.GetSeed(), maxSeed)
+				}
+				return
+			}
+			if got.GetSeed() != tt.req.GetSeed() {
+				t.Errorf("Generate() seed = %d, want %d", got.GetSeed(), tt.req.GetSeed())
+			}
+			if tt.req.GetSeed() != 2 {
+				return
+			}
+			if got.GetPuzzle().GetCells() != want.Puzzle().String() {
+				t.Errorf("Generate() puzzle = %s, want %s", got.GetPuzzle().GetCells(), want.Puzzle())
+			}
+			if got.GetSolution().GetCells() != want.Board().String() {
+				t.Errorf("Generate() solution = %s, want %s", got.GetSolution().GetCells(), want.Board())
+			}
+		})
+	}
+}
+
+func TestServer_GenerateStream(t *testing.T) {
+	client := testClient(t, NewServer())
+
+	tests := []struct {
+		name     string
+		seed     int64
+		count    int32
+		wantCode codes.Code
+	}{
+		{name: "one", seed: 100, count: 1},
+		{name: "several", seed: 100, count: 5},
+		{name: "zero", seed: 100, count: 0, wantCode: codes.InvalidArgument},
+		{name: "negative", seed: 100, count: -1, wantCode: codes.InvalidArgument},
+		{name: "maximum", seed: 100, count: maxStreamCount + 1, wantCode: codes.InvalidArgument},
+		{name: "maximum seed", seed: maxSeed - 2, count: 2},
+		{name: "too big seed", seed: maxSeed - 1, count: 2, wantCode: codes.InvalidArgument},
+		{name: "negative seed", seed: -1, count: 2, wantCode: codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream, err := client.GenerateStream(context.Background(), &enginepb.GenerateStreamRequest{
+				Seed:       tt.seed,
+				Difficulty: enginepb.Difficulty_DIFFICULTY_EASY,
+				Count:      tt.count,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			seeds := make(map[int64]bool)
+			for {
+				puzzle, err := stream.Recv()
+				if err == io.EOF {
+					break
+				}
+				if status.Code(err) != tt.wantCode {
+					t.Fatalf("GenerateStream() error = %v, want code %s", err, tt.wantCode)
+				}
+				if err != nil {
+					return
+				}
+				if puzzle.GetGeneratorVersion() != sudoku_classic.GeneratorVersion {
+					t.Errorf("GenerateStream() generator version = %d, want %d", puzzle.GetGeneratorVersion(), sudoku_classic.GeneratorVersion)
+				}
+				seeds[puzzle.GetSeed()] = true
+			}
+			if tt.wantCode != codes.OK {
+				t.Fatalf("GenerateStream() error = nil, want code %s", tt.wantCode)
+			}
+			if len(seeds) != int(tt.count) {
+				t.Errorf("GenerateStream() sent %d puzzles, want %d", len(seeds), tt.count)
+			}
+			for seed := tt.seed; seed < tt.seed+int64(tt.count); seed++ {
+				if !seeds[seed] {
+					t.Errorf("GenerateStream() has not sent the puzzle of seed %d", seed)
+				}
+			}
+		})
+	}
+}
+
+// TestServer_GenerateStreamCancel checks that workers of the bulk request stop when the client cancels it.
+func TestServer_GenerateStreamCancel(t *testing.T) {
+	srv := NewServer()
+	srv.workers = 4
+	client := testClient(t, srv)
+	// warm up the connection, its goroutines are not counted
+	if _, err := client.Generate(context.Background(), &enginepb.GenerateRequest{Seed: 2, Difficulty: enginepb.Difficulty_DIFFICULTY_EASY}); err != nil {
+		t.Fatal(err)
+	}
+	goroutines := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := client.GenerateStream(ctx, &enginepb.GenerateStreamRequest{
+		Seed:       100,
+		Difficulty: enginepb.Difficulty_DIFFICULTY_EASY,
+		Count:      maxStreamCount,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	cancel()
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if status.Code(err) != codes.Canceled {
+				t.Errorf("GenerateStream() error = %v, want code %s", err, codes.Canceled)
+			}
+			break
+		}
+	}
+
+	deadline := time.Now().Add(10 * time.Second)
+	for runtime.NumGoroutine() > goroutines {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines are running after the cancellation, want %d", runtime.NumGoroutine(), goroutines)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServer_Solve(t *testing.T) {
+	client := testClient(t, NewServer())
+
+	tests := []struct {
+		name     string
+		puzzle   string
+		want     string
+		wantCode codes.Code
+	}{
+		{
+			name:   "solution",
+			puzzle: "...1.5...14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9...",
+			want:   "672145398145983672389762451263574819958621743714398526597236184426817935831459267",
+		},
+		{
+			name:   "zeros",
+			puzzle: "400000938032094100095300240370609004529001673604703090957008300003900400240030709",
+			want:   "461572938732894156895316247378629514529481673614753892957248361183967425246135789",
+		},
+		{
+			name:     "no solutions",
+			puzzle:   "12345678.........9" + strings.Repeat(".", 63),
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "many solutions",
+			puzzle:   strings.Repeat(".", 81),
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "rules broken",
+			puzzle:   "11" + strings.Repeat(".", 79),
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "short grid",
+			puzzle:   strings.Repeat(".", 80),
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "not a digit",
+			puzzle:   "x" + strings.Repeat(".", 80),
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.Solve(context.Background(), &enginepb.SolveRequest{Puzzle: &enginepb.Grid{Cells: tt.puzzle}})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Solve() error = %v, want code %s", err, tt.wantCode)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+			if got.GetSolution().GetCells() != tt.want {
+				t.Errorf("Solve() = %s, want %s", got.GetSolution().GetCells(), tt.want)
+			}
+			if len(got.GetSteps()) != strings.Count(strings.ReplaceAll(tt.puzzle, "0", "."), ".") {
+				t.Errorf("Solve() steps = %d, want a step for each empty cell", len(got.GetSteps()))
+			}
+		})
+	}
+}
+
+func TestServer_Grade(t *testing.T) {
+	client := testClient(t, NewServer())
+
+	tests := []struct {
+		name     string
+		puzzle   string
+		want     enginepb.Difficulty
+		wantCode codes.Code
+	}{
+		{
+			name:   "easy",
+			puzzle: "...1.5...14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9...",
+			want:   enginepb.Difficulty_DIFFICULTY_EASY,
+		},
+		{
+			name:   "medium",
+			puzzle: ".........14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9...",
+			want:   enginepb.Difficulty_DIFFICULTY_MEDIUM,
+		},
+		{
+			name:   "hard",
+			puzzle: "400000938032094100095300240370609004529001673604703090957008300003900400240030709",
+			want:   enginepb.Difficulty_DIFFICULTY_HARD,
+		},
+		{
+			name:     "no solutions",
+			puzzle:   "12345678.........9" + strings.Repeat(".", 63),
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "short grid",
+			puzzle:   strings.Repeat(".", 80),
+			wantCode: codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.Grade(context.Background(), &enginepb.GradeRequest{Puzzle: &enginepb.Grid{Cells: tt.puzzle}})
+			if status.Code(err) != tt.wantCode {
+				t.Fatalf("Grade() error = %v, want code %s", err, tt.wantCode)
+			}
+			if tt.wantCode != codes.OK {
+				return
+			}
+			if got.GetDifficulty() != tt.want {
+				t.Errorf("Grade() difficulty = %s, want %s", got.GetDifficulty(), tt.want)
+			}
+			hints := 81 - strings.Count(strings.ReplaceAll(tt.puzzle, "0", "."), ".")
+			if int(got.GetHints()) != hints {
+				t.Errorf("Grade() hints = %d, want %d", got.GetHints(), hints)
+			}
+		})
+	}
+}
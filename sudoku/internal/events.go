@@ -0,0 +1,122 @@
+package sudoku
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/rs/zerolog/log"
+	"strings"
+	"time"
+)
+
+// websocketTimerTickInterval is the period of sending the game time to players of sessions in progress.
+const websocketTimerTickInterval = 10 * time.Second
+
+// websocketTopicAll is the topic that every connection subscribes to. It is used for events for all clients.
+const websocketTopicAll = "all"
+
+func init() {
+	websocketEvents.Add((*websocketTimerTickEvent)(nil))
+	websocketEvents.Add((*websocketSessionExpiredEvent)(nil))
+	websocketEvents.Add((*websocketDailyAvailableEvent)(nil))
+}
+
+// websocketTimerTickEvent synchronizes the game time of the session with clients.
+type websocketTimerTickEvent struct {
+	SessionID string         `json:"sessionID"`
+	Timer     websocketTimer `json:"timer"`
+}
+
+func (websocketTimerTickEvent) Event() string {
+	return "timerTick"
+}
+
+// websocketSessionExpiredEvent is sent when the timer of the idle game is paused by the server.
+type websocketSessionExpiredEvent struct {
+	SessionID string         `json:"sessionID"`
+	Timer     websocketTimer `json:"timer"`
+}
+
+func (websocketSessionExpiredEvent) Event() string {
+	return "sessionExpired"
+}
+
+// websocketDailyAvailableEvent is sent to all clients when puzzles of the new day are available.
+type websocketDailyAvailableEvent struct {
+	Date   string             `json:"date"`
+	Levels []data.SudokuLevel `json:"levels"`
+}
+
+func (websocketDailyAvailableEvent) Event() string {
+	return "dailyAvailable"
+}
+
+// runTimerTicks periodically sends the game time to players and spectators of sessions in progress.
+func (srv *Service) runTimerTicks() {
+	ticker := time.NewTicker(websocketTimerTickInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		srv.tickTimers(now)
+	}
+}
+
+// tickTimers sends the game time of running timers. Timers of idle games are paused and clients are notified that the
+// session expired. Each instance checks sessions of its own connections, the timer is paused atomically by one of
+// them and the expiration is published to clients on all instances.
+func (srv *Service) tickTimers(now time.Time) {
+	redis := srv.redis.Get()
+	defer redis.Close()
+	// each instance sends ticks to its own connections
+	for _, topic := range srv.hub.topicsWithPrefix(sessionTopic("")) {
+		sessionID := strings.TrimPrefix(topic, sessionTopic(""))
+		log := log.With().Str("session", sessionID).Logger()
+		session, err := model.SudokuSessionByIDString(redis, sessionID)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get sudoku session")
+			continue
+		}
+		if session.IsNull() {
+			continue
+		}
+		status, err := session.Status()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get status of sudoku session")
+			continue
+		}
+		if status != data.SudokuSessionInProgress {
+			continue
+		}
+		timer, isPaused, err := session.PauseIdleTimer(now, sudokuIdleTimeout)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to pause timer of idle sudoku session")
+			continue
+		}
+		if isPaused {
+			srv.hub.publish(topic, websocketSessionExpiredEvent{
+				SessionID: sessionID,
+				Timer:     newWebsocketTimer(timer, now),
+			})
+			continue
+		}
+		// the idle timer that is not paused by this instance has been changed by the player or another instance
+		if !timer.IsStarted() || timer.IsPaused() || timer.IsIdle(now, sudokuIdleTimeout) {
+			continue
+		}
+		srv.hub.publishLocal(topic, websocketTimerTickEvent{
+			SessionID: sessionID,
+			Timer:     newWebsocketTimer(timer, now),
+		})
+	}
+}
+
+// runDailyNotifications notifies all clients about puzzles of the new day at midnight UTC.
+func (srv *Service) runDailyNotifications() {
+	for {
+		now := time.Now().UTC()
+		midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+		time.Sleep(midnight.Sub(now))
+		srv.hub.publishLocal(websocketTopicAll, websocketDailyAvailableEvent{
+			Date:   midnight.Format("2006-01-02"),
+			Levels: data.SudokuLevels,
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package sudoku
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"testing"
+	"time"
+)
+
+func TestService_tickTimers(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	sudoku := testSudoku(t, conn)
+	now := time.Now()
+
+	active := testSudokuSession(t, conn, sudoku)
+	idle := testSudokuSession(t, conn, sudoku)
+	timer := data.SudokuSessionTimer{}
+	timer.Start(now.Add(-2 * sudokuIdleTimeout))
+	if err := idle.SetTimer(timer); err != nil {
+		t.Fatal(err)
+	}
+	wsConn := testWebsocketConn()
+	srv.hub.subscribe(sessionTopic(active.ID().String()), wsConn)
+	srv.hub.subscribe(sessionTopic(idle.ID().String()), wsConn)
+
+	srv.tickTimers(now)
+	events := testWebsocketEvents(t, wsConn)
+	tests := []struct {
+		name      string
+		sessionID string
+		wantEvent string
+	}{
+		{name: "active", sessionID: active.ID().String(), wantEvent: "timerTick"},
+		{name: "idle", sessionID: idle.ID().String(), wantEvent: "sessionExpired"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if events[tt.sessionID] != tt.wantEvent {
+				t.Errorf("event = '%s', want '%s'", events[tt.sessionID], tt.wantEvent)
+			}
+		})
+	}
+	if timer, err := idle.Timer(); err != nil {
+		t.Fatal(err)
+	} else if !timer.IsPaused() {
+		t.Errorf("timer of idle session is not paused")
+	}
+
+	// the expiration is sent once
+	srv.tickTimers(now.Add(websocketTimerTickInterval))
+	if events := testWebsocketEvents(t, wsConn); events[idle.ID().String()] != "" {
+		t.Errorf("event of paused session = '%s', want none", events[idle.ID().String()])
+	}
+}
@@ -0,0 +1,215 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// APIPrefix is the path prefix of the current version of the REST API.
+const APIPrefix = "/api/v1"
+
+// apiBodyLimit is the maximum size of the body of a REST API request.
+const apiBodyLimit = 1 << 20
+
+// APIRoute binds a method of the websocket API to the REST API. Variables of the path are fields of the request with
+// the same names, other fields are in the JSON body.
+type APIRoute struct {
+	HTTPMethod string
+	// Path relative to APIPrefix.
+	Path string
+	// Method of the websocket API.
+	Method string
+}
+
+// APIRoutes are routes of the REST API. Requests are validated and executed by the same objects as websocket requests.
+var APIRoutes = []APIRoute{
+	{HTTPMethod: http.MethodPost, Path: "/games", Method: "createGame"},
+	{HTTPMethod: http.MethodGet, Path: "/games/{sessionID:[0-9a-f-]{36}}", Method: "getPuzzle"},
+	{HTTPMethod: http.MethodPost, Path: "/games/{sessionID:[0-9a-f-]{36}}/steps", Method: "makeStep"},
+	{HTTPMethod: http.MethodPost, Path: "/games/{sessionID:[0-9a-f-]{36}}/hints", Method: "getHint"},
+	{HTTPMethod: http.MethodPost, Path: "/games/{sessionID:[0-9a-f-]{36}}/undo", Method: "undo"},
+	{HTTPMethod: http.MethodPost, Path: "/games/{sessionID:[0-9a-f-]{36}}/pause", Method: "pause"},
+	{HTTPMethod: http.MethodPost, Path: "/games/{sessionID:[0-9a-f-]{36}}/resume", Method: "resume"},
+	{HTTPMethod: http.MethodGet, Path: "/users/me", Method: "getProfile"},
+	{HTTPMethod: http.MethodGet, Path: "/users/me/history", Method: "getHistory"},
+}
+
+// apiErrorStatuses are HTTP statuses of errors of the REST API.
+var apiErrorStatuses = map[websocketErrorCode]int{
+	websocketErrorValidation:   http.StatusBadRequest,
+	websocketErrorNotFound:     http.StatusNotFound,
+	websocketErrorUnauthorized: http.StatusForbidden,
+	websocketErrorInternal:     http.StatusInternalServerError,
+	websocketErrorRateLimited:  http.StatusTooManyRequests,
+	websocketErrorTimeout:      http.StatusGatewayTimeout,
+}
+
+// HandleAPI executes the method of the websocket API as a REST API request. The response is the body of the websocket
+// response, errors are returned as {"error": {"code": ..., "message": ...}} with the corresponding HTTP status.
+// New anonymous sessions are saved in the cookie. Requests of other sites are rejected, clients are limited like
+// websocket connections.
+func (srv *Service) HandleAPI(method string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log := log.With().Str("method", method).Logger()
+		if wsErr := checkAPIRequest(r); wsErr != nil {
+			log.Warn().Str("origin", r.Header.Get("Origin")).Str("content_type", r.Header.Get("Content-Type")).Msg(wsErr.Message)
+			writeAPIError(w, wsErr)
+			return
+		}
+		if !srv.apiLimiters.allow(apiClient(r), time.Now()) {
+			writeAPIError(w, newWebsocketError(websocketErrorRateLimited, "too many requests"))
+			return
+		}
+		redis := srv.redis.Get()
+		user, err := authUser(redis, getAuth(r))
+		redis.Close()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get user")
+			writeAPIError(w, errWebsocketInternal)
+			return
+		}
+		sessions := newAnonymousSessions(getAnonymousSessions(r))
+		ctx := r.Context()
+		ctx = context.WithValue(ctx, "log", log)
+		ctx = context.WithValue(ctx, "srv", srv)
+		ctx = context.WithValue(ctx, "user", &user)
+		ctx = context.WithValue(ctx, "anonymousSessions", sessions)
+
+		reqBody, err := apiRequestBody(r)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to read body request")
+			writeAPIError(w, newWebsocketError(websocketErrorValidation, "body invalid"))
+			return
+		}
+		respBody, wsErr := websocketRequestExecute(ctx, method, reqBody)
+		if ids, changed := sessions.list(); changed {
+			if err := srv.createSessionsCookie(w, ids); err != nil {
+				log.Error().Err(err).Msg("failed to create 'sessions' cookie")
+			}
+		}
+		if wsErr != nil {
+			writeAPIError(w, wsErr)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(respBody); err != nil {
+			log.Warn().Err(err).Msg("failed to write response")
+		}
+	}
+}
+
+// checkAPIRequest returns an error if the request can be sent by another site with the cookies of the client. The
+// Origin header must match the host. Requests changing data must have the JSON body, because browsers do not send it
+// to other sites without the consent of the server. Returned errors can be sent to the client.
+func checkAPIRequest(r *http.Request) *websocketError {
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return newWebsocketError(websocketErrorUnauthorized, "origin is not allowed")
+		}
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return nil
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		return newWebsocketError(websocketErrorValidation, "content type must be application/json")
+	}
+	return nil
+}
+
+// apiClient returns the key of the client of the REST API for the rate limiter: the user or the IP address of the
+// anonymous client.
+func apiClient(r *http.Request) string {
+	if auth := getAuth(r); auth.IsAuthorized {
+		return fmt.Sprintf("user:%d", auth.ID)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return fmt.Sprintf("ip:%s", host)
+}
+
+// apiRateLimiters are rate limiters of clients of the REST API with the limits of websocket connections. The zero
+// value is ready to use.
+type apiRateLimiters struct {
+	mx       sync.Mutex
+	limiters map[string]*websocketRateLimiter
+	sweptAt  time.Time
+}
+
+// apiRateLimitersSweep is the period of removing limiters of inactive clients.
+const apiRateLimitersSweep = time.Minute
+
+// allow takes a token of the client. Returns false if the client has exceeded the limit. Limiters of clients that
+// have been inactive for the time of filling the bucket are removed, because they are equal to new ones.
+func (l *apiRateLimiters) allow(client string, now time.Time) bool {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	if l.limiters == nil {
+		l.limiters = make(map[string]*websocketRateLimiter)
+	}
+	if now.Sub(l.sweptAt) >= apiRateLimitersSweep {
+		l.sweptAt = now
+		for key, limiter := range l.limiters {
+			if now.Sub(limiter.at) >= websocketRateBurst*time.Second/websocketRateLimit {
+				delete(l.limiters, key)
+			}
+		}
+	}
+	limiter, ok := l.limiters[client]
+	if !ok {
+		limiter = &websocketRateLimiter{}
+		l.limiters[client] = limiter
+	}
+	return limiter.allow(now)
+}
+
+// apiRequestBody returns the body of the websocket request: the JSON body of the HTTP request with variables of the
+// path.
+func apiRequestBody(r *http.Request) ([]byte, error) {
+	fields := make(map[string]json.RawMessage)
+	bodyBts, err := io.ReadAll(io.LimitReader(r.Body, apiBodyLimit))
+	if err != nil {
+		return nil, err
+	}
+	if len(bodyBts) > 0 {
+		if err := json.Unmarshal(bodyBts, &fields); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range mux.Vars(r) {
+		valueBts, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = valueBts
+	}
+	return json.Marshal(fields)
+}
+
+func writeAPIError(w http.ResponseWriter, wsErr *websocketError) {
+	status, ok := apiErrorStatuses[wsErr.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(struct {
+		Error *websocketError `json:"error"`
+	}{
+		Error: wsErr,
+	}); err != nil {
+		log.Warn().Err(err).Msg("failed to write error")
+	}
+}
@@ -0,0 +1,171 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/gorilla/mux"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testAPIRequest returns the request of the anonymous client with the sessions as prepared by MiddlewareCookies.
+func testAPIRequest(method, target, body string, vars map[string]string, sessionIDs ...string) *http.Request {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	ctx := context.WithValue(r.Context(), "auth", &data.Auth{})
+	ctx = context.WithValue(ctx, "sessions", sessionIDs)
+	return mux.SetURLVars(r.WithContext(ctx), vars)
+}
+
+func TestHandleAPI(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	session := testSudokuSession(t, conn, testSudoku(t, conn))
+	sessionID := session.ID().String()
+	vars := map[string]string{"sessionID": sessionID}
+
+	tests := []struct {
+		name       string
+		method     string
+		request    func() *http.Request
+		wantStatus int
+	}{
+		{
+			name:   "getPuzzle",
+			method: "getPuzzle",
+			request: func() *http.Request {
+				return testAPIRequest(http.MethodGet, "/api/v1/games/"+sessionID, "", vars, sessionID)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "getPuzzle of another player",
+			method: "getPuzzle",
+			request: func() *http.Request {
+				return testAPIRequest(http.MethodGet, "/api/v1/games/"+sessionID, "", vars)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:   "pause",
+			method: "pause",
+			request: func() *http.Request {
+				r := testAPIRequest(http.MethodPost, "/api/v1/games/"+sessionID+"/pause", "{}", vars, sessionID)
+				r.Header.Set("Content-Type", "application/json; charset=utf-8")
+				r.Header.Set("Origin", "http://"+r.Host)
+				return r
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:   "form instead of JSON",
+			method: "pause",
+			request: func() *http.Request {
+				r := testAPIRequest(http.MethodPost, "/api/v1/games/"+sessionID+"/pause", "", vars, sessionID)
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return r
+			},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "another site",
+			method: "pause",
+			request: func() *http.Request {
+				r := testAPIRequest(http.MethodPost, "/api/v1/games/"+sessionID+"/pause", "{}", vars, sessionID)
+				r.Header.Set("Content-Type", "application/json")
+				r.Header.Set("Origin", "https://example.org")
+				return r
+			},
+			wantStatus: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			srv.HandleAPI(tt.method)(w, tt.request())
+			if w.Code != tt.wantStatus {
+				t.Errorf("HandleAPI() status = %d, want %d, body: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+
+	t.Run("rate limit", func(t *testing.T) {
+		srv.apiLimiters = apiRateLimiters{}
+		var status int
+		for i := 0; i <= websocketRateBurst; i++ {
+			w := httptest.NewRecorder()
+			srv.HandleAPI("getPuzzle")(w, testAPIRequest(http.MethodGet, "/api/v1/games/"+sessionID, "", vars, sessionID))
+			status = w.Code
+		}
+		if status != http.StatusTooManyRequests {
+			t.Errorf("HandleAPI() status after %d requests = %d, want %d", websocketRateBurst+1, status, http.StatusTooManyRequests)
+		}
+	})
+}
+
+func TestApiRequestBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		vars    map[string]string
+		want    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "empty", want: map[string]interface{}{}},
+		{name: "body", body: `{"state":"1"}`, want: map[string]interface{}{"state": "1"}},
+		{
+			name: "variables of the path",
+			body: `{"state":"1","sessionID":"body"}`,
+			vars: map[string]string{"sessionID": "path"},
+			want: map[string]interface{}{"state": "1", "sessionID": "path"},
+		},
+		{name: "invalid", body: `[1]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)), tt.vars)
+			got, err := apiRequestBody(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("apiRequestBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var fields map[string]interface{}
+			if err := json.Unmarshal(got, &fields); err != nil {
+				t.Fatal(err)
+			}
+			if len(fields) != len(tt.want) {
+				t.Errorf("apiRequestBody() = %s, want %v", got, tt.want)
+			}
+			for name, value := range tt.want {
+				if fields[name] != value {
+					t.Errorf("apiRequestBody() = %s, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestCreateGameAnonymousWebsocket checks that anonymous games are not created by the websocket connection.
+func TestCreateGameAnonymousWebsocket(t *testing.T) {
+	ctx := context.WithValue(testContext(testService(t)), "conn", testWebsocketConn())
+	_, wsErr := websocketRequestExecute(ctx, "createGame", []byte(`{"level":"easy"}`))
+	if wsErr == nil || wsErr.Code != websocketErrorUnauthorized {
+		t.Errorf("createGame() error = %v, want code '%s'", wsErr, websocketErrorUnauthorized)
+	}
+}
+
+// TestCreateGameTimeout checks that the generation of the puzzle is limited in time and does not block ordered requests
+// of the connection.
+func TestCreateGameTimeout(t *testing.T) {
+	if websocketPool.IsMutating("createGame") {
+		t.Errorf("IsMutating(createGame) = true, want false")
+	}
+	if _, ok := interface{}(websocketCreateGameRequest{}).(websocketTimeoutRequest); !ok {
+		t.Errorf("createGame request has no timeout")
+	}
+}
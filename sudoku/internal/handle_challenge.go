@@ -0,0 +1,194 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/cnblvr/sudoku/sudoku/templates"
+	"github.com/gomodule/redigo/redis"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// HandleChallenge starts the game on the shared puzzle. Each recipient of the challenge link gets a new session of
+// the same puzzle. The logged-in user who has already played the puzzle returns to the session.
+func (srv *Service) HandleChallenge(w http.ResponseWriter, r *http.Request) {
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	var session model.SudokuSession
+	status := func() int {
+		sudoku, status := challengeSudoku(redis, r)
+		if status != http.StatusOK {
+			return status
+		}
+		log := log.With().Int64("sudoku", sudoku.ID()).Logger()
+		// the puzzle of the day has only one attempt
+		isDaily, err := sudoku.IsDaily()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to check daily sudoku")
+			return http.StatusInternalServerError
+		}
+		if isDaily {
+			log.Warn().Msg("challenge of daily sudoku")
+			return http.StatusForbidden
+		}
+		user, err := authUser(redis, getAuth(r))
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get user")
+			return http.StatusInternalServerError
+		}
+		if !user.IsNull() {
+			if session, err = sudoku.UserSession(user); err != nil {
+				log.Error().Err(err).Msg("failed to get user's session of sudoku")
+				return http.StatusInternalServerError
+			}
+			if !session.IsNull() {
+				return http.StatusOK
+			}
+		}
+		if session, err = srv.newSudokuSession(w, r, redis, sudoku, user); err != nil {
+			log.Error().Err(err).Msg("failed to create new sudoku session")
+			return http.StatusInternalServerError
+		}
+		return http.StatusOK
+	}()
+	if status == http.StatusOK {
+		redirectPath := data.EndpointSudoku(session.ID().String())
+		log.Debug().Str("redirect", redirectPath).Msg("success HandleChallenge")
+		http.Redirect(w, r, redirectPath, http.StatusSeeOther)
+		return
+	}
+
+	// render error
+	http.Error(w, http.StatusText(status), status)
+}
+
+// HandleChallengeResults renders times of all players of the shared puzzle. With the parameter format=json the
+// results are returned in JSON.
+func (srv *Service) HandleChallengeResults(w http.ResponseWriter, r *http.Request) {
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	var d struct {
+		Token        string                 `json:"token"`
+		Level        data.SudokuLevel       `json:"level"`
+		Results      []data.ChallengeResult `json:"results"`
+		ErrorMessage string                 `json:"-"`
+	}
+
+	status := http.StatusOK
+	d.ErrorMessage = func() string {
+		sudoku, st := challengeSudoku(redis, r)
+		if st != http.StatusOK {
+			status = st
+			switch st {
+			case http.StatusBadRequest:
+				return ErrorBadRequest
+			case http.StatusNotFound:
+				return ErrorSudokuNotFound
+			default:
+				return ErrorInternalServerError
+			}
+		}
+		log := log.With().Int64("sudoku", sudoku.ID()).Logger()
+		var err error
+		if d.Token, err = sudoku.ChallengeToken(); err != nil {
+			log.Error().Err(err).Msg("failed to get challenge token of sudoku")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		if d.Level, err = sudoku.Level(); err != nil {
+			log.Error().Err(err).Msg("failed to get level of sudoku")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		if d.Results, err = challengeResults(sudoku); err != nil {
+			log.Error().Err(err).Msg("failed to get results of challenge")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		return ""
+	}()
+
+	if r.URL.Query().Get("format") == "json" {
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(d); err != nil {
+			log.Error().Err(err).Msg("failed to encode challenge results")
+		}
+		return
+	}
+
+	args := templates.Args{
+		Header: templates.Header{
+			Title: "challenge",
+		},
+		Auth: getAuth(r),
+		Data: d,
+	}
+	srv.executeTemplate(w, "page_challenge", args)
+}
+
+// challengeSudoku returns the puzzle of the challenge by the token from the path of the request.
+func challengeSudoku(redis redis.Conn, r *http.Request) (model.Sudoku, int) {
+	token, ok := mux.Vars(r)["token"]
+	if !ok {
+		log.Error().Msg("'token' not found in mux.Vars")
+		return model.Sudoku{}, http.StatusBadRequest
+	}
+	sudoku, isExists, err := model.SudokuByChallengeToken(redis, token)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get sudoku by challenge token")
+		return model.Sudoku{}, http.StatusInternalServerError
+	}
+	if !isExists {
+		log.Warn().Msg("challenge token not found")
+		return model.Sudoku{}, http.StatusNotFound
+	}
+	return sudoku, http.StatusOK
+}
+
+// challengeResults collects the games of all players of the puzzle. Sessions are revealed only for finished games.
+func challengeResults(sudoku model.Sudoku) ([]data.ChallengeResult, error) {
+	sessions, err := sudoku.Sessions()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]data.ChallengeResult, 0, len(sessions))
+	for _, session := range sessions {
+		item, err := session.HistoryItem()
+		if err != nil {
+			return nil, err
+		}
+		stats, err := session.Stats()
+		if err != nil {
+			return nil, err
+		}
+		result := data.ChallengeResult{
+			Status:    item.Status,
+			SolveTime: item.SolveTime,
+			Mistakes:  stats.Mistakes,
+			Score:     item.Score,
+		}
+		if result.IsSolved() {
+			result.SessionID = item.SessionID
+		}
+		user, err := session.User()
+		if err != nil {
+			return nil, err
+		}
+		if !user.IsNull() {
+			if result.Username, err = user.Username(); err != nil {
+				return nil, err
+			}
+		}
+		results = append(results, result)
+	}
+	data.RankChallengeResults(results)
+	return results, nil
+}
@@ -0,0 +1,125 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/cnblvr/sudoku/sudoku/templates"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// leaderboardPageSize is the number of users on one page of the leaderboard.
+const leaderboardPageSize = 20
+
+// HandleLeaderboard renders the leaderboard page of the difficulty and the period. With the parameter format=json
+// the leaderboard is returned in JSON.
+func (srv *Service) HandleLeaderboard(w http.ResponseWriter, r *http.Request) {
+	auth := getAuth(r)
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	var d struct {
+		Level   data.SudokuLevel         `json:"level"`
+		Period  data.LeaderboardPeriod   `json:"period"`
+		Page    int                      `json:"page"`
+		Pages   int                      `json:"pages"`
+		Items   []data.LeaderboardItem   `json:"items"`
+		MyRank  *data.LeaderboardItem    `json:"myRank,omitempty"`
+		Levels  []data.SudokuLevel       `json:"-"`
+		Periods []data.LeaderboardPeriod `json:"-"`
+		// Numbers of the previous and the next pages. Zero if the page does not exist.
+		PrevPage     int    `json:"-"`
+		NextPage     int    `json:"-"`
+		ErrorMessage string `json:"-"`
+	}
+	d.Levels, d.Periods = data.SudokuLevels, data.LeaderboardPeriods
+
+	status := http.StatusOK
+	d.ErrorMessage = func() string {
+		query := r.URL.Query()
+		var err error
+		if d.Level, err = data.ParseSudokuLevel(query.Get("level")); err != nil {
+			log.Warn().Err(err).Msg("failed to parse level")
+			status = http.StatusBadRequest
+			return ErrorBadRequest
+		}
+		if d.Period, err = data.ParseLeaderboardPeriod(query.Get("period")); err != nil {
+			log.Warn().Err(err).Msg("failed to parse period")
+			status = http.StatusBadRequest
+			return ErrorBadRequest
+		}
+		d.Page = 1
+		if pageStr := query.Get("page"); pageStr != "" {
+			if d.Page, err = strconv.Atoi(pageStr); err != nil || d.Page < 1 {
+				log.Warn().Err(err).Str("page", pageStr).Msg("failed to parse page")
+				status = http.StatusBadRequest
+				return ErrorBadRequest
+			}
+		}
+
+		leaderboard := model.LeaderboardByPeriod(redis, d.Period, d.Level, time.Now())
+		count, err := leaderboard.Count()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to count leaderboard")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		d.Pages = (count + leaderboardPageSize - 1) / leaderboardPageSize
+		if d.Items, err = leaderboard.Page((d.Page-1)*leaderboardPageSize, leaderboardPageSize); err != nil {
+			log.Error().Err(err).Msg("failed to get leaderboard")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		if d.Page > 1 {
+			d.PrevPage = d.Page - 1
+		}
+		if d.Page < d.Pages {
+			d.NextPage = d.Page + 1
+		}
+
+		if auth.IsAuthorized {
+			user, isExists, err := model.UserByID(redis, auth.ID)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to get user")
+				status = http.StatusInternalServerError
+				return ErrorInternalServerError
+			}
+			if isExists {
+				myRank, isRanked, err := leaderboard.Rank(user)
+				if err != nil {
+					log.Error().Err(err).Msg("failed to get user's rank")
+					status = http.StatusInternalServerError
+					return ErrorInternalServerError
+				}
+				if isRanked {
+					d.MyRank = &myRank
+				}
+			}
+		}
+		return ""
+	}()
+
+	if r.URL.Query().Get("format") == "json" {
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(d); err != nil {
+			log.Error().Err(err).Msg("failed to encode leaderboard")
+		}
+		return
+	}
+
+	args := templates.Args{
+		Header: templates.Header{
+			Title: "leaderboard",
+		},
+		Auth: auth,
+		Data: d,
+	}
+	srv.executeTemplate(w, "page_leaderboard", args)
+}
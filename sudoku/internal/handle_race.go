@@ -0,0 +1,121 @@
+package sudoku
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/cnblvr/sudoku/sudoku/static"
+	"github.com/cnblvr/sudoku/sudoku/templates"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+const (
+	ErrorRaceNotFound = "Race not found."
+)
+
+// HandleRaceCreate creates a race on a new puzzle and redirects to the race page. The link to the page can be sent
+// to other players. The puzzle is taken from the bank of pre-generated puzzles, see createGameSudoku.
+func (srv *Service) HandleRaceCreate(w http.ResponseWriter, r *http.Request) {
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	var race model.Race
+	status := func() int {
+		level, err := data.ParseSudokuLevel(r.URL.Query().Get("level"))
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to parse level")
+			return http.StatusBadRequest
+		}
+		user, err := authUser(redis, getAuth(r))
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get user")
+			return http.StatusInternalServerError
+		}
+		mSudoku, err := srv.createGameSudoku(redis, level, user)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create new sudoku")
+			return http.StatusInternalServerError
+		}
+		if race, err = model.NewRace(redis, mSudoku); err != nil {
+			log.Error().Err(err).Msg("failed to create new race")
+			return http.StatusInternalServerError
+		}
+		return http.StatusOK
+	}()
+	if status == http.StatusOK {
+		redirectPath := data.EndpointRace(race.ID().String())
+		log.Debug().Str("redirect", redirectPath).Msg("success HandleRaceCreate")
+		http.Redirect(w, r, redirectPath, http.StatusSeeOther)
+		return
+	}
+
+	// render error
+	http.Error(w, http.StatusText(status), status)
+}
+
+// HandleRace renders page with puzzle of the race. The session of an anonymous player is created with the page and
+// saved in the cookie, like other anonymous sessions. The session of a logged-in user is created on joining the race
+// via websocket.
+func (srv *Service) HandleRace(w http.ResponseWriter, r *http.Request) {
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	var d sudokuPageData
+
+	d.ErrorMessage = func() string {
+		raceID, ok := mux.Vars(r)["race_id"]
+		if !ok {
+			log.Error().Msg("'race_id' not found in mux.Vars")
+			return ErrorBadRequest
+		}
+		race, err := model.RaceByIDString(redis, raceID)
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to get race '%s'", raceID)
+			return ErrorInternalServerError
+		}
+		if race.IsNull() {
+			log.Warn().Msgf("race '%s' not found", raceID)
+			return ErrorRaceNotFound
+		}
+		d.Race = race.ID().String()
+		if getAuth(r).IsAuthorized {
+			return ""
+		}
+		anonymousSessions := getAnonymousSessions(r)
+		for _, sessionID := range anonymousSessions {
+			isMember, err := race.HasSession(sessionID)
+			if err != nil {
+				log.Error().Err(err).Msgf("failed to check session of race '%s'", raceID)
+				return ErrorInternalServerError
+			}
+			if isMember {
+				d.Session = sessionID
+				return ""
+			}
+		}
+		session, err := race.Join(model.User{}, "")
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to join race '%s'", raceID)
+			return ErrorInternalServerError
+		}
+		d.Session = session.ID().String()
+		if err := srv.createSessionsCookie(w, append(anonymousSessions, d.Session)); err != nil {
+			log.Error().Err(err).Msg("failed to create 'sessions' cookie")
+		}
+		return ""
+	}()
+
+	args := templates.Args{
+		Header: templates.Header{
+			Title: "sudoku race",
+			Css:   []string{static.CssSudoku},
+		},
+		Auth: getAuth(r),
+		Data: d,
+		Footer: templates.Footer{
+			Js: []string{static.JsSudoku},
+		},
+	}
+	srv.executeTemplate(w, "page_sudoku", args)
+}
@@ -1,10 +1,13 @@
 package sudoku
 
 import (
+	"context"
 	"fmt"
+	"github.com/cnblvr/sudoku/data"
 	"github.com/cnblvr/sudoku/model"
 	"github.com/cnblvr/sudoku/sudoku/static"
 	"github.com/cnblvr/sudoku/sudoku/templates"
+	"github.com/gomodule/redigo/redis"
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"net/http"
@@ -14,14 +17,43 @@ const (
 	ErrorSudokuNotFound = "Sudoku not found."
 )
 
+// sudokuPageData is the data of the 'page_sudoku' template.
+type sudokuPageData struct {
+	Session string
+	// Challenge is the token of the challenge link to share the puzzle with friends.
+	Challenge string
+	// Seed is the link to a new game of the puzzle generated by the same seed.
+	Seed string
+	Race string
+	Coop bool
+	// Watch is true for spectators of the session.
+	Watch bool
+	// WatchToken is the token of the link for spectators. It is shown to players of the session and passed by
+	// spectators to the websocket API.
+	WatchToken string
+	// CoopToken is the token of the invitation link of the co-op game. It is shown to players of the session and
+	// passed by them to the websocket API.
+	CoopToken    string
+	ErrorMessage string
+}
+
 // HandleSudoku renders page with puzzle.
 func (srv *Service) HandleSudoku(w http.ResponseWriter, r *http.Request) {
+	srv.handleSudokuPage(w, r, false)
+}
+
+// HandleSudokuWatch renders page with puzzle of the session for spectators. The page receives moves of the players
+// and can not change the session.
+func (srv *Service) HandleSudokuWatch(w http.ResponseWriter, r *http.Request) {
+	srv.handleSudokuPage(w, r, true)
+}
+
+func (srv *Service) handleSudokuPage(w http.ResponseWriter, r *http.Request, watch bool) {
 	redis := srv.redis.Get()
 	defer redis.Close()
 
-	var d struct {
-		Session      string
-		ErrorMessage string
+	d := sudokuPageData{
+		Watch: watch,
 	}
 
 	d.ErrorMessage = func() string {
@@ -37,6 +69,49 @@ func (srv *Service) HandleSudoku(w http.ResponseWriter, r *http.Request) {
 			return ErrorSudokuNotFound
 		}
 		d.Session = sudokuSession.ID().String()
+		isDaily, err := sudokuSession.Sudoku().IsDaily()
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to check daily sudoku of session '%s'", sessionID)
+			return ErrorInternalServerError
+		}
+		if !isDaily {
+			if d.Challenge, err = sudokuSession.Sudoku().ChallengeToken(); err != nil {
+				log.Error().Err(err).Msgf("failed to get challenge token of sudoku session '%s'", sessionID)
+				return ErrorInternalServerError
+			}
+			seed, version, isSeed, err := sudokuSession.Sudoku().Seed()
+			if err != nil {
+				log.Error().Err(err).Msgf("failed to get seed of sudoku session '%s'", sessionID)
+				return ErrorInternalServerError
+			}
+			if isSeed {
+				level, err := sudokuSession.Sudoku().Level()
+				if err != nil {
+					log.Error().Err(err).Msgf("failed to get level of sudoku session '%s'", sessionID)
+					return ErrorInternalServerError
+				}
+				d.Seed = data.EndpointSudokuSeed(seed, level, version)
+			}
+		}
+		race, err := sudokuSession.Race()
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to get race of sudoku session '%s'", sessionID)
+			return ErrorInternalServerError
+		}
+		if !race.IsNull() {
+			d.Race = race.ID().String()
+		}
+		if d.Coop, err = sudokuSession.IsCoop(); err != nil {
+			log.Error().Err(err).Msgf("failed to get mode of sudoku session '%s'", sessionID)
+			return ErrorInternalServerError
+		}
+
+		if watch {
+			d.WatchToken = r.URL.Query().Get("token")
+		} else if d.WatchToken, d.CoopToken, err = sudokuTokens(redis, r, sudokuSession, d.Coop); err != nil {
+			log.Error().Err(err).Msgf("failed to get tokens of sudoku session '%s'", sessionID)
+			return ErrorInternalServerError
+		}
 
 		// TODO sudokuSession.Sudoku().AddUserID()
 		_ = sudokuSession
@@ -56,3 +131,35 @@ func (srv *Service) HandleSudoku(w http.ResponseWriter, r *http.Request) {
 	}
 	srv.executeTemplate(w, "page_sudoku", args)
 }
+
+// sudokuTokens returns tokens of links for spectators and of the invitation link of the co-op game if the client is a
+// player of the session. Players of the co-op game open the page by the invitation link with the parameter 'coop'.
+func sudokuTokens(redis redis.Conn, r *http.Request, session model.SudokuSession, isCoop bool) (watchToken, coopToken string, err error) {
+	user, err := authUser(redis, getAuth(r))
+	if err != nil {
+		return "", "", err
+	}
+	ctx := context.WithValue(r.Context(), "user", &user)
+	ctx = context.WithValue(ctx, "anonymousSessions", newAnonymousSessions(getAnonymousSessions(r)))
+	if isCoop {
+		err = authorizeCoopSudokuSession(ctx, session, r.URL.Query().Get("coop"))
+	} else {
+		err = authorizeSudokuSession(ctx, session)
+	}
+	switch err {
+	case nil:
+	case errWebsocketInternal:
+		return "", "", err
+	default:
+		return "", "", nil
+	}
+	if isCoop {
+		if coopToken, err = session.CoopToken(); err != nil {
+			return "", "", err
+		}
+	}
+	if watchToken, err = session.WatchToken(); err != nil {
+		return "", "", err
+	}
+	return watchToken, coopToken, nil
+}
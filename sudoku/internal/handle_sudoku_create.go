@@ -4,34 +4,76 @@ import (
 	"github.com/cnblvr/sudoku/data"
 	"github.com/cnblvr/sudoku/model"
 	"github.com/cnblvr/sudoku/sudoku/internal/sudoku_classic"
+	"github.com/gomodule/redigo/redis"
 	"github.com/rs/zerolog/log"
+	"math/rand"
 	"net/http"
+	"sync"
+	"time"
 )
 
+var (
+	seedRndMx sync.Mutex
+	seedRnd   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+// maxSeed bounds seeds of puzzles. The generator seeds rand.NewSource that reduces the seed modulo 2^31-1, so greater
+// seeds would repeat puzzles of smaller ones.
+const maxSeed = 1<<31 - 1
+
+// randomSeed returns a random seed of a new puzzle in [0, maxSeed).
+func randomSeed() int64 {
+	seedRndMx.Lock()
+	defer seedRndMx.Unlock()
+	return seedRnd.Int63n(maxSeed)
+}
+
+// sudokuModeCoop is the mode of the cooperative game.
+const sudokuModeCoop = "coop"
+
 // HandleSudokuCreate is a puzzle generator handler/page(TODO).
+// The puzzle is taken from the bank of pre-generated puzzles, see createGameSudoku.
+// With the parameter mode=coop the session is a cooperative game: several players solve the puzzle together.
+// The session is attached to the logged-in user. Sessions of anonymous users are remembered in the browser to be
+// claimed on signup or login.
 func (srv *Service) HandleSudokuCreate(w http.ResponseWriter, r *http.Request) {
-	//a := getAuth(r)
+	auth := getAuth(r)
 	redis := srv.redis.Get()
 	defer redis.Close()
-	const seed = int64(2) // todo seed
-	log := log.With().Int64("seed", seed).Logger()
 
 	var sudokuSession model.SudokuSession
 	status := func() int {
-		sudoku := sudoku_classic.NewSudoku(seed)
-		mSudoku, err := model.NewSudoku(redis,
-			sudoku.Board().String(),
-			sudoku.Puzzle().String(),
-		)
+		level, err := data.ParseSudokuLevel(r.URL.Query().Get("level"))
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to parse level")
+			return http.StatusBadRequest
+		}
+		mode := r.URL.Query().Get("mode")
+		if mode != "" && mode != sudokuModeCoop {
+			log.Warn().Str("mode", mode).Msg("unknown mode")
+			return http.StatusBadRequest
+		}
+		user, err := authUser(redis, auth)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get user")
+			return http.StatusInternalServerError
+		}
+		mSudoku, err := srv.createGameSudoku(redis, level, user)
 		if err != nil {
 			log.Error().Err(err).Msg("failed to create new sudoku")
 			return http.StatusInternalServerError
 		}
-		sudokuSession, err = model.NewSudokuSession(redis, mSudoku, model.User{})
+		sudokuSession, err = srv.newSudokuSession(w, r, redis, mSudoku, user)
 		if err != nil {
 			log.Error().Err(err).Msg("failed to create new sudoku session")
 			return http.StatusInternalServerError
 		}
+		if mode == sudokuModeCoop {
+			if err := sudokuSession.SetCoop(); err != nil {
+				log.Error().Err(err).Msg("failed to set co-op mode of sudoku session")
+				return http.StatusInternalServerError
+			}
+		}
 		return http.StatusOK
 	}()
 	if status == http.StatusOK {
@@ -44,3 +86,78 @@ func (srv *Service) HandleSudokuCreate(w http.ResponseWriter, r *http.Request) {
 	// render error
 	http.Error(w, http.StatusText(status), status)
 }
+
+// createGameSudoku returns the puzzle of a new game from the bank. Puzzles played by the user are not repeated. The
+// puzzle is generated by a random seed if the bank has no puzzles for the user.
+func (srv *Service) createGameSudoku(redis redis.Conn, level data.SudokuLevel, user model.User) (model.Sudoku, error) {
+	sudoku, ok, err := srv.puzzleBank.pop(redis, data.SudokuVariantClassic, level, user)
+	if err != nil {
+		return model.Sudoku{}, err
+	}
+	if ok {
+		return sudoku, nil
+	}
+	log.Debug().Str("level", string(level)).Int64("user", user.ID()).Msg("puzzle bank is exhausted")
+	return createSudoku(redis, randomSeed(), level)
+}
+
+// createSudoku generates the puzzle by the seed with the current version of the generator, see createSudokuVersion.
+func createSudoku(redis redis.Conn, seed int64, level data.SudokuLevel) (model.Sudoku, error) {
+	return createSudokuVersion(redis, sudoku_classic.GeneratorVersion, seed, level)
+}
+
+// createSudokuVersion generates the puzzle by the seed with the version of the generator and saves it with its
+// canonical form, the seed and the version.
+func createSudokuVersion(redis redis.Conn, version int, seed int64, level data.SudokuLevel) (model.Sudoku, error) {
+	sudoku, err := sudoku_classic.NewSudokuVersion(version, seed, level)
+	if err != nil {
+		return model.Sudoku{}, err
+	}
+	mSudoku, err := model.NewSudoku(redis,
+		sudoku.Board().String(),
+		sudoku.Puzzle().String(),
+		level,
+	)
+	if err != nil {
+		return model.Sudoku{}, err
+	}
+	if err := mSudoku.SetCanonical(sudoku_classic.Canonical(sudoku.Puzzle())); err != nil {
+		return model.Sudoku{}, err
+	}
+	if err := mSudoku.SetSeed(seed, version); err != nil {
+		return model.Sudoku{}, err
+	}
+	return mSudoku, nil
+}
+
+// authUser returns the logged-in user. The null user if the request is anonymous or the user is not found.
+func authUser(redis redis.Conn, auth *data.Auth) (model.User, error) {
+	if !auth.IsAuthorized {
+		return model.User{}, nil
+	}
+	user, isExists, err := model.UserByID(redis, auth.ID)
+	if err != nil {
+		return model.User{}, err
+	}
+	if !isExists {
+		log.Warn().Int64("id", auth.ID).Msg("user not found")
+		return model.User{}, nil
+	}
+	return user, nil
+}
+
+// newSudokuSession creates the session of the puzzle for the user. Sessions of anonymous users are remembered in the
+// browser to be claimed on signup or login.
+func (srv *Service) newSudokuSession(w http.ResponseWriter, r *http.Request, redis redis.Conn, sudoku model.Sudoku, user model.User) (model.SudokuSession, error) {
+	session, err := model.NewSudokuSession(redis, sudoku, user)
+	if err != nil {
+		return model.SudokuSession{}, err
+	}
+	if user.IsNull() {
+		sessionIDs := append(getAnonymousSessions(r), session.ID().String())
+		if err := srv.createSessionsCookie(w, sessionIDs); err != nil {
+			log.Error().Err(err).Msg("failed to create 'sessions' cookie")
+		}
+	}
+	return session, nil
+}
@@ -0,0 +1,118 @@
+package sudoku
+
+import (
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/cnblvr/sudoku/sudoku/templates"
+	"github.com/rs/zerolog/log"
+	"hash/fnv"
+	"net/http"
+	"time"
+)
+
+// dailyLeaderboardLimit is the number of the best results shown in the daily leaderboard.
+const dailyLeaderboardLimit = 100
+
+// HandleSudokuDaily redirects the user to the attempt to solve the puzzle of the day. The puzzle is generated on the
+// first request of the day. Each user has only one attempt per day.
+func (srv *Service) HandleSudokuDaily(w http.ResponseWriter, r *http.Request) {
+	redis := srv.redis.Get()
+	defer redis.Close()
+	user := getUser(r)
+
+	var session model.SudokuSession
+	status := func() int {
+		level, err := data.ParseSudokuLevel(r.URL.Query().Get("level"))
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to parse level")
+			return http.StatusBadRequest
+		}
+		daily := model.DailySudokuByDate(redis, time.Now(), level)
+		log := log.With().Str("date", daily.Date()).Str("level", string(level)).Logger()
+		_, isExists, err := daily.Sudoku()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get daily sudoku")
+			return http.StatusInternalServerError
+		}
+		if !isExists {
+			sudoku, err := createSudoku(redis, dailySeed(daily), level)
+			if err != nil {
+				log.Error().Err(err).Msg("failed to create daily sudoku")
+				return http.StatusInternalServerError
+			}
+			if _, err := daily.SetSudoku(sudoku); err != nil {
+				log.Error().Err(err).Msg("failed to set daily sudoku")
+				return http.StatusInternalServerError
+			}
+		}
+		session, err = daily.NewSession(*user)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create daily sudoku session")
+			return http.StatusInternalServerError
+		}
+		return http.StatusOK
+	}()
+	if status == http.StatusOK {
+		redirectPath := data.EndpointSudoku(session.ID().String())
+		log.Debug().Str("redirect", redirectPath).Msg("success HandleSudokuDaily")
+		http.Redirect(w, r, redirectPath, http.StatusSeeOther)
+		return
+	}
+
+	// render error
+	http.Error(w, http.StatusText(status), status)
+}
+
+// HandleSudokuDailyLeaderboard renders the leaderboard of the puzzle of the day.
+func (srv *Service) HandleSudokuDailyLeaderboard(w http.ResponseWriter, r *http.Request) {
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	var d struct {
+		Date         string
+		Level        data.SudokuLevel
+		Levels       []data.SudokuLevel
+		Leaderboard  []data.DailyLeaderboardItem
+		ErrorMessage string
+	}
+	d.Levels = data.SudokuLevels
+
+	d.ErrorMessage = func() string {
+		level, err := data.ParseSudokuLevel(r.URL.Query().Get("level"))
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to parse level")
+			return ErrorBadRequest
+		}
+		date := time.Now()
+		if dateStr := r.URL.Query().Get("date"); dateStr != "" {
+			if date, err = time.Parse("2006-01-02", dateStr); err != nil {
+				log.Warn().Err(err).Msg("failed to parse date")
+				return ErrorBadRequest
+			}
+		}
+		daily := model.DailySudokuByDate(redis, date, level)
+		d.Date, d.Level = daily.Date(), daily.Level()
+		if d.Leaderboard, err = daily.Leaderboard(0, dailyLeaderboardLimit); err != nil {
+			log.Error().Err(err).Msg("failed to get daily leaderboard")
+			return ErrorInternalServerError
+		}
+		return ""
+	}()
+
+	args := templates.Args{
+		Header: templates.Header{
+			Title: "daily leaderboard",
+		},
+		Auth: getAuth(r),
+		Data: d,
+	}
+	srv.executeTemplate(w, "page_daily_leaderboard", args)
+}
+
+// dailySeed returns the seed of the puzzle of the day in [0, maxSeed). The seed depends only on the date and the level.
+func dailySeed(daily model.DailySudoku) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(fmt.Sprintf("daily:%s:%s", daily.Date(), daily.Level())))
+	return int64(h.Sum64() % maxSeed)
+}
@@ -0,0 +1,113 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/cnblvr/sudoku/sudoku/static"
+	"github.com/cnblvr/sudoku/sudoku/templates"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+const (
+	ErrorSudokuNotFinished = "The game is not finished yet."
+)
+
+// HandleSudokuReplay renders the replay page of the finished game. With the parameter format=json the recorded moves
+// are returned in JSON. Games in progress can only be watched, so that the replay does not reveal the solution of the
+// puzzle before the end of the game.
+func (srv *Service) HandleSudokuReplay(w http.ResponseWriter, r *http.Request) {
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	var d struct {
+		data.SudokuReplay
+		ErrorMessage string `json:"-"`
+	}
+
+	status := http.StatusOK
+	d.ErrorMessage = func() string {
+		sessionID, ok := mux.Vars(r)["session_id"]
+		if !ok {
+			log.Error().Msg("'session_id' not found in mux.Vars")
+			status = http.StatusBadRequest
+			return ErrorBadRequest
+		}
+		log := log.With().Str("session", sessionID).Logger()
+		session, err := model.SudokuSessionByIDString(redis, sessionID)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get sudoku session")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		if session.IsNull() {
+			log.Warn().Msg("sudoku session not found")
+			status = http.StatusNotFound
+			return ErrorSudokuNotFound
+		}
+		d.SessionID = session.ID().String()
+		if d.Status, err = session.Status(); err != nil {
+			log.Error().Err(err).Msg("failed to get status of sudoku session")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		if d.Status != data.SudokuSessionSolved {
+			status = http.StatusForbidden
+			return ErrorSudokuNotFinished
+		}
+		if d.Puzzle, err = session.Sudoku().Puzzle(); err != nil {
+			log.Error().Err(err).Msg("failed to get puzzle")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		timer, err := session.Timer()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get timer of sudoku session")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		d.StartedAt = timer.StartedAt
+		solveTime, err := session.SolveTime()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get solve time of sudoku session")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		d.SolveTime = data.Duration(solveTime)
+		moves, err := session.Moves(1)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get moves of sudoku session")
+			status = http.StatusInternalServerError
+			return ErrorInternalServerError
+		}
+		d.Moves = data.NewSudokuReplayMoves(timer.StartedAt, moves)
+		return ""
+	}()
+
+	if r.URL.Query().Get("format") == "json" {
+		if status != http.StatusOK {
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(d.SudokuReplay); err != nil {
+			log.Error().Err(err).Msg("failed to encode replay")
+		}
+		return
+	}
+
+	args := templates.Args{
+		Header: templates.Header{
+			Title: "replay",
+			Css:   []string{static.CssSudoku},
+		},
+		Auth: getAuth(r),
+		Data: d,
+		Footer: templates.Footer{
+			Js: []string{static.JsReplay},
+		},
+	}
+	srv.executeTemplate(w, "page_replay", args)
+}
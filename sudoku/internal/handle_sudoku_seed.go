@@ -0,0 +1,81 @@
+package sudoku
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/cnblvr/sudoku/sudoku/internal/sudoku_classic"
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"net/http"
+	"strconv"
+)
+
+// HandleSudokuSeed creates a game of the puzzle generated by the seed from the path and the parameters level and
+// version. The same seed, level and version of the generator give the same puzzle, so the link reproduces the puzzle of
+// any game. Links without the version were created by the first version. Seeds are below maxSeed. The saved puzzle of
+// the seed is reused, otherwise it is generated.
+func (srv *Service) HandleSudokuSeed(w http.ResponseWriter, r *http.Request) {
+	auth := getAuth(r)
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	var sudokuSession model.SudokuSession
+	status := func() int {
+		seed, err := strconv.ParseInt(mux.Vars(r)["seed"], 10, 64)
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to parse seed")
+			return http.StatusBadRequest
+		}
+		if seed < 0 || seed >= maxSeed {
+			log.Warn().Int64("seed", seed).Msg("seed is out of range")
+			return http.StatusBadRequest
+		}
+		level, err := data.ParseSudokuLevel(r.URL.Query().Get("level"))
+		if err != nil {
+			log.Warn().Err(err).Msg("failed to parse level")
+			return http.StatusBadRequest
+		}
+		version := 1
+		if v := r.URL.Query().Get("version"); v != "" {
+			if version, err = strconv.Atoi(v); err != nil {
+				log.Warn().Err(err).Msg("failed to parse version")
+				return http.StatusBadRequest
+			}
+		}
+		if !sudoku_classic.HasGeneratorVersion(version) {
+			log.Warn().Int("version", version).Msg("unknown generator version")
+			return http.StatusBadRequest
+		}
+		user, err := authUser(redis, auth)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to get user")
+			return http.StatusInternalServerError
+		}
+		mSudoku, isExists, err := model.SudokuBySeed(redis, seed, level, version)
+		if err != nil {
+			log.Error().Err(err).Int64("seed", seed).Msg("failed to get sudoku by seed")
+			return http.StatusInternalServerError
+		}
+		if !isExists {
+			if mSudoku, err = createSudokuVersion(redis, version, seed, level); err != nil {
+				log.Error().Err(err).Int64("seed", seed).Msg("failed to create new sudoku")
+				return http.StatusInternalServerError
+			}
+		}
+		sudokuSession, err = srv.newSudokuSession(w, r, redis, mSudoku, user)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create new sudoku session")
+			return http.StatusInternalServerError
+		}
+		return http.StatusOK
+	}()
+	if status == http.StatusOK {
+		redirectPath := data.EndpointSudoku(sudokuSession.ID().String())
+		log.Debug().Str("redirect", redirectPath).Msg("success HandleSudokuSeed")
+		http.Redirect(w, r, redirectPath, http.StatusSeeOther)
+		return
+	}
+
+	// render error
+	http.Error(w, http.StatusText(status), status)
+}
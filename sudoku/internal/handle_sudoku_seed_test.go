@@ -0,0 +1,94 @@
+package sudoku
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/cnblvr/sudoku/sudoku/internal/sudoku_classic"
+	"github.com/gorilla/securecookie"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRandomSeed(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		if seed := randomSeed(); seed < 0 || seed >= maxSeed {
+			t.Fatalf("randomSeed() = %d, want [0, %d)", seed, maxSeed)
+		}
+	}
+}
+
+func TestDailySeed(t *testing.T) {
+	date := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 1000; i++ {
+		for _, level := range []data.SudokuLevel{data.SudokuLevelEasy, data.SudokuLevelMedium, data.SudokuLevelHard} {
+			daily := model.DailySudokuByDate(nil, date.AddDate(0, 0, i), level)
+			if seed := dailySeed(daily); seed < 0 || seed >= maxSeed {
+				t.Fatalf("dailySeed(%s, %s) = %d, want [0, %d)", daily.Date(), level, seed, maxSeed)
+			}
+		}
+	}
+}
+
+func TestHandleSudokuSeed(t *testing.T) {
+	srv := testService(t)
+	srv.securecookie = securecookie.New(securecookie.GenerateRandomKey(32), nil)
+	conn := srv.redis.Get()
+	defer conn.Close()
+
+	// handle returns the status of the request and the puzzle of the created session
+	handle := func(t *testing.T, seed, query string) (int, model.Sudoku) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		srv.HandleSudokuSeed(w, testAPIRequest(http.MethodGet, "/sudoku/seed/"+seed+query, "", map[string]string{"seed": seed}))
+		if w.Code != http.StatusSeeOther {
+			return w.Code, model.Sudoku{}
+		}
+		session, err := model.SudokuSessionByIDString(conn, path.Base(w.Header().Get("Location")))
+		if err != nil || session.IsNull() {
+			t.Fatalf("session of %s not found: %v", w.Header().Get("Location"), err)
+		}
+		return w.Code, session.Sudoku()
+	}
+
+	tests := []struct {
+		name        string
+		seed        string
+		query       string
+		wantStatus  int
+		wantVersion int
+	}{
+		{name: "without version", seed: "42", query: "?level=easy", wantStatus: http.StatusSeeOther, wantVersion: 1},
+		{name: "current version", seed: "7", query: "?level=easy&version=" + strconv.Itoa(sudoku_classic.GeneratorVersion),
+			wantStatus: http.StatusSeeOther, wantVersion: sudoku_classic.GeneratorVersion},
+		{name: "invalid seed", seed: "x", query: "?level=easy", wantStatus: http.StatusBadRequest},
+		{name: "maximum seed", seed: strconv.Itoa(maxSeed - 1), query: "?level=easy", wantStatus: http.StatusSeeOther, wantVersion: 1},
+		{name: "too big seed", seed: strconv.Itoa(maxSeed), query: "?level=easy", wantStatus: http.StatusBadRequest},
+		{name: "negative seed", seed: "-1", query: "?level=easy", wantStatus: http.StatusBadRequest},
+		{name: "invalid level", seed: "42", query: "?level=x", wantStatus: http.StatusBadRequest},
+		{name: "invalid version", seed: "42", query: "?level=easy&version=x", wantStatus: http.StatusBadRequest},
+		{name: "unknown version", seed: "42", query: "?level=easy&version=0", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, sudoku := handle(t, tt.seed, tt.query)
+			if status != tt.wantStatus {
+				t.Fatalf("HandleSudokuSeed() status = %d, want %d", status, tt.wantStatus)
+			}
+			if status != http.StatusSeeOther {
+				return
+			}
+			seed, version, isSeed, err := sudoku.Seed()
+			if err != nil || !isSeed || strconv.FormatInt(seed, 10) != tt.seed || version != tt.wantVersion {
+				t.Errorf("Seed() = %d, %d, %v, %v, want %s, %d", seed, version, isSeed, err, tt.seed, tt.wantVersion)
+			}
+			// the saved puzzle of the seed is reused
+			if _, again := handle(t, tt.seed, tt.query); again.ID() != sudoku.ID() {
+				t.Errorf("repeated HandleSudokuSeed() sudoku = %d, want %d", again.ID(), sudoku.ID())
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/rs/zerolog/log"
+	"net/http"
+)
+
+// HandleUserHistory returns the user's games history from the oldest and statistics in JSON.
+func (srv *Service) HandleUserHistory(w http.ResponseWriter, r *http.Request) {
+	user := getUser(r)
+
+	history, err := user.History()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get user history")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	resp := struct {
+		History []data.SudokuHistoryItem `json:"history"`
+		Stats   data.UserStats           `json:"stats"`
+	}{
+		History: history,
+		Stats:   data.NewUserStats(history),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error().Err(err).Msg("failed to encode user history")
+	}
+}
@@ -8,6 +8,10 @@ import (
 	"net/http"
 )
 
+// userInfoHistoryLimit is the number of the last games shown on the user's info page.
+const userInfoHistoryLimit = 20
+
+// HandleUserInfo renders the user's info page with the games history and changes the user's info.
 func (srv *Service) HandleUserInfo(w http.ResponseWriter, r *http.Request) {
 	redirect := func(endpoint string) {
 		http.Redirect(w, r, endpoint, http.StatusSeeOther)
@@ -16,6 +20,9 @@ func (srv *Service) HandleUserInfo(w http.ResponseWriter, r *http.Request) {
 
 	var d struct {
 		ErrorMessage string
+		// The last games from the newest.
+		History []data.SudokuHistoryItem
+		Stats   data.UserStats
 	}
 
 	info, err := user.UserInfo()
@@ -24,6 +31,16 @@ func (srv *Service) HandleUserInfo(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	history, err := user.History()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get user history")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	d.Stats = data.NewUserStats(history)
+	for i := len(history) - 1; i >= 0 && len(d.History) < userInfoHistoryLimit; i-- {
+		d.History = append(d.History, history[i])
+	}
 
 	// POST method processes data from the user
 	if r.Method == http.MethodPost {
@@ -1,39 +1,101 @@
 package sudoku
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/gomodule/redigo/redis"
 	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog/log"
 	"net/http"
+	"time"
 )
 
+// websocketWorkers is the number of requests or batches of one connection that are executed at the same time.
+// Responses of read-only requests can come in a different order than requests, clients match them by echo.
+const websocketWorkers = 4
+
 type websocketMessage struct {
-	Method string          `json:"method"`
+	Method string          `json:"method,omitempty"`
+	Event  string          `json:"event,omitempty"`
 	Echo   string          `json:"echo,omitempty"`
-	Error  string          `json:"error,omitempty"`
-	Body   json.RawMessage `json:"body,omitempty"`
+	Error  *websocketError `json:"error,omitempty"`
+	// Seq is the sequence number of the event of the connection. It is used for resuming the connection.
+	Seq  int64           `json:"seq,omitempty"`
+	Body json.RawMessage `json:"body,omitempty"`
 }
 
 func (srv *Service) HandleWebsocket(w http.ResponseWriter, r *http.Request) {
-	auth := getAuth(r)
-	log := log.Logger
-	if !auth.IsAuthorized {
-		log.With().Bool("anonymous", true).Logger()
+	redis := srv.redis.Get()
+	auth, user, err := websocketUser(redis, getAuth(r))
+	redis.Close()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get user")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	logCtx := log.With()
+	if auth.IsAuthorized {
+		logCtx = logCtx.Int64("user", auth.ID)
 	} else {
-		log.With().Int64("user", auth.ID).Logger()
+		logCtx = logCtx.Bool("anonymous", true)
 	}
+	log := logCtx.Logger()
 	conn, err := srv.upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to upgrade client")
 		return
 	}
-	defer conn.Close()
+	wsConn := newWebsocketConn(conn, srv.redis)
+	// the dropped connection can be resumed by the client, the closed one is forgotten
+	closed := false
+	defer func() {
+		if closed {
+			wsConn.close()
+			srv.hub.unsubscribeAll(wsConn)
+			return
+		}
+		srv.hub.park(wsConn)
+	}()
+	// the connection is resumed only by the same user with the same anonymous sessions
+	sessions := newAnonymousSessions(getAnonymousSessions(r))
+	sessionIDs, _ := sessions.list()
+	wsConn.updateResume("user", func(resume model.WebsocketResume) error {
+		if err := resume.SetUser(auth.ID); err != nil {
+			return err
+		}
+		return resume.AddAnonymousSessions(sessionIDs...)
+	})
+	srv.hub.subscribe(websocketTopicAll, wsConn)
+	srv.hub.subscribe(connectionTopic(wsConn.token.String()), wsConn)
+	if err := wsConn.push(websocketConnectedEvent{
+		Token:   wsConn.token.String(),
+		Version: wsConn.version,
+	}); err != nil {
+		log.Error().Err(err).Msg("failed to push event")
+		return
+	}
+
+	// the client must answer pings, otherwise the connection is dropped
+	_ = conn.SetReadDeadline(time.Now().Add(websocketPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(websocketPongWait))
+	})
+
+	// requests are executed by workers, the context of the requests is cancelled when the client disconnects
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = context.WithValue(ctx, "log", log)
+	ctx = context.WithValue(ctx, "srv", srv)
+	ctx = context.WithValue(ctx, "conn", wsConn)
+	ctx = context.WithValue(ctx, "auth", auth)
+	ctx = context.WithValue(ctx, "user", user)
+	ctx = context.WithValue(ctx, "anonymousSessions", sessions)
+	workers := newWebsocketWorkerPool(websocketWorkers)
+	defer workers.close()
+	defer cancel()
 	for {
-		ctx := context.Background()
-		ctx = context.WithValue(ctx, "log", log)
-		ctx = context.WithValue(ctx, "srv", srv)
-		var req websocketMessage
 		mType, reqBts, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsCloseError(err,
@@ -42,63 +104,173 @@ func (srv *Service) HandleWebsocket(w http.ResponseWriter, r *http.Request) {
 				websocket.CloseGoingAway,
 			) {
 				log.Debug().Err(err).Msg("connection closed")
+				closed = true
 				return
 			}
 			log.Error().Err(err).Msg("failed to read message")
 			return
 		}
+		_ = conn.SetReadDeadline(time.Now().Add(websocketPongWait))
 		if mType != websocket.TextMessage {
 			log.Debug().Msg("message is not TextMessage")
 			continue
 		}
 		log.Debug().Msgf("ws request:  %s", reqBts)
-		if err := json.Unmarshal(reqBts, &req); err != nil {
+		reqs, batch, err := decodeWebsocketRequests(wsConn.version, reqBts)
+		if err != nil {
 			log.Error().Err(err).Msg("failed to unmarshal request")
 			return
 		}
-		resp := websocketMessage{
-			Method: req.Method,
-			Echo:   req.Echo,
+		if len(reqs) > websocketBatchLimit {
+			if err := wsConn.write(false, websocketMessage{
+				Error: newWebsocketError(websocketErrorValidation, "batch is larger than %d requests", websocketBatchLimit),
+			}); err != nil {
+				log.Error().Err(err).Msg("failed to write message")
+				return
+			}
+			continue
 		}
-		resp.Body, resp.Error = websocketRequestExecute(ctx, req.Method, req.Body)
-		respBts, err := json.Marshal(resp)
-		if err != nil {
-			log.Error().Err(err).Msg("failed to marshal response")
-			return
+		now := time.Now()
+		resps := make([]websocketMessage, len(reqs))
+		for i, req := range reqs {
+			resps[i] = websocketMessage{
+				Method: req.Method,
+				Echo:   req.Echo,
+			}
+			if !wsConn.limiter.allow(now) {
+				resps[i].Error = newWebsocketError(websocketErrorRateLimited, "too many requests")
+			}
+		}
+
+		ordered := false
+		for _, req := range reqs {
+			if websocketPool.IsMutating(req.Method) {
+				ordered = true
+			}
 		}
-		log.Debug().Msgf("ws response: %s", respBts)
-		if err := conn.WriteMessage(websocket.TextMessage, respBts); err != nil {
-			log.Error().Err(err).Msg("failed to write message")
+		// the reading waits for a free worker, so a client cannot start more requests than websocketWorkers
+		if !workers.execute(ordered, wsConn.done, func() {
+			// requests of a batch are executed in order
+			for i, req := range reqs {
+				if resps[i].Error != nil {
+					continue
+				}
+				resps[i].Body, resps[i].Error = websocketRequestExecute(ctx, req.Method, req.Body)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err := wsConn.write(batch, resps...); err != nil {
+				log.Warn().Err(err).Msg("failed to write message")
+			}
+		}) {
 			return
 		}
 	}
 }
 
-func websocketRequestExecute(ctx context.Context, method string, reqBody []byte) ([]byte, string) {
+// websocketUser returns the user of the connection. The connection is anonymous if the user from the cookie does not
+// exist anymore.
+func websocketUser(redis redis.Conn, auth *data.Auth) (*data.Auth, *model.User, error) {
+	if !auth.IsAuthorized {
+		return auth, &model.User{}, nil
+	}
+	user, isExists, err := model.UserByID(redis, auth.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isExists {
+		return &data.Auth{}, &model.User{}, nil
+	}
+	return auth, &user, nil
+}
+
+// decodeWebsocketRequests returns requests of the frame. Since the second version of the protocol the frame can be
+// an array of requests.
+func decodeWebsocketRequests(version int, frame []byte) ([]websocketMessage, bool, error) {
+	if version >= websocketProtocolV2 {
+		if trimmed := bytes.TrimSpace(frame); len(trimmed) > 0 && trimmed[0] == '[' {
+			var reqs []websocketMessage
+			if err := json.Unmarshal(trimmed, &reqs); err != nil {
+				return nil, false, err
+			}
+			return reqs, true, nil
+		}
+	}
+	var req websocketMessage
+	if err := json.Unmarshal(frame, &req); err != nil {
+		return nil, false, err
+	}
+	return []websocketMessage{req}, false, nil
+}
+
+func websocketRequestExecute(ctx context.Context, method string, reqBody []byte) ([]byte, *websocketError) {
 	reqObj, err := websocketPool.GetRequest(method)
 	if err != nil {
 		log.Warn().Err(err).Msg("failed to find request")
-		return nil, err.Error()
+		return nil, err.(*websocketError)
 	}
 	if len(reqBody) > 0 {
 		if err := json.Unmarshal(reqBody, reqObj); err != nil {
 			log.Warn().Err(err).Msg("failed to unmarshal body request")
-			return nil, "body invalid"
+			return nil, newWebsocketError(websocketErrorValidation, "body invalid")
 		}
 	}
 	if err := reqObj.Validate(ctx); err != nil {
 		log.Error().Err(err).Msg("failed to validate")
-		return nil, err.Error()
+		return nil, toWebsocketError(reqObj, err, websocketErrorValidation)
 	}
-	respObj, err := reqObj.Execute(ctx)
+	respObj, err := websocketRequestRun(ctx, reqObj)
 	if err != nil {
+		if wsErr, ok := err.(*websocketError); ok && wsErr.Code == websocketErrorTimeout {
+			log.Warn().Err(err).Str("method", method).Msg("request is not completed in time")
+			return nil, wsErr
+		}
 		log.Error().Err(err).Msg("failed to execute")
-		return nil, err.Error()
+		return nil, toWebsocketError(reqObj, err, websocketErrorInternal)
 	}
 	respBody, err := json.Marshal(respObj)
 	if err != nil {
 		log.Error().Err(err).Msg("failed to marshal body response")
-		return nil, "internal server error"
+		return nil, errWebsocketInternal
+	}
+	return respBody, nil
+}
+
+// websocketRequestRun executes the validated request. The mutating request locks its session and is completed even if
+// the client stops waiting for the response. Other requests are limited by websocketRequestTimeout or their own
+// timeout.
+func websocketRequestRun(ctx context.Context, reqObj websocketRequest) (websocketResponse, error) {
+	if mReq, ok := reqObj.(websocketMutatingRequest); ok {
+		if key := mReq.SessionKey(); key != "" {
+			unlock := ctx.Value("srv").(*Service).sessionLocks.lock(key)
+			defer unlock()
+		}
+		// the request waiting for the lock is not started if the client has gone
+		if ctx.Err() != nil {
+			return nil, newWebsocketError(websocketErrorTimeout, "request cancelled")
+		}
+		return reqObj.Execute(ctx)
+	}
+	timeout := websocketRequestTimeout
+	if t, ok := reqObj.(websocketTimeoutRequest); ok {
+		timeout = t.Timeout()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	type result struct {
+		resp websocketResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		respObj, err := reqObj.Execute(ctx)
+		done <- result{resp: respObj, err: err}
+	}()
+	select {
+	case res := <-done:
+		return res.resp, res.err
+	case <-ctx.Done():
+		return nil, newWebsocketError(websocketErrorTimeout, "request timed out")
 	}
-	return respBody, ""
 }
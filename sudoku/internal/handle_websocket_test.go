@@ -0,0 +1,117 @@
+package sudoku
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testSlowRequest is executed until the context is done or the delay passes.
+type testSlowRequest struct {
+	delay   time.Duration
+	session string
+	// executing counts requests of the session that are executed at the same time
+	executing *int
+	overlaps  *int
+	mx        *sync.Mutex
+}
+
+func (testSlowRequest) Method() string {
+	return "testSlow"
+}
+
+func (testSlowRequest) Errors() []websocketErrorCode {
+	return nil
+}
+
+func (testSlowRequest) Timeout() time.Duration {
+	return 10 * time.Millisecond
+}
+
+func (testSlowRequest) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r testSlowRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	if r.mx != nil {
+		r.mx.Lock()
+		*r.executing++
+		if *r.executing > 1 {
+			*r.overlaps++
+		}
+		r.mx.Unlock()
+		defer func() {
+			r.mx.Lock()
+			*r.executing--
+			r.mx.Unlock()
+		}()
+	}
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+	}
+	return websocketHealthResponse("OK"), nil
+}
+
+// testMutatingRequest is testSlowRequest that changes the session.
+type testMutatingRequest struct {
+	testSlowRequest
+}
+
+func (r testMutatingRequest) SessionKey() string {
+	return r.session
+}
+
+func TestWebsocketRequestRun(t *testing.T) {
+	ctx := testContext(testService(t))
+
+	t.Run("timeout", func(t *testing.T) {
+		_, err := websocketRequestRun(ctx, testSlowRequest{delay: time.Second})
+		if wsErr, ok := err.(*websocketError); !ok || wsErr.Code != websocketErrorTimeout {
+			t.Errorf("websocketRequestRun() error = %v, want code '%s'", err, websocketErrorTimeout)
+		}
+	})
+	t.Run("mutating without timeout", func(t *testing.T) {
+		resp, err := websocketRequestRun(ctx, testMutatingRequest{testSlowRequest{delay: 50 * time.Millisecond}})
+		if err != nil {
+			t.Fatalf("websocketRequestRun() error = %v, want nil", err)
+		}
+		if resp != websocketHealthResponse("OK") {
+			t.Errorf("websocketRequestRun() = %v, want OK", resp)
+		}
+	})
+	t.Run("mutating cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		_, err := websocketRequestRun(ctx, testMutatingRequest{testSlowRequest{}})
+		if wsErr, ok := err.(*websocketError); !ok || wsErr.Code != websocketErrorTimeout {
+			t.Errorf("websocketRequestRun() error = %v, want code '%s'", err, websocketErrorTimeout)
+		}
+	})
+	t.Run("mutating of one session", func(t *testing.T) {
+		var mx sync.Mutex
+		executing, overlaps := 0, 0
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				req := testMutatingRequest{testSlowRequest{
+					delay:     5 * time.Millisecond,
+					session:   "session",
+					executing: &executing,
+					overlaps:  &overlaps,
+					mx:        &mx,
+				}}
+				if _, err := websocketRequestRun(ctx, req); err != nil {
+					t.Errorf("websocketRequestRun() error = %v, want nil", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if overlaps != 0 {
+			t.Errorf("requests of one session are executed at the same time %d times, want 0", overlaps)
+		}
+	})
+}
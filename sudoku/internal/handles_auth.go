@@ -88,6 +88,7 @@ func (srv *Service) HandleLogin(w http.ResponseWriter, r *http.Request) {
 				log.Error().Err(err).Msg("failed to create 'auth' cookie")
 				return ErrorInternalServerError
 			}
+			srv.claimAnonymousSessions(w, r, user)
 			return ""
 		}()
 		if d.ErrorMessage == "" {
@@ -108,6 +109,33 @@ func (srv *Service) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	srv.executeTemplate(w, "page_login", args)
 }
 
+// claimAnonymousSessions attaches anonymous sudoku sessions created in the browser to the user.
+func (srv *Service) claimAnonymousSessions(w http.ResponseWriter, r *http.Request, user model.User) {
+	sessionIDs := getAnonymousSessions(r)
+	if len(sessionIDs) == 0 {
+		return
+	}
+	redis := srv.redis.Get()
+	defer redis.Close()
+	for _, sessionID := range sessionIDs {
+		session, err := model.SudokuSessionByIDString(redis, sessionID)
+		if err != nil {
+			log.Warn().Err(err).Str("session", sessionID).Msg("failed to get sudoku session")
+			continue
+		}
+		if session.IsNull() {
+			continue
+		}
+		isClaimed, err := session.Claim(user)
+		if err != nil {
+			log.Error().Err(err).Str("session", sessionID).Msg("failed to claim sudoku session")
+			continue
+		}
+		log.Debug().Str("session", sessionID).Int64("id", user.ID()).Bool("claimed", isClaimed).Msg("anonymous sudoku session")
+	}
+	deleteSessionsCookie(w)
+}
+
 // HandleLogout is a handler of logout.
 func (srv *Service) HandleLogout(w http.ResponseWriter, r *http.Request) {
 	a := getAuth(r)
@@ -198,6 +226,7 @@ func (srv *Service) HandleSignup(w http.ResponseWriter, r *http.Request) {
 				log.Error().Err(err).Msg("failed to create 'auth' cookie")
 				return ErrorInternalServerError
 			}
+			srv.claimAnonymousSessions(w, r, user)
 			return ""
 		}()
 		if d.ErrorMessage == "" {
@@ -0,0 +1,41 @@
+package sudoku
+
+import "sync"
+
+// keyedMutex is a set of mutexes by keys. The mutex of the key exists while it is locked or awaited. The zero value is
+// ready to use.
+type keyedMutex struct {
+	mx    sync.Mutex
+	locks map[string]*keyedMutexLock
+}
+
+type keyedMutexLock struct {
+	mx   sync.Mutex
+	refs int
+}
+
+// lock locks the key and returns the function that unlocks it.
+func (m *keyedMutex) lock(key string) func() {
+	m.mx.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*keyedMutexLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyedMutexLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mx.Unlock()
+
+	l.mx.Lock()
+	return func() {
+		l.mx.Unlock()
+		m.mx.Lock()
+		defer m.mx.Unlock()
+		l.refs--
+		if l.refs == 0 {
+			delete(m.locks, key)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/gomodule/redigo/redis"
+	"testing"
+	"time"
+)
+
+// testService returns the service connected to the in-memory Redis server of the test.
+func testService(t *testing.T) *Service {
+	t.Helper()
+	addr := miniredis.RunT(t).Addr()
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr)
+		},
+	}
+	t.Cleanup(func() { pool.Close() })
+	return &Service{
+		redis: pool,
+		hub:   newWebsocketHub(nil),
+	}
+}
+
+// testContext returns the context of requests of the anonymous client that has created the sessions.
+func testContext(srv *Service, sessionIDs ...string) context.Context {
+	ctx := context.WithValue(context.Background(), "srv", srv)
+	ctx = context.WithValue(ctx, "auth", &data.Auth{})
+	ctx = context.WithValue(ctx, "user", &model.User{})
+	return context.WithValue(ctx, "anonymousSessions", newAnonymousSessions(sessionIDs))
+}
+
+// testSudokuSession returns the started session of the anonymous player.
+func testSudokuSession(t *testing.T, conn redis.Conn, sudoku model.Sudoku) model.SudokuSession {
+	t.Helper()
+	session, err := model.NewSudokuSession(conn, sudoku, model.User{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStartSudokuSession(t, session)
+	return session
+}
+
+// testStartSudokuSession starts the timer of the session.
+func testStartSudokuSession(t *testing.T, session model.SudokuSession) {
+	t.Helper()
+	timer := data.SudokuSessionTimer{}
+	timer.Start(time.Now())
+	if err := session.SetTimer(timer); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testSudoku returns the saved puzzle with one empty cell.
+func testSudoku(t *testing.T, conn redis.Conn) model.Sudoku {
+	t.Helper()
+	const board = "123456789456789123789123456214365897365897214897214365531642978642978531978531642"
+	sudoku, err := model.NewSudoku(conn, board, "."+board[1:], data.SudokuLevelEasy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sudoku
+}
+
+// testWebsocketConn returns the connection without the network. Frames are queued in the send channel.
+func testWebsocketConn() *websocketConn {
+	return &websocketConn{
+		version: websocketProtocolV2,
+		send:    make(chan []byte, websocketSendBuffer),
+		done:    make(chan struct{}),
+		players: make(map[string]data.CoopPlayer),
+	}
+}
+
+// testWebsocketEvents returns names of events queued to the connection by the session ID of the event.
+func testWebsocketEvents(t *testing.T, conn *websocketConn) map[string]string {
+	t.Helper()
+	events := make(map[string]string)
+	for {
+		select {
+		case frame := <-conn.send:
+			var msg struct {
+				Event string `json:"event"`
+				Body  struct {
+					SessionID string `json:"sessionID"`
+				} `json:"body"`
+			}
+			if err := json.Unmarshal(frame, &msg); err != nil {
+				t.Fatal(err)
+			}
+			events[msg.Body.SessionID] = msg.Event
+		default:
+			return events
+		}
+	}
+}
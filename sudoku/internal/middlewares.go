@@ -14,10 +14,11 @@ func (srv *Service) MiddlewareCookies(next http.Handler) http.Handler {
 		log := log.With().Str("path", r.URL.Path).Logger()
 		ctx := r.Context()
 		ctx = context.WithValue(ctx, "auth", &data.Auth{})
-		for _, name := range []string{"auth"} {
+		ctx = context.WithValue(ctx, "sessions", []string(nil))
+		for _, name := range []string{"auth", "sessions"} {
 			c, err := r.Cookie(name)
 			if err != nil {
-				log.Debug().Err(err).Msg("cookie 'auth' not found")
+				log.Debug().Err(err).Msgf("cookie '%s' not found", name)
 				continue
 			}
 			switch c.Name {
@@ -30,6 +31,15 @@ func (srv *Service) MiddlewareCookies(next http.Handler) http.Handler {
 					continue
 				}
 				ctx = context.WithValue(ctx, "auth", &a)
+
+			// read 'sessions' cookie
+			case "sessions":
+				var sessionIDs []string
+				if err := srv.securecookie.Decode("sessions", c.Value, &sessionIDs); err != nil {
+					log.Warn().Err(err).Msg("failed to decode cookie 'sessions'")
+					continue
+				}
+				ctx = context.WithValue(ctx, "sessions", sessionIDs)
 			}
 		}
 		next.ServeHTTP(w, r.WithContext(ctx))
@@ -79,6 +89,11 @@ func getAuth(r *http.Request) *data.Auth {
 	return r.Context().Value("auth").(*data.Auth)
 }
 
+// getAnonymousSessions get IDs of anonymous sudoku sessions created in the browser from request's context.
+func getAnonymousSessions(r *http.Request) []string {
+	return r.Context().Value("sessions").([]string)
+}
+
 func getUser(r *http.Request) *model.User {
 	return r.Context().Value("user").(*model.User)
 }
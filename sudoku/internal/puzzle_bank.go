@@ -0,0 +1,172 @@
+package sudoku
+
+import (
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/gomodule/redigo/redis"
+	"github.com/rs/zerolog/log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultPuzzleBankSize is the target number of puzzles of each variant and difficulty in the bank.
+	defaultPuzzleBankSize = 20
+	// defaultPuzzleBankWorkers is the number of puzzles generated for the bank at the same time.
+	defaultPuzzleBankWorkers = 1
+	// puzzleBankInterval is the period of checking the full bank. Workers are woken earlier when a puzzle is taken.
+	puzzleBankInterval = 10 * time.Second
+)
+
+// puzzleBankVariants are variants of puzzles in the bank.
+var puzzleBankVariants = []data.SudokuVariant{data.SudokuVariantClassic}
+
+// puzzleBank fills banks of puzzles of all variants and difficulties to the target size in the background.
+type puzzleBank struct {
+	redis *redis.Pool
+	// The target size of each bank. Zero disables the bank.
+	size    int64
+	workers int
+	// refill wakes workers after a puzzle is taken from the bank
+	refill chan struct{}
+
+	// mx prevents workers of the instance from generating the last missing puzzle of the bank at the same time.
+	// Instances of the service can overfill the bank by a few puzzles.
+	mx         sync.Mutex
+	generating map[puzzleBankKey]int64
+}
+
+type puzzleBankKey struct {
+	variant data.SudokuVariant
+	level   data.SudokuLevel
+}
+
+// newPuzzleBank returns the bank configured by environment variables PUZZLE_BANK_SIZE and PUZZLE_BANK_WORKERS.
+func newPuzzleBank(redis *redis.Pool) (*puzzleBank, error) {
+	b := &puzzleBank{
+		redis:      redis,
+		size:       defaultPuzzleBankSize,
+		workers:    defaultPuzzleBankWorkers,
+		refill:     make(chan struct{}, 1),
+		generating: make(map[puzzleBankKey]int64),
+	}
+	if s := os.Getenv("PUZZLE_BANK_SIZE"); s != "" {
+		size, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || size < 0 {
+			log.Error().Err(err).Str("value", s).Msg("failed to parse 'PUZZLE_BANK_SIZE' env variable")
+			return nil, fmt.Errorf("PUZZLE_BANK_SIZE must be a non-negative number")
+		}
+		b.size = size
+	}
+	if s := os.Getenv("PUZZLE_BANK_WORKERS"); s != "" {
+		workers, err := strconv.Atoi(s)
+		if err != nil || workers < 1 {
+			log.Error().Err(err).Str("value", s).Msg("failed to parse 'PUZZLE_BANK_WORKERS' env variable")
+			return nil, fmt.Errorf("PUZZLE_BANK_WORKERS must be a positive number")
+		}
+		b.workers = workers
+	}
+	return b, nil
+}
+
+// run starts workers that fill the bank.
+func (b *puzzleBank) run() {
+	if b.size == 0 {
+		return
+	}
+	for i := 0; i < b.workers; i++ {
+		go b.runWorker()
+	}
+}
+
+func (b *puzzleBank) runWorker() {
+	ticker := time.NewTicker(puzzleBankInterval)
+	defer ticker.Stop()
+	for {
+		isGenerated, err := b.fill()
+		if err != nil {
+			log.Error().Err(err).Msg("failed to fill puzzle bank")
+		}
+		if isGenerated && err == nil {
+			continue
+		}
+		select {
+		case <-ticker.C:
+		case <-b.refill:
+		}
+	}
+}
+
+// fill generates a puzzle for the bank with the largest shortage. Returns false if all banks are full.
+func (b *puzzleBank) fill() (bool, error) {
+	conn := b.redis.Get()
+	defer conn.Close()
+
+	bank, ok, err := b.reserve(conn)
+	if err != nil || !ok {
+		return false, err
+	}
+	defer b.release(bank)
+
+	start := time.Now()
+	sudoku, err := createSudoku(conn, randomSeed(), bank.Level())
+	if err != nil {
+		return false, err
+	}
+	if err := bank.Push(sudoku); err != nil {
+		return false, err
+	}
+	log.Debug().Str("variant", string(bank.Variant())).Str("level", string(bank.Level())).
+		Dur("duration", time.Since(start)).Msg("puzzle is added to the bank")
+	return true, nil
+}
+
+// reserve returns the bank with the largest shortage taking into account puzzles being generated by other workers.
+func (b *puzzleBank) reserve(conn redis.Conn) (model.PuzzleBank, bool, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	var (
+		target   model.PuzzleBank
+		shortage int64
+	)
+	for _, variant := range puzzleBankVariants {
+		for _, level := range data.SudokuLevels {
+			bank := model.PuzzleBankOf(conn, variant, level)
+			n, err := bank.Len()
+			if err != nil {
+				return model.PuzzleBank{}, false, err
+			}
+			if s := b.size - n - b.generating[puzzleBankKey{variant, level}]; s > shortage {
+				target, shortage = bank, s
+			}
+		}
+	}
+	if shortage <= 0 {
+		return model.PuzzleBank{}, false, nil
+	}
+	b.generating[puzzleBankKey{target.Variant(), target.Level()}]++
+	return target, true, nil
+}
+
+func (b *puzzleBank) release(bank model.PuzzleBank) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	b.generating[puzzleBankKey{bank.Variant(), bank.Level()}]--
+}
+
+// pop takes a puzzle not played by the user from the bank and wakes workers to replace it. Returns false if the bank
+// has no such puzzles.
+func (b *puzzleBank) pop(conn redis.Conn, variant data.SudokuVariant, level data.SudokuLevel, user model.User) (model.Sudoku, bool, error) {
+	if b.size == 0 {
+		return model.Sudoku{}, false, nil
+	}
+	sudoku, ok, err := model.PuzzleBankOf(conn, variant, level).Pop(user)
+	select {
+	case b.refill <- struct{}{}:
+	default:
+	}
+	return sudoku, ok, err
+}
@@ -0,0 +1,50 @@
+package sudoku
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"testing"
+)
+
+func TestCreateGameSudoku(t *testing.T) {
+	srv := testService(t)
+	redis := srv.redis.Get()
+	defer redis.Close()
+	banked := testSudoku(t, redis)
+	if err := model.PuzzleBankOf(redis, data.SudokuVariantClassic, data.SudokuLevelEasy).Push(banked); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		size     int64
+		isBanked bool
+	}{
+		{name: "disabled bank", size: 0},
+		{name: "from bank", size: 1, isBanked: true},
+		{name: "empty bank", size: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv.puzzleBank = &puzzleBank{
+				redis:  srv.redis,
+				size:   tt.size,
+				refill: make(chan struct{}, 1),
+			}
+			sudoku, err := srv.createGameSudoku(redis, data.SudokuLevelEasy, model.User{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isBanked := sudoku.ID() == banked.ID(); isBanked != tt.isBanked {
+				t.Errorf("createGameSudoku() = %d, banked %v, want %v", sudoku.ID(), isBanked, tt.isBanked)
+			}
+			level, err := sudoku.Level()
+			if err != nil || level != data.SudokuLevelEasy {
+				t.Errorf("Level() = %s, %v, want %s", level, err, data.SudokuLevelEasy)
+			}
+			if isRefilled := len(srv.puzzleBank.refill) == 1; isRefilled != (tt.size > 0) {
+				t.Errorf("refill = %v, want %v", isRefilled, tt.size > 0)
+			}
+		})
+	}
+}
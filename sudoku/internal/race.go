@@ -0,0 +1,124 @@
+package sudoku
+
+import (
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/cnblvr/sudoku/sudoku/internal/sudoku_classic"
+	"github.com/gomodule/redigo/redis"
+	"github.com/rs/zerolog/log"
+	"sort"
+)
+
+func init() {
+	websocketEvents.Add((*websocketRaceProgressEvent)(nil))
+}
+
+// websocketRaceProgressEvent is sent to all players of the race after each change of the progress.
+type websocketRaceProgressEvent struct {
+	data.RaceState
+}
+
+func (websocketRaceProgressEvent) Event() string {
+	return "raceProgress"
+}
+
+// raceTopic returns the websocket topic of the race players.
+func raceTopic(race model.Race) string {
+	return fmt.Sprintf("race:%s", race.ID().String())
+}
+
+// raceState collects the progress of all players of the race. Sessions of players are revealed after the end of the
+// race.
+func raceState(race model.Race) (data.RaceState, error) {
+	state := data.RaceState{
+		RaceID: race.ID().String(),
+	}
+	puzzleStr, err := race.Sudoku().Puzzle()
+	if err != nil {
+		return data.RaceState{}, err
+	}
+	boardStr, err := race.Sudoku().Board()
+	if err != nil {
+		return data.RaceState{}, err
+	}
+	puzzle, board := sudoku_classic.PuzzleFromString(puzzleStr), sudoku_classic.PuzzleFromString(boardStr)
+	winner, err := race.Winner()
+	if err != nil {
+		return data.RaceState{}, err
+	}
+	if !winner.IsNull() {
+		state.Winner = winner.ID().String()
+	}
+	sessions, err := race.Sessions()
+	if err != nil {
+		return data.RaceState{}, err
+	}
+	for _, session := range sessions {
+		player := data.RacePlayer{
+			SessionID: session.ID().String(),
+		}
+		user, err := session.User()
+		if err != nil {
+			return data.RaceState{}, err
+		}
+		if !user.IsNull() {
+			if player.Username, err = user.Username(); err != nil {
+				return data.RaceState{}, err
+			}
+		}
+		stateStr, err := session.State()
+		if err != nil {
+			return data.RaceState{}, err
+		}
+		if stateStr == "" {
+			stateStr = puzzleStr
+		}
+		player.Progress = data.SudokuProgress(puzzle, sudoku_classic.PuzzleFromString(stateStr), board)
+		status, err := session.Status()
+		if err != nil {
+			return data.RaceState{}, err
+		}
+		if status == data.SudokuSessionSolved {
+			player.Solved = true
+			solveTime, err := session.SolveTime()
+			if err != nil {
+				return data.RaceState{}, err
+			}
+			player.SolveTime = data.Duration(solveTime)
+		}
+		state.Players = append(state.Players, player)
+	}
+	sort.Slice(state.Players, func(i, j int) bool {
+		if state.Players[i].Progress != state.Players[j].Progress {
+			return state.Players[i].Progress > state.Players[j].Progress
+		}
+		return state.Players[i].SessionID < state.Players[j].SessionID
+	})
+	if state.Winner == "" {
+		for i := range state.Players {
+			state.Players[i].SessionID = ""
+		}
+	}
+	return state, nil
+}
+
+// broadcastRace sends the progress of players to everyone in the race if the session is played in a race. Errors are
+// only logged, because the step of the player has already been saved.
+func (srv *Service) broadcastRace(redis redis.Conn, session model.SudokuSession) {
+	log := log.With().Str("session", session.ID().String()).Logger()
+	race, err := session.Race()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get race of session")
+		return
+	}
+	if race.IsNull() {
+		return
+	}
+	state, err := raceState(race)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to get race state")
+		return
+	}
+	srv.hub.publish(raceTopic(race), websocketRaceProgressEvent{state})
+}
@@ -0,0 +1,85 @@
+package sudoku
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRaceState checks that sessions of players are revealed only after the end of the race.
+func TestRaceState(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	race, err := model.NewRace(conn, testSudoku(t, conn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sessions []model.SudokuSession
+	for i := 0; i < 2; i++ {
+		session, err := race.Join(model.User{}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		testStartSudokuSession(t, session)
+		sessions = append(sessions, session)
+	}
+
+	state, err := raceState(race)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, player := range state.Players {
+		if player.SessionID != "" {
+			t.Errorf("raceState() reveals session %s before the end of the race", player.SessionID)
+		}
+	}
+
+	if _, _, err := srv.finishSudokuSession(sessions[0], time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if state, err = raceState(race); err != nil {
+		t.Fatal(err)
+	}
+	if state.Winner != sessions[0].ID().String() {
+		t.Errorf("raceState() winner = %s, want %s", state.Winner, sessions[0].ID())
+	}
+	for _, player := range state.Players {
+		if player.SessionID == "" {
+			t.Errorf("raceState() does not reveal sessions after the end of the race")
+		}
+	}
+}
+
+// TestHandleRaceCreate checks that the race is created on the puzzle from the bank.
+func TestHandleRaceCreate(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	banked := testSudoku(t, conn)
+	if err := model.PuzzleBankOf(conn, data.SudokuVariantClassic, data.SudokuLevelEasy).Push(banked); err != nil {
+		t.Fatal(err)
+	}
+	srv.puzzleBank = &puzzleBank{
+		redis:  srv.redis,
+		size:   1,
+		refill: make(chan struct{}, 1),
+	}
+
+	w := httptest.NewRecorder()
+	srv.HandleRaceCreate(w, testAPIRequest(http.MethodGet, data.EndpointRaceNew+"?level=easy", "", nil))
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("HandleRaceCreate() status = %d, want %d", w.Code, http.StatusSeeOther)
+	}
+	race, err := model.RaceByIDString(conn, strings.TrimPrefix(w.Header().Get("Location"), data.EndpointRace("")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if race.Sudoku().ID() != banked.ID() {
+		t.Errorf("HandleRaceCreate() sudoku = %d, want %d from the bank", race.Sudoku().ID(), banked.ID())
+	}
+}
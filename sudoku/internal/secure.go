@@ -37,6 +37,39 @@ func deleteAuthCookie(w http.ResponseWriter) {
 	})
 }
 
+// maxAnonymousSessions is the maximum number of anonymous sessions stored in the 'sessions' cookie.
+const maxAnonymousSessions = 20
+
+// createSessionsCookie creates the cookie with IDs of anonymous sudoku sessions created in the browser.
+func (srv *Service) createSessionsCookie(w http.ResponseWriter, sessionIDs []string) error {
+	if len(sessionIDs) > maxAnonymousSessions {
+		sessionIDs = sessionIDs[len(sessionIDs)-maxAnonymousSessions:]
+	}
+	value, err := srv.securecookie.Encode("sessions", sessionIDs)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     "sessions",
+		Value:    value,
+		Path:     "/",
+		Expires:  time.Now().Add(time.Hour * 24 * 30),
+		HttpOnly: true,
+	})
+	return nil
+}
+
+// deleteSessionsCookie writes an empty cookie with IDs of anonymous sudoku sessions to be deleted.
+func deleteSessionsCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "sessions",
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+	})
+}
+
 // generatePasswordSalt generates the password hash salt. Different for each user.
 func generatePasswordSalt() []byte {
 	buf := make([]byte, 16)
@@ -26,6 +26,14 @@ type Service struct {
 	passwordPepper []byte
 	// gorilla/websocket object
 	upgrader websocket.Upgrader
+	// Topics of websocket connections for server events
+	hub *websocketHub
+	// Pre-generated puzzles for new games
+	puzzleBank *puzzleBank
+	// Locks of sessions that serialize requests changing the same session
+	sessionLocks keyedMutex
+	// Rate limiters of clients of the REST API
+	apiLimiters apiRateLimiters
 }
 
 // NewService initialize the service sudoku.
@@ -90,12 +98,31 @@ func NewService() (*Service, error) {
 		return nil, err
 	}
 
+	// Background generation of puzzles
+	srv.puzzleBank, err = newPuzzleBank(srv.redis)
+	if err != nil {
+		return nil, err
+	}
+
 	// init upgrader
-	srv.upgrader = websocket.Upgrader{}
+	srv.upgrader = websocket.Upgrader{
+		Subprotocols: websocketSubprotocols,
+	}
+	srv.hub = newWebsocketHub(srv.redis)
+	go srv.hub.run()
+	go srv.runTimerTicks()
+	go srv.runDailyNotifications()
+	srv.puzzleBank.run()
 
 	return srv, nil
 }
 
+// Shutdown closes websocket connections with the code of the restarting server. Clients reconnect and resume the
+// connections on another instance of the service.
+func (srv *Service) Shutdown() {
+	srv.hub.closeAll(websocket.CloseServiceRestart, "server is restarting")
+}
+
 func (srv *Service) executeTemplate(w http.ResponseWriter, name string, args templates.Args) {
 	err := srv.templates.ExecuteTemplate(w, name, args)
 	if err != nil {
@@ -50,7 +50,7 @@ func (c sudokuCandidates) MarshalJSON() ([]byte, error) {
 func (c sudokuCandidates) forEach(fn func(p data.Point, candidates []int8)) {
 	for row := 0; row < 9; row++ {
 		for col := 0; col < 9; col++ {
-			fn(data.Point{row, col}, c.in(data.Point{row, col}))
+			fn(data.Point{Row: row, Col: col}, c.in(data.Point{Row: row, Col: col}))
 		}
 	}
 }
@@ -58,23 +58,23 @@ func (c sudokuCandidates) forEach(fn func(p data.Point, candidates []int8)) {
 // todo break and excludes
 func (c sudokuCandidates) forEachInRow(row int, fn func(p data.Point, candidates []int8)) {
 	for col := 0; col < 9; col++ {
-		fn(data.Point{row, col}, c.in(data.Point{row, col}))
+		fn(data.Point{Row: row, Col: col}, c.in(data.Point{Row: row, Col: col}))
 	}
 }
 
 // todo break and excludes
 func (c sudokuCandidates) forEachInCol(col int, fn func(p data.Point, candidates []int8)) {
 	for row := 0; row < 9; row++ {
-		fn(data.Point{row, col}, c.in(data.Point{row, col}))
+		fn(data.Point{Row: row, Col: col}, c.in(data.Point{Row: row, Col: col}))
 	}
 }
 
 // todo break and excludes
 func (c sudokuCandidates) forEachInBox(p data.Point, fn func(p data.Point, candidates []int8)) {
-	pBox := data.Point{(p.Row / 3) * 3, (p.Col / 3) * 3}
+	pBox := data.Point{Row: (p.Row / 3) * 3, Col: (p.Col / 3) * 3}
 	for row := 0; row < 3; row++ {
 		for col := 0; col < 3; col++ {
-			pCurrent := data.Point{pBox.Row + row, pBox.Col + col}
+			pCurrent := data.Point{Row: pBox.Row + row, Col: pBox.Col + col}
 			fn(pCurrent, c.in(pCurrent))
 		}
 	}
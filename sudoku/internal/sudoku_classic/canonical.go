@@ -0,0 +1,107 @@
+package sudoku_classic
+
+import (
+	"bytes"
+	"github.com/cnblvr/sudoku/data"
+)
+
+// Canonical returns the same string for puzzles that differ only by transformations that keep the rules: a
+// transposition, permutations of "big" lines, permutations of lines within one "big" line and a renumbering of digits.
+// Rotations and reflections are combinations of them. Such puzzles are the same puzzle for the player. The canonical
+// form is the smallest string of all equivalent puzzles.
+func Canonical(puzzle data.SudokuPuzzle) string {
+	p := sudokuPuzzleFromString(puzzle.String())
+	var c canonicalSearch
+	for _, grid := range []sudokuPuzzle{p, p.transposed()} {
+		for _, cols := range canonicalLinePermutations {
+			for row := 0; row < 9; row++ {
+				for col := 0; col < 9; col++ {
+					c.grid[row][col] = grid[row][cols[col]]
+				}
+			}
+			c.search(0, 0, 0, [10]byte{}, 1)
+		}
+	}
+	out := sudokuPuzzleFromString("")
+	for idx, v := range c.best {
+		out[idx/9][idx%9] = int8(v)
+	}
+	return out.String()
+}
+
+// canonicalLinePermutations are all 1296 orders of lines that keep lines of each "big" line together.
+var canonicalLinePermutations = func() (perms [][9]int) {
+	orders := [][3]int{{0, 1, 2}, {0, 2, 1}, {1, 0, 2}, {1, 2, 0}, {2, 0, 1}, {2, 1, 0}}
+	for _, big := range orders {
+		for _, a := range orders {
+			for _, b := range orders {
+				for _, c := range orders {
+					var perm [9]int
+					for i, small := range [3][3]int{a, b, c} {
+						for j := 0; j < 3; j++ {
+							perm[i*3+j] = big[i]*3 + small[j]
+						}
+					}
+					perms = append(perms, perm)
+				}
+			}
+		}
+	}
+	return
+}()
+
+// canonicalSearch finds the smallest string of the grid with columns in the fixed order over all orders of rows and
+// renumberings of digits. Orders of rows that give a greater prefix than the best string are not completed.
+type canonicalSearch struct {
+	grid    [9][9]int8
+	current [81]byte
+	best    [81]byte
+	hasBest bool
+}
+
+// search chooses the row at the position k. Digits are numbered in the order of their first appearance, which gives
+// the smallest string for the order of rows.
+func (c *canonicalSearch) search(k int, band int, usedRows uint16, numbers [10]byte, next byte) {
+	if k == 9 {
+		// the string is not greater than the best one, otherwise it would be cut off
+		c.best, c.hasBest = c.current, true
+		return
+	}
+	for row := 0; row < 9; row++ {
+		if usedRows&(1<<row) != 0 {
+			continue
+		}
+		if k%3 == 0 {
+			// the first row of an unused "big" line
+			if usedRows>>(row/3*3)&7 != 0 {
+				continue
+			}
+		} else if row/3 != band {
+			continue
+		}
+		rowNumbers, rowNext := numbers, next
+		for col := 0; col < 9; col++ {
+			v := c.grid[row][col]
+			if v != 0 && rowNumbers[v] == 0 {
+				rowNumbers[v] = rowNext
+				rowNext++
+			}
+			c.current[k*9+col] = rowNumbers[v]
+		}
+		if c.hasBest && bytes.Compare(c.current[:k*9+9], c.best[:k*9+9]) > 0 {
+			continue
+		}
+		c.search(k+1, row/3, usedRows|1<<row, rowNumbers, rowNext)
+	}
+}
+
+// transposed returns the puzzle reflected across the main diagonal.
+func (p sudokuPuzzle) transposed() sudokuPuzzle {
+	out := sudokuPuzzleFromString("")
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			out[col][row] = p[row][col]
+		}
+	}
+	return out
+}
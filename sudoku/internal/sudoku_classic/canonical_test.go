@@ -0,0 +1,51 @@
+package sudoku_classic
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"strings"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	const puzzle = "...1.5...14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9..."
+	want := Canonical(PuzzleFromString(puzzle))
+
+	rotated := sudokuBoard(sudokuPuzzleFromString(puzzle))
+	rotated.reflect(data.Horizontal)
+	rotated.rotate(data.Rotate90)
+	renumbered := strings.NewReplacer("1", "9", "9", "1", "2", "5", "5", "2").Replace(sudokuString(rotated))
+
+	swapped := sudokuBoard(sudokuPuzzleFromString(puzzle))
+	swapped.swapLines(data.Horizontal, 0, 2)
+	swapped.swapLines(data.Vertical, 4, 5)
+	// bands and stacks
+	for i := 0; i < 3; i++ {
+		swapped.swapLines(data.Horizontal, 3+i, 6+i)
+		swapped.swapLines(data.Vertical, i, 3+i)
+	}
+
+	tests := []struct {
+		name   string
+		puzzle string
+		same   bool
+	}{
+		{name: "same", puzzle: puzzle, same: true},
+		{name: "reflected, rotated and renumbered", puzzle: renumbered, same: true},
+		{name: "lines and big lines swapped", puzzle: sudokuString(swapped), same: true},
+		{name: "another", puzzle: ".....5...14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9...", same: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Canonical(PuzzleFromString(tt.puzzle)); (got == want) != tt.same {
+				t.Errorf("Canonical() = %s, canonical of the puzzle %s", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkCanonical(b *testing.B) {
+	puzzle := PuzzleFromString("...1.5...14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9...")
+	for i := 0; i < b.N; i++ {
+		Canonical(puzzle)
+	}
+}
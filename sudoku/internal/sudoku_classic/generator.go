@@ -0,0 +1,50 @@
+package sudoku_classic
+
+import (
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"sort"
+)
+
+// GeneratorVersion is the version of the generator of NewSudoku.
+const GeneratorVersion = 1
+
+// Generator creates the puzzle by the seed and removes some hints depending on the level.
+type Generator func(seed int64, level data.SudokuLevel) data.Sudoku
+
+// generators are all versions of the generator. Links to puzzles contain the seed and the version, so a generator
+// must give the same puzzles forever. A changed algorithm is added as a new version, and the old one and the code it
+// depends on stay unchanged. Outputs of every version are pinned by golden files in testdata.
+var generators = map[int]Generator{
+	1: newSudokuV1,
+}
+
+// NewSudoku creates a new puzzle by the current version of the generator.
+func NewSudoku(seed int64, level data.SudokuLevel) data.Sudoku {
+	return generators[GeneratorVersion](seed, level)
+}
+
+// NewSudokuVersion creates the puzzle by the generator of the version. Returns an error if the version is unknown.
+func NewSudokuVersion(version int, seed int64, level data.SudokuLevel) (data.Sudoku, error) {
+	generator, ok := generators[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown generator version %d", version)
+	}
+	return generator(seed, level), nil
+}
+
+// HasGeneratorVersion reports whether the version of the generator is registered.
+func HasGeneratorVersion(version int) bool {
+	_, ok := generators[version]
+	return ok
+}
+
+// GeneratorVersions returns all versions of the generator in ascending order.
+func GeneratorVersions() []int {
+	versions := make([]int, 0, len(generators))
+	for version := range generators {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
@@ -0,0 +1,76 @@
+package sudoku_classic
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "create golden files of new generator versions")
+
+// goldenSeeds are seeds of puzzles pinned by golden files. The generator reduces seeds modulo 2^31-1, so seeds are
+// below it to give different puzzles. The seeds are chosen for fast generation of hard puzzles.
+var goldenSeeds = []int64{2, 21, 27, 1000000007}
+
+// TestGeneratorGolden fails if a version of the generator gives other puzzles than before. Golden files of new
+// versions are created with -update, existing files are never rewritten.
+func TestGeneratorGolden(t *testing.T) {
+	for _, version := range GeneratorVersions() {
+		t.Run(fmt.Sprintf("v%d", version), func(t *testing.T) {
+			var got bytes.Buffer
+			for _, level := range []data.SudokuLevel{data.SudokuLevelEasy, data.SudokuLevelMedium, data.SudokuLevelHard} {
+				for _, seed := range goldenSeeds {
+					s, err := NewSudokuVersion(version, seed, level)
+					if err != nil {
+						t.Fatalf("NewSudokuVersion() error = %v", err)
+					}
+					fmt.Fprintf(&got, "%d %s %s %s\n", seed, level, s.Puzzle().String(), s.Board().String())
+				}
+			}
+			name := filepath.Join("testdata", fmt.Sprintf("generator_v%d.golden", version))
+			want, err := os.ReadFile(name)
+			if errors.Is(err, os.ErrNotExist) && *updateGolden {
+				if err := os.WriteFile(name, got.Bytes(), 0644); err != nil {
+					t.Fatalf("failed to create: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to read %s, run 'go test ./sudoku/internal/sudoku_classic/ -run TestGeneratorGolden -update' for the new version: %v", name, err)
+			}
+			if !bytes.Equal(got.Bytes(), want) {
+				t.Errorf("generator v%d gives other puzzles than %s, add the changed algorithm as a new version", version, name)
+			}
+		})
+	}
+}
+
+func TestNewSudokuVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		wantErr bool
+	}{
+		{name: "current", version: GeneratorVersion},
+		{name: "unknown", version: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := NewSudokuVersion(tt.version, 2, data.SudokuLevelEasy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewSudokuVersion() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if want := NewSudoku(2, data.SudokuLevelEasy); s.Puzzle().String() != want.Puzzle().String() {
+				t.Errorf("NewSudokuVersion() = %s, want %s", s.Puzzle().String(), want.Puzzle().String())
+			}
+		})
+	}
+}
@@ -41,7 +41,7 @@ func TestManualGenerate(t *testing.T) {
 func TestSimpleGeneration(t *testing.T) {
 	const seed = 2
 
-	s := NewSudoku(seed)
+	s := NewSudoku(seed, data.SudokuLevelMedium).(*Sudoku)
 	t.Logf("base     %s\n%s", s.board.String(), s.board.debug())
 	t.Logf("%s\n%s", s.puzzle.String(), s.puzzle.debug())
 	t.Logf("count of hints = %d", s.puzzle.CountHints())
@@ -58,9 +58,9 @@ func TestSeed(t *testing.T) {
 		rand.Int63(),
 	}
 	for _, seed := range seeds {
-		board := NewSudoku(seed).board.String()
+		board := NewSudoku(seed, data.SudokuLevelMedium).Board().String()
 		for i := 0; i < 10000; i++ {
-			if NewSudoku(seed).board.String() != board {
+			if NewSudoku(seed, data.SudokuLevelMedium).Board().String() != board {
 				t.Errorf("seed generate various puzzles")
 				continue
 			}
@@ -74,11 +74,11 @@ func TestUnique(t *testing.T) {
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	unique := make(map[string]int64)
 	for i := int64(0); i < 1000000; i++ {
-		s := NewSudoku(i)
-		if seed, exists := unique[s.board.String()]; exists {
+		s := NewSudoku(i, data.SudokuLevelMedium)
+		if seed, exists := unique[s.Board().String()]; exists {
 			t.Errorf("seeds %d and %d generate same boards", seed, i)
 			continue
 		}
-		unique[s.board.String()] = i
+		unique[s.Board().String()] = i
 	}
 }
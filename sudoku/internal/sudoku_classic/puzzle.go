@@ -1,6 +1,7 @@
 package sudoku_classic
 
 import (
+	"context"
 	"github.com/cnblvr/sudoku/data"
 )
 
@@ -31,12 +32,28 @@ func sudokuPuzzleFromString(str string) sudokuPuzzle {
 }
 
 func (p sudokuPuzzle) solveBruteForce(breakOn int) []sudokuPuzzle {
+	solutions, _ := p.solveBruteForceContext(context.Background(), breakOn)
+	return solutions
+}
+
+// solveBruteForceContext finds up to breakOn solutions of the puzzle. The search is stopped with the error of the
+// context when the context is done.
+func (p sudokuPuzzle) solveBruteForceContext(ctx context.Context, breakOn int) ([]sudokuPuzzle, error) {
 	var solutions []sudokuPuzzle
+	var err error
+	nodes := 0
 	var recursion func(p sudokuPuzzle)
 	recursion = func(p sudokuPuzzle) {
-		if breakOn > 0 && breakOn <= len(solutions) {
+		if err != nil || breakOn > 0 && breakOn <= len(solutions) {
 			return
 		}
+		// the context is checked periodically, because the check is slower than the step of the search
+		if nodes%1024 == 0 {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+		}
+		nodes++
 		candidates := p.findCandidates()
 		for row := 0; row < 9; row++ {
 			for col := 0; col < 9; col++ {
@@ -62,7 +79,7 @@ func (p sudokuPuzzle) solveBruteForce(breakOn int) []sudokuPuzzle {
 	puzzle := p.clone()
 	recursion(puzzle)
 
-	return solutions
+	return solutions, err
 }
 
 func (p sudokuPuzzle) forEach(fn func(p data.Point, v int8, _break *bool), excludeCols ...int) {
@@ -91,7 +108,7 @@ func (p sudokuPuzzle) forEachInRow(row int, fn func(p data.Point, v int8, _break
 		if _, isExcluded := excludes[col]; isExcluded {
 			continue
 		}
-		fn(data.Point{row, col}, p[row][col], &_break)
+		fn(data.Point{Row: row, Col: col}, p[row][col], &_break)
 	}
 }
 
@@ -105,7 +122,7 @@ func (p sudokuPuzzle) forEachInCol(col int, fn func(p data.Point, v int8, _break
 		if _, isExcluded := excludes[row]; isExcluded {
 			continue
 		}
-		fn(data.Point{row, col}, p[row][col], &_break)
+		fn(data.Point{Row: row, Col: col}, p[row][col], &_break)
 	}
 }
 
@@ -115,10 +132,10 @@ func (p sudokuPuzzle) forEachInBox(point data.Point, fn func(p data.Point, v int
 		excludes[e] = struct{}{}
 	}
 	_break := false
-	pBox := data.Point{(point.Row / 3) * 3, (point.Col / 3) * 3}
+	pBox := data.Point{Row: (point.Row / 3) * 3, Col: (point.Col / 3) * 3}
 	for row := 0; !_break && row < 3; row++ {
 		for col := 0; !_break && col < 3; col++ {
-			pCurrent := data.Point{pBox.Row + row, pBox.Col + col}
+			pCurrent := data.Point{Row: pBox.Row + row, Col: pBox.Col + col}
 			if _, isExcluded := excludes[pCurrent]; isExcluded {
 				continue
 			}
@@ -152,6 +169,11 @@ func (p sudokuPuzzle) findCandidates() sudokuCandidates {
 	return c
 }
 
+// IsCorrectSolve checks that all cells of the puzzle are filled in and the rules of the game are not violated.
+func (p sudokuPuzzle) IsCorrectSolve() bool {
+	return p.isCorrectSolve()
+}
+
 func (p sudokuPuzzle) isCorrectSolve() bool {
 	isCorrect := true
 	p.forEach(func(p1 data.Point, v1 int8, _break1 *bool) {
@@ -257,6 +279,20 @@ func (p sudokuPuzzle) FindErrors(target data.SudokuPuzzle) (listErrors []data.Po
 	return
 }
 
+// FindChangedHints returns the points where the user's state does not keep the hints of the original puzzle.
+func (p sudokuPuzzle) FindChangedHints(hints data.SudokuPuzzle) (listChanged []data.Point) {
+	p.forEach(func(point data.Point, value int8, _ *bool) {
+		hint := hints.In(point)
+		if hint == 0 {
+			return
+		}
+		if value != hint {
+			listChanged = append(listChanged, point)
+		}
+	})
+	return
+}
+
 func (p sudokuPuzzle) In(point data.Point) int8 {
 	return p[point.Row][point.Col]
 }
@@ -2,6 +2,8 @@ package sudoku_classic
 
 import (
 	"encoding/json"
+	"github.com/cnblvr/sudoku/data"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -136,6 +138,16 @@ func Test_sudokuPuzzle_isSolve(t *testing.T) {
 			p:    "400000938032094100095300240370609004529001673604703090957008300003900400240030709",
 			want: false,
 		},
+		{
+			name: "#2",
+			p:    "672145398145983672389762451263574819958621743714398526597236184426817935831459267",
+			want: true,
+		},
+		{
+			name: "#3",
+			p:    "672145398145983672389762451263574819958621743714398526597236184426817935831459276",
+			want: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -145,3 +157,39 @@ func Test_sudokuPuzzle_isSolve(t *testing.T) {
 		})
 	}
 }
+
+func Test_sudokuPuzzle_FindChangedHints(t *testing.T) {
+	const hints = "...1.5...14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9..."
+	tests := []struct {
+		name  string
+		state string
+		want  []data.Point
+	}{
+		{
+			name:  "same",
+			state: hints,
+		},
+		{
+			name:  "solved",
+			state: "672145398145983672389762451263574819958621743714398526597236184426817935831459267",
+		},
+		{
+			name:  "changed",
+			state: "...2.5...14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9..1",
+			want:  []data.Point{{Row: 0, Col: 3}},
+		},
+		{
+			name:  "removed",
+			state: "...1.5....4....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9...",
+			want:  []data.Point{{Row: 1, Col: 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sudokuPuzzleFromString(tt.state).FindChangedHints(sudokuPuzzleFromString(hints))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindChangedHints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
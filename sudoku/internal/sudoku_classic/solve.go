@@ -0,0 +1,140 @@
+package sudoku_classic
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+)
+
+// Technique is a method of finding the digit of the cell.
+type Technique string
+
+const (
+	// The cell has only one candidate.
+	TechniqueNakedSingle Technique = "naked_single"
+	// The candidate is possible only in one cell of the row, column or box.
+	TechniqueHiddenSingle Technique = "hidden_single"
+	// The digit is taken from the solution when logical techniques do not find digits.
+	TechniqueGuess Technique = "guess"
+)
+
+// SolveStep is the digit placed in the cell during the solution.
+type SolveStep struct {
+	Point     data.Point
+	Digit     int8
+	Technique Technique
+}
+
+// Solve returns the only solution of the puzzle and steps of the solution as a human would solve it: the simplest
+// technique is used on each step. Returns an error if the puzzle has no solutions or has many solutions.
+func Solve(puzzle data.SudokuPuzzle) (data.SudokuPuzzle, []SolveStep, error) {
+	return SolveContext(context.Background(), puzzle)
+}
+
+// SolveContext is Solve that stops the search of solutions when the context is done. Puzzles with few digits can take
+// a long search.
+func SolveContext(ctx context.Context, puzzle data.SudokuPuzzle) (data.SudokuPuzzle, []SolveStep, error) {
+	p := sudokuPuzzleFromString(puzzle.String())
+	if len(p.FindUserErrors()) > 0 {
+		return nil, nil, fmt.Errorf("puzzle breaks the rules")
+	}
+	solutions, err := p.solveBruteForceContext(ctx, 2)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch len(solutions) {
+	case 0:
+		return nil, nil, fmt.Errorf("puzzle has no solutions")
+	case 1:
+	default:
+		return nil, nil, fmt.Errorf("puzzle has many solutions")
+	}
+	solution := solutions[0]
+
+	var steps []SolveStep
+	for {
+		step, ok := p.nextSolveStep(solution)
+		if !ok {
+			break
+		}
+		p[step.Point.Row][step.Point.Col] = step.Digit
+		steps = append(steps, step)
+	}
+	return solution, steps, nil
+}
+
+// sudokuUnits are rows, columns and boxes of the puzzle.
+var sudokuUnits = func() (units [27][9]data.Point) {
+	for i := 0; i < 9; i++ {
+		for j := 0; j < 9; j++ {
+			units[i][j] = data.Point{Row: i, Col: j}
+			units[9+i][j] = data.Point{Row: j, Col: i}
+			units[18+i][j] = data.Point{Row: i/3*3 + j/3, Col: i%3*3 + j%3}
+		}
+	}
+	return
+}()
+
+// nextSolveStep returns the step by the simplest technique. Returns false if the puzzle is solved.
+func (p sudokuPuzzle) nextSolveStep(solution sudokuPuzzle) (SolveStep, bool) {
+	candidates := p.findCandidates()
+	guess, guessCandidates := SolveStep{}, 10
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			point := data.Point{Row: row, Col: col}
+			if p.In(point) != 0 {
+				continue
+			}
+			cellCandidates := candidates.in(point)
+			if len(cellCandidates) == 1 {
+				return SolveStep{Point: point, Digit: cellCandidates[0], Technique: TechniqueNakedSingle}, true
+			}
+			// the guess is made in the cell with the fewest candidates
+			if len(cellCandidates) < guessCandidates {
+				guess = SolveStep{Point: point, Digit: solution.In(point), Technique: TechniqueGuess}
+				guessCandidates = len(cellCandidates)
+			}
+		}
+	}
+	if guessCandidates == 10 {
+		return SolveStep{}, false
+	}
+	for _, unit := range sudokuUnits {
+		var cells [10][]data.Point
+		for _, point := range unit {
+			for _, c := range candidates.in(point) {
+				cells[c] = append(cells[c], point)
+			}
+		}
+		for digit := int8(1); digit <= 9; digit++ {
+			if len(cells[digit]) == 1 {
+				return SolveStep{Point: cells[digit][0], Digit: digit, Technique: TechniqueHiddenSingle}, true
+			}
+		}
+	}
+	return guess, true
+}
+
+// Grade returns the difficulty of the puzzle by the hardest technique required for the solution: naked singles are
+// enough for the easy puzzle, the medium one requires hidden singles, the hard one cannot be solved without guesses.
+func Grade(puzzle data.SudokuPuzzle) (data.SudokuLevel, []SolveStep, error) {
+	return GradeContext(context.Background(), puzzle)
+}
+
+// GradeContext is Grade that stops the search of solutions when the context is done.
+func GradeContext(ctx context.Context, puzzle data.SudokuPuzzle) (data.SudokuLevel, []SolveStep, error) {
+	_, steps, err := SolveContext(ctx, puzzle)
+	if err != nil {
+		return "", nil, err
+	}
+	level := data.SudokuLevelEasy
+	for _, step := range steps {
+		switch step.Technique {
+		case TechniqueHiddenSingle:
+			level = data.SudokuLevelMedium
+		case TechniqueGuess:
+			return data.SudokuLevelHard, steps, nil
+		}
+	}
+	return level, steps, nil
+}
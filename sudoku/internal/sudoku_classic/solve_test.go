@@ -0,0 +1,86 @@
+package sudoku_classic
+
+import (
+	"context"
+	"github.com/cnblvr/sudoku/data"
+	"testing"
+)
+
+func TestSolve(t *testing.T) {
+	tests := []struct {
+		name      string
+		puzzle    string
+		want      string
+		wantLevel data.SudokuLevel
+		wantErr   bool
+	}{
+		{
+			name:      "naked singles",
+			puzzle:    "...1.5...14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9...",
+			want:      "672145398145983672389762451263574819958621743714398526597236184426817935831459267",
+			wantLevel: data.SudokuLevelEasy,
+		},
+		{
+			name:      "hidden singles",
+			puzzle:    ".........14....67..8...24...63.7..1.9.......3.1..9.52...72...8..26....35...4.9...",
+			want:      "672145398145983672389762451263574819958621743714398526597236184426817935831459267",
+			wantLevel: data.SudokuLevelMedium,
+		},
+		{
+			name:      "guesses",
+			puzzle:    "400000938032094100095300240370609004529001673604703090957008300003900400240030709",
+			want:      "461572938732894156895316247378629514529481673614753892957248361183967425246135789",
+			wantLevel: data.SudokuLevelHard,
+		},
+		{
+			name:    "many solutions",
+			puzzle:  ".................................................................................",
+			wantErr: true,
+		},
+		{
+			name:    "rules broken",
+			puzzle:  "11...............................................................................",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, steps, err := Solve(PuzzleFromString(tt.puzzle))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Solve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.String() != tt.want {
+				t.Errorf("Solve() = %s, want %s", got, tt.want)
+			}
+			state := sudokuPuzzleFromString(tt.puzzle)
+			for _, step := range steps {
+				state[step.Point.Row][step.Point.Col] = step.Digit
+			}
+			if state.String() != tt.want {
+				t.Errorf("steps lead to %s, want %s", state, tt.want)
+			}
+			level, _, err := Grade(PuzzleFromString(tt.puzzle))
+			if err != nil {
+				t.Fatalf("Grade() error = %v", err)
+			}
+			if level != tt.wantLevel {
+				t.Errorf("Grade() = %s, want %s", level, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestSolveContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	puzzle := PuzzleFromString("400000938032094100095300240370609004529001673604703090957008300003900400240030709")
+	if _, _, err := SolveContext(ctx, puzzle); err != context.Canceled {
+		t.Errorf("SolveContext() error = %v, want %v", err, context.Canceled)
+	}
+	if _, _, err := GradeContext(ctx, puzzle); err != context.Canceled {
+		t.Errorf("GradeContext() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -26,9 +26,16 @@ func (s Sudoku) Puzzle() data.SudokuPuzzle {
 	return s.puzzle
 }
 
-// NewSudoku creates a new puzzle and removes some hints depending on the level.
-// seed is used to create a unique puzzle.
-func NewSudoku(seed int64) data.Sudoku {
+// levelRemoves is the maximum number of hints removed from the board in one pass depending on the level.
+var levelRemoves = map[data.SudokuLevel]int{
+	data.SudokuLevelEasy:   36,
+	data.SudokuLevelMedium: 46,
+	data.SudokuLevelHard:   56,
+}
+
+// newSudokuV1 is the first version of the generator. It creates a new puzzle and removes some hints depending on
+// the level. seed is used to create a unique puzzle.
+func newSudokuV1(seed int64, level data.SudokuLevel) data.Sudoku {
 	s := Sudoku{}
 	s.seed = seed
 	// randomizer for full puzzle generation
@@ -68,13 +75,17 @@ func NewSudoku(seed int64) data.Sudoku {
 		copy(s.puzzle[row], s.board[row])
 	}
 
+	maxRemoves, ok := levelRemoves[level]
+	if !ok {
+		maxRemoves = levelRemoves[data.SudokuLevelMedium]
+	}
 	removes := 81
 mainFor:
 	for removes > 0 {
 		removes = 0
 		for _, p := range sudokuRandomPoints(rnd) {
-			if removes >= 46 {
-				break mainFor // todo level
+			if removes >= maxRemoves {
+				break mainFor
 			}
 			digit := s.puzzle[p.Row][p.Col]
 			if digit == 0 {
@@ -216,7 +227,7 @@ func sudokuRandomPoints(rnd *rand.Rand) []data.Point {
 	var points []data.Point
 	for row := 0; row < 9; row++ {
 		for col := 0; col < 9; col++ {
-			points = append(points, data.Point{row, col})
+			points = append(points, data.Point{Row: row, Col: col})
 		}
 	}
 	rnd.Shuffle(len(points), func(i, j int) {
@@ -0,0 +1,323 @@
+package sudoku
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/gomodule/redigo/redis"
+	"sync"
+	"time"
+)
+
+func init() {
+	websocketEvents.Add((*websocketSessionMovesEvent)(nil))
+}
+
+// sessionTopic returns the websocket topic of the session participants.
+func sessionTopic(sessionID string) string {
+	return fmt.Sprintf("session:%s", sessionID)
+}
+
+// anonymousSessions are IDs of anonymous sessions created in the client's browser. The set is shared by requests of
+// the client, the REST API saves new sessions in the cookie.
+type anonymousSessions struct {
+	mx      sync.Mutex
+	ids     []string
+	changed bool
+}
+
+func newAnonymousSessions(ids []string) *anonymousSessions {
+	return &anonymousSessions{
+		ids: append([]string(nil), ids...),
+	}
+}
+
+func (s *anonymousSessions) has(sessionID string) bool {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	for _, id := range s.ids {
+		if id == sessionID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *anonymousSessions) add(sessionID string) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.ids = append(s.ids, sessionID)
+	s.changed = true
+}
+
+// list returns IDs of the sessions. Returns true if sessions have been added.
+func (s *anonymousSessions) list() ([]string, bool) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return append([]string(nil), s.ids...), s.changed
+}
+
+// authorizeSudokuSession returns an error if the client is not allowed to play the session. The session with an
+// owner is played only by the owner, the anonymous session - by the browser that created it. Players of the co-op game
+// are invited by the link and play the session after joining it on the websocket connection.
+func authorizeSudokuSession(ctx context.Context, session model.SudokuSession) error {
+	if conn, ok := ctx.Value("conn").(*websocketConn); ok {
+		if _, ok := conn.coopPlayer(session.ID().String()); ok {
+			return nil
+		}
+	}
+	owner, err := session.User()
+	if err != nil {
+		return errWebsocketInternal
+	}
+	if owner.IsNull() {
+		if ctx.Value("anonymousSessions").(*anonymousSessions).has(session.ID().String()) {
+			return nil
+		}
+	} else if user := ctx.Value("user").(*model.User); !user.IsNull() && user.ID() == owner.ID() {
+		return nil
+	}
+	return newWebsocketError(websocketErrorUnauthorized, "session belongs to another player")
+}
+
+// authorizeWatchSudokuSession returns an error if the client is not allowed to watch the session. Players watch their
+// sessions, spectators need the token of the link shared by players. Finished games are public like their replays.
+// Returned errors can be sent to the client.
+func authorizeWatchSudokuSession(ctx context.Context, session model.SudokuSession, token string) error {
+	status, err := session.Status()
+	if err != nil {
+		return errWebsocketInternal
+	}
+	if status == data.SudokuSessionSolved {
+		return nil
+	}
+	if token != "" {
+		watchToken, err := session.WatchToken()
+		if err != nil {
+			return errWebsocketInternal
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(watchToken)) == 1 {
+			return nil
+		}
+	}
+	return authorizeSudokuSession(ctx, session)
+}
+
+// authorizeCoopSudokuSession returns an error if the client is not allowed to join the cooperative session. The owner
+// joins the session, other players need the token of the invitation link. Returned errors can be sent to the client.
+func authorizeCoopSudokuSession(ctx context.Context, session model.SudokuSession, token string) error {
+	if token != "" {
+		coopToken, err := session.CoopToken()
+		if err != nil {
+			return errWebsocketInternal
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(coopToken)) == 1 {
+			return nil
+		}
+	}
+	return authorizeSudokuSession(ctx, session)
+}
+
+// activeSudokuSession returns the started and not solved session of the player, registering the user's activity in
+// the session timer. Returned errors can be sent to the client.
+func activeSudokuSession(ctx context.Context, redis redis.Conn, sessionID string, now time.Time) (model.SudokuSession, data.SudokuSessionTimer, error) {
+	session, err := model.SudokuSessionByIDString(redis, sessionID)
+	if err != nil {
+		return model.SudokuSession{}, data.SudokuSessionTimer{}, errWebsocketInternal
+	}
+	if session.IsNull() {
+		return model.SudokuSession{}, data.SudokuSessionTimer{}, newWebsocketError(websocketErrorNotFound, "session not found")
+	}
+	if err := authorizeSudokuSession(ctx, session); err != nil {
+		return model.SudokuSession{}, data.SudokuSessionTimer{}, err
+	}
+	status, err := session.Status()
+	if err != nil {
+		return model.SudokuSession{}, data.SudokuSessionTimer{}, errWebsocketInternal
+	}
+	if status == data.SudokuSessionSolved {
+		return model.SudokuSession{}, data.SudokuSessionTimer{}, newWebsocketError(websocketErrorValidation, "sudoku already solved")
+	}
+	timer, err := session.Timer()
+	if err != nil {
+		return model.SudokuSession{}, data.SudokuSessionTimer{}, errWebsocketInternal
+	}
+	if !timer.IsStarted() {
+		return model.SudokuSession{}, data.SudokuSessionTimer{}, newWebsocketError(websocketErrorValidation, "game not started")
+	}
+	timer.Touch(now, sudokuIdleTimeout)
+	if err := session.SetTimer(timer); err != nil {
+		return model.SudokuSession{}, data.SudokuSessionTimer{}, errWebsocketInternal
+	}
+	return session, timer, nil
+}
+
+// errSudokuSessionSolved is returned by finishSudokuSession if the session has already been solved by a parallel
+// request. The error can be sent to the client.
+var errSudokuSessionSolved = newWebsocketError(websocketErrorValidation, "sudoku already solved")
+
+// finishSudokuSession marks the session as solved, calculates the score of the game and saves it in the history of
+// the session owner. The first solved session of a race becomes the winner. Subscribers of the leaderboards and the
+// owner's connections are notified about the new points. The score and the daily leaderboard use the ranked time that
+// counts pauses of the player. The game is scored only once: the request that loses the race to solve the session
+// gets errSudokuSessionSolved.
+func (srv *Service) finishSudokuSession(session model.SudokuSession, now time.Time) (time.Duration, data.SudokuScore, error) {
+	isSolved, err := session.Solve(now)
+	if err != nil {
+		return 0, data.SudokuScore{}, err
+	}
+	if !isSolved {
+		return 0, data.SudokuScore{}, errSudokuSessionSolved
+	}
+	solveTime, err := session.SolveTime()
+	if err != nil {
+		return 0, data.SudokuScore{}, err
+	}
+	timer, err := session.Timer()
+	if err != nil {
+		return 0, data.SudokuScore{}, err
+	}
+	rankedTime := timer.RankedTime(now, sudokuIdleTimeout)
+	level, err := session.Sudoku().Level()
+	if err != nil {
+		return 0, data.SudokuScore{}, err
+	}
+	stats, err := session.Stats()
+	if err != nil {
+		return 0, data.SudokuScore{}, err
+	}
+	score := data.NewSudokuScore(level, rankedTime, stats)
+	if err := session.SetScore(score); err != nil {
+		return 0, data.SudokuScore{}, err
+	}
+	race, err := session.Race()
+	if err != nil {
+		return 0, data.SudokuScore{}, err
+	}
+	if !race.IsNull() {
+		if _, err := race.SetWinner(session); err != nil {
+			return 0, data.SudokuScore{}, err
+		}
+	}
+	user, err := session.User()
+	if err != nil {
+		return 0, data.SudokuScore{}, err
+	}
+	if !user.IsNull() {
+		if err := user.AddScore(session, score); err != nil {
+			return 0, data.SudokuScore{}, err
+		}
+		for _, period := range data.LeaderboardPeriods {
+			srv.hub.publish(leaderboardTopic(level, period), websocketLeaderboardUpdatedEvent{
+				Level:  level,
+				Period: period,
+				UserID: user.ID(),
+			})
+		}
+		srv.hub.publish(userTopic(user.ID()), websocketUserNotificationEvent{
+			Kind:      "score",
+			SessionID: session.ID().String(),
+			Score:     score.Points,
+		})
+		daily, isDaily, err := session.Daily()
+		if err != nil {
+			return 0, data.SudokuScore{}, err
+		}
+		if isDaily {
+			if err := daily.AddResult(user, rankedTime, stats.Mistakes); err != nil {
+				return 0, data.SudokuScore{}, err
+			}
+		}
+	}
+	return solveTime, score, nil
+}
+
+// countMistakes returns the number of digits that were placed in the new state and do not match the solution.
+func countMistakes(prevState, state data.SudokuPuzzle, board data.SudokuPuzzle) (mistakes int64) {
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			p := data.Point{Row: row, Col: col}
+			v := state.In(p)
+			if v == 0 || v == prevState.In(p) {
+				continue
+			}
+			if v != board.In(p) {
+				mistakes++
+			}
+		}
+	}
+	return
+}
+
+// rejectCoopSudokuSession returns the error for methods that change the whole state and are not available in
+// cooperative sessions. Returned errors can be sent to the client.
+func rejectCoopSudokuSession(session model.SudokuSession) error {
+	isCoop, err := session.IsCoop()
+	if err != nil {
+		return errWebsocketInternal
+	}
+	if isCoop {
+		return newWebsocketError(websocketErrorValidation, "not available in co-op game")
+	}
+	return nil
+}
+
+// rejectRaceSudokuSession returns the error for methods that help the player and are not available in races: the
+// first player to solve the puzzle wins. Returned errors can be sent to the client.
+func rejectRaceSudokuSession(session model.SudokuSession) error {
+	race, err := session.Race()
+	if err != nil {
+		return errWebsocketInternal
+	}
+	if !race.IsNull() {
+		return newWebsocketError(websocketErrorValidation, "not available in race")
+	}
+	return nil
+}
+
+// websocketSessionMovesEvent is sent to spectators of the session after each change of the state.
+type websocketSessionMovesEvent struct {
+	Moves []data.SudokuMove `json:"moves"`
+	Win   bool              `json:"win,omitempty"`
+	Timer websocketTimer    `json:"timer"`
+}
+
+func (websocketSessionMovesEvent) Event() string {
+	return "sessionMoves"
+}
+
+// recordSudokuSessionMoves saves the changes between the states as moves for the replay and sends them to
+// spectators of the session.
+func (srv *Service) recordSudokuSessionMoves(session model.SudokuSession, prevState, state string, now time.Time, timer websocketTimer, win bool) error {
+	var moves []data.SudokuMove
+	for i := 0; i < len(state) && i < len(prevState); i++ {
+		if state[i] == prevState[i] {
+			continue
+		}
+		move := data.SudokuMove{
+			SudokuCell: data.SudokuCell{
+				Point: data.Point{Row: i / 9, Col: i % 9},
+			},
+			At: now,
+		}
+		if '1' <= state[i] && state[i] <= '9' {
+			move.Digit = int8(state[i] - '0')
+		}
+		moves = append(moves, move)
+	}
+	if len(moves) == 0 {
+		return nil
+	}
+	moves, err := session.AddMoves(moves)
+	if err != nil {
+		return err
+	}
+	srv.hub.publish(sessionTopic(session.ID().String()), websocketSessionMovesEvent{
+		Moves: moves,
+		Win:   win,
+		Timer: timer,
+	})
+	return nil
+}
@@ -0,0 +1,50 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"github.com/cnblvr/sudoku/model"
+	"testing"
+)
+
+// TestRaceSudokuSession checks that methods helping the player are not available in races.
+func TestRaceSudokuSession(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	sudoku := testSudoku(t, conn)
+	race, err := model.NewRace(conn, sudoku)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raceSession, err := race.Join(model.User{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStartSudokuSession(t, raceSession)
+	session := testSudokuSession(t, conn, sudoku)
+	ctx := testContext(srv, raceSession.ID().String(), session.ID().String())
+
+	tests := []struct {
+		name      string
+		method    string
+		session   model.SudokuSession
+		wantError websocketErrorCode
+	}{
+		{name: "getHint in race", method: "getHint", session: raceSession, wantError: websocketErrorValidation},
+		{name: "undo in race", method: "undo", session: raceSession, wantError: websocketErrorValidation},
+		{name: "getHint", method: "getHint", session: session},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{"sessionID": tt.session.ID().String()})
+			_, wsErr := websocketRequestExecute(ctx, tt.method, body)
+			var gotError websocketErrorCode
+			if wsErr != nil {
+				gotError = wsErr.Code
+			}
+			if gotError != tt.wantError {
+				t.Errorf("%s() error = %v, want code '%s'", tt.method, wsErr, tt.wantError)
+			}
+		})
+	}
+}
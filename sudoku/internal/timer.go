@@ -0,0 +1,24 @@
+package sudoku
+
+import (
+	"github.com/cnblvr/sudoku/data"
+	"time"
+)
+
+// sudokuIdleTimeout is the time of the user's inactivity after which the game timer is paused automatically.
+const sudokuIdleTimeout = 3 * time.Minute
+
+// websocketTimer is the state of the game timer sent to the client.
+type websocketTimer struct {
+	// Game time in milliseconds.
+	Elapsed int64 `json:"elapsed"`
+	// Flag indicates if the timer is stopped.
+	Paused bool `json:"paused"`
+}
+
+func newWebsocketTimer(timer data.SudokuSessionTimer, now time.Time) websocketTimer {
+	return websocketTimer{
+		Elapsed: timer.Duration(now, sudokuIdleTimeout).Milliseconds(),
+		Paused:  timer.IsPaused(),
+	}
+}
@@ -0,0 +1,115 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/gomodule/redigo/redis"
+	uuid "github.com/satori/go.uuid"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	websocketEvents.Add((*websocketLeaderboardUpdatedEvent)(nil))
+	websocketEvents.Add((*websocketUserNotificationEvent)(nil))
+}
+
+// leaderboardTopic returns the websocket topic of changes in the leaderboard of the difficulty and the period.
+func leaderboardTopic(level data.SudokuLevel, period data.LeaderboardPeriod) string {
+	return fmt.Sprintf("leaderboard:%s:%s", level, period)
+}
+
+// userTopic returns the websocket topic of notifications of the user.
+func userTopic(userID int64) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// checkTopic returns an error if the topic does not exist or the connection is not allowed to subscribe to it. Only
+// players subscribe to the topic of the session in progress, spectators use the method watchSession with the token.
+// Returned errors can be sent to the client.
+func checkTopic(ctx context.Context, redis redis.Conn, topic string) error {
+	auth := ctx.Value("auth").(*data.Auth)
+	kind, name := topic, ""
+	if i := strings.IndexByte(topic, ':'); i >= 0 {
+		kind, name = topic[:i], topic[i+1:]
+	}
+	switch kind {
+	case "session":
+		if _, err := uuid.FromString(name); err != nil {
+			return newWebsocketError(websocketErrorValidation, "sessionID is not UUID")
+		}
+		session, err := model.SudokuSessionByIDString(redis, name)
+		if err != nil {
+			return errWebsocketInternal
+		}
+		if session.IsNull() {
+			return newWebsocketError(websocketErrorNotFound, "session not found")
+		}
+		if err := authorizeWatchSudokuSession(ctx, session, ""); err != nil {
+			return err
+		}
+	case "race":
+		if _, err := uuid.FromString(name); err != nil {
+			return newWebsocketError(websocketErrorValidation, "raceID is not UUID")
+		}
+		race, err := model.RaceByIDString(redis, name)
+		if err != nil {
+			return errWebsocketInternal
+		}
+		if race.IsNull() {
+			return newWebsocketError(websocketErrorNotFound, "race not found")
+		}
+	case "leaderboard":
+		parts := strings.Split(name, ":")
+		if len(parts) != 2 {
+			return newWebsocketError(websocketErrorValidation, "leaderboard topic is invalid")
+		}
+		level, err := data.ParseSudokuLevel(parts[0])
+		if err != nil || parts[0] == "" {
+			return newWebsocketError(websocketErrorValidation, "level is invalid")
+		}
+		period, err := data.ParseLeaderboardPeriod(parts[1])
+		if err != nil || parts[1] == "" {
+			return newWebsocketError(websocketErrorValidation, "period is invalid")
+		}
+		if topic != leaderboardTopic(level, period) {
+			return newWebsocketError(websocketErrorValidation, "leaderboard topic is invalid")
+		}
+	case "user":
+		id, err := strconv.ParseInt(name, 10, 64)
+		if err != nil {
+			return newWebsocketError(websocketErrorValidation, "user ID is invalid")
+		}
+		if !auth.IsAuthorized || auth.ID != id {
+			return newWebsocketError(websocketErrorUnauthorized, "forbidden")
+		}
+	default:
+		return newWebsocketError(websocketErrorValidation, "unknown topic")
+	}
+	return nil
+}
+
+// websocketLeaderboardUpdatedEvent is sent to subscribers of the leaderboard when the user's points are changed.
+type websocketLeaderboardUpdatedEvent struct {
+	Level  data.SudokuLevel       `json:"level"`
+	Period data.LeaderboardPeriod `json:"period"`
+	UserID int64                  `json:"userID"`
+}
+
+func (websocketLeaderboardUpdatedEvent) Event() string {
+	return "leaderboardUpdated"
+}
+
+// websocketUserNotificationEvent is a notification of the user on all their connections.
+type websocketUserNotificationEvent struct {
+	// Kind of notification. "score" is sent when the game is completed.
+	Kind      string `json:"kind"`
+	SessionID string `json:"sessionID,omitempty"`
+	Score     int64  `json:"score,omitempty"`
+}
+
+func (websocketUserNotificationEvent) Event() string {
+	return "userNotification"
+}
@@ -2,46 +2,99 @@ package sudoku
 
 import (
 	"context"
-	"fmt"
 	"reflect"
+	"sort"
 	"sync"
+	"time"
 )
 
 type websocketMessagesPool struct {
 	mx           sync.Mutex
-	requestPool  map[string]websocketRequest
-	responsePool map[string]websocketResponse
+	requestPool  map[string]reflect.Type
+	responsePool map[string]reflect.Type
 }
 
 func (p *websocketMessagesPool) Add(req websocketRequest, resp websocketResponse) {
 	p.mx.Lock()
 	defer p.mx.Unlock()
-	req = reflect.New(reflect.TypeOf(req).Elem()).Interface().(websocketRequest)
-	resp = reflect.New(reflect.TypeOf(resp).Elem()).Interface().(websocketResponse)
-	p.requestPool[req.Method()] = req
-	p.responsePool[resp.Method()] = resp
+	reqType, respType := reflect.TypeOf(req).Elem(), reflect.TypeOf(resp).Elem()
+	req = reflect.New(reqType).Interface().(websocketRequest)
+	resp = reflect.New(respType).Interface().(websocketResponse)
+	p.requestPool[req.Method()] = reqType
+	p.responsePool[resp.Method()] = respType
 }
 
+// GetRequest returns a new object of the request of the method. Requests are executed concurrently, so each of them
+// is unmarshalled into its own object.
 func (p *websocketMessagesPool) GetRequest(method string) (websocketRequest, error) {
 	if method == "" {
-		return nil, fmt.Errorf("method is empty")
+		return nil, newWebsocketError(websocketErrorValidation, "method is empty")
 	}
 	p.mx.Lock()
 	defer p.mx.Unlock()
-	req, ok := p.requestPool[method]
+	reqType, ok := p.requestPool[method]
 	if !ok {
-		return nil, fmt.Errorf("method not allowed")
+		return nil, newWebsocketError(websocketErrorValidation, "unknown method '%s'", method)
 	}
-	return req, nil
+	return reflect.New(reqType).Interface().(websocketRequest), nil
+}
+
+// Methods returns names of all methods in alphabetical order.
+func (p *websocketMessagesPool) Methods() []string {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	methods := make([]string, 0, len(p.requestPool))
+	for method := range p.requestPool {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	return methods
+}
+
+// IsMutating returns true if the request of the method changes saved data.
+func (p *websocketMessagesPool) IsMutating(method string) bool {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	reqType, ok := p.requestPool[method]
+	if !ok {
+		return false
+	}
+	_, ok = reflect.New(reqType).Interface().(websocketMutatingRequest)
+	return ok
+}
+
+// Types returns types of the request and the response of the method.
+func (p *websocketMessagesPool) Types(method string) (reflect.Type, reflect.Type) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return p.requestPool[method], p.responsePool[method]
 }
 
 var websocketPool = websocketMessagesPool{
-	requestPool:  make(map[string]websocketRequest),
-	responsePool: make(map[string]websocketResponse),
+	requestPool:  make(map[string]reflect.Type),
+	responsePool: make(map[string]reflect.Type),
+}
+
+// websocketRequestTimeout is the default time limit of the request execution.
+const websocketRequestTimeout = 10 * time.Second
+
+// websocketTimeoutRequest is a request with its own time limit instead of websocketRequestTimeout.
+type websocketTimeoutRequest interface {
+	Timeout() time.Duration
+}
+
+// websocketMutatingRequest is a request that changes saved data. The change cannot be cancelled, so the request is
+// executed without the time limit and the response always tells the client whether the change has been applied.
+// Requests of the same session are executed one by one.
+type websocketMutatingRequest interface {
+	// SessionKey returns the ID of the changed session or "" if the request does not change an existing session.
+	SessionKey() string
 }
 
 type websocketRequest interface {
 	Method() string
+	// Errors returns codes of errors that the method returns in addition to websocketCommonErrors.
+	Errors() []websocketErrorCode
 	Validate(context.Context) error
 	Execute(context.Context) (websocketResponse, error)
 }
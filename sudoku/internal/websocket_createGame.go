@@ -0,0 +1,97 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"time"
+)
+
+func init() {
+	websocketPool.Add((*websocketCreateGameRequest)(nil), (*websocketCreateGameResponse)(nil))
+}
+
+// websocketCreateGameRequest creates a session of a new puzzle. The session is attached to the logged-in user,
+// sessions of anonymous clients are remembered in the browser.
+type websocketCreateGameRequest struct {
+	Level data.SudokuLevel `json:"level,omitempty"`
+	Mode  string           `json:"mode,omitempty"`
+}
+
+func (websocketCreateGameRequest) Method() string {
+	return "createGame"
+}
+
+func (websocketCreateGameRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorUnauthorized}
+}
+
+// Timeout is longer than websocketRequestTimeout because the puzzle is generated when the bank has no puzzles for the
+// player. The request is not mutating: it does not block other requests of the connection while the puzzle is
+// generated, and the session created after the timeout is only left unplayed.
+func (websocketCreateGameRequest) Timeout() time.Duration {
+	return 30 * time.Second
+}
+
+func (r websocketCreateGameRequest) Validate(ctx context.Context) error {
+	if _, err := data.ParseSudokuLevel(string(r.Level)); err != nil {
+		return fmt.Errorf("level is invalid")
+	}
+	if r.Mode != "" && r.Mode != sudokuModeCoop {
+		return fmt.Errorf("mode is invalid")
+	}
+	return nil
+}
+
+// Execute creates the game of the player. Anonymous games are kept by the cookie of the browser, so they are created
+// by the REST API only: the websocket connection cannot save the new game in the cookie.
+func (r websocketCreateGameRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	user := ctx.Value("user").(*model.User)
+	if _, isWebsocket := ctx.Value("conn").(*websocketConn); isWebsocket && user.IsNull() {
+		return websocketCreateGameResponse{}, newWebsocketError(websocketErrorUnauthorized, "anonymous games are created by the REST API")
+	}
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	level, _ := data.ParseSudokuLevel(string(r.Level))
+	sudoku, err := srv.createGameSudoku(redis, level, *user)
+	if err != nil {
+		return websocketCreateGameResponse{}, errWebsocketInternal
+	}
+	session, err := model.NewSudokuSession(redis, sudoku, *user)
+	if err != nil {
+		return websocketCreateGameResponse{}, errWebsocketInternal
+	}
+	if user.IsNull() {
+		ctx.Value("anonymousSessions").(*anonymousSessions).add(session.ID().String())
+	}
+	if r.Mode == sudokuModeCoop {
+		if err := session.SetCoop(); err != nil {
+			return websocketCreateGameResponse{}, errWebsocketInternal
+		}
+	}
+
+	return websocketCreateGameResponse{
+		SessionID: session.ID().String(),
+		Level:     level,
+	}, nil
+}
+
+type websocketCreateGameResponse struct {
+	SessionID string           `json:"sessionID"`
+	Level     data.SudokuLevel `json:"level"`
+}
+
+func (websocketCreateGameResponse) Method() string {
+	return "createGame"
+}
+
+func (r websocketCreateGameResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketCreateGameResponse) Execute(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,77 @@
+package sudoku
+
+import (
+	"fmt"
+	"github.com/rs/zerolog/log"
+)
+
+// websocketErrorCode is a stable code of the error of the request. Clients handle errors by the code, the message is
+// only for humans.
+type websocketErrorCode string
+
+const (
+	// The request is malformed or cannot be performed in the current state of the game.
+	websocketErrorValidation websocketErrorCode = "validation"
+	// The session, race or another object of the request does not exist.
+	websocketErrorNotFound websocketErrorCode = "not_found"
+	// The client is not allowed to perform the request.
+	websocketErrorUnauthorized websocketErrorCode = "unauthorized"
+	// The request failed on the server side.
+	websocketErrorInternal websocketErrorCode = "internal"
+	// The client sends requests too often.
+	websocketErrorRateLimited websocketErrorCode = "rate_limited"
+	// The request has not been completed in time.
+	websocketErrorTimeout websocketErrorCode = "timeout"
+)
+
+// websocketCommonErrors are codes that any method can return. Methods declare other codes in Errors.
+var websocketCommonErrors = []websocketErrorCode{
+	websocketErrorValidation,
+	websocketErrorInternal,
+	websocketErrorRateLimited,
+	websocketErrorTimeout,
+}
+
+// websocketError is the error of the request sent to the client.
+type websocketError struct {
+	Code    websocketErrorCode `json:"code"`
+	Message string             `json:"message"`
+}
+
+func newWebsocketError(code websocketErrorCode, format string, args ...interface{}) *websocketError {
+	return &websocketError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *websocketError) Error() string {
+	return e.Message
+}
+
+var errWebsocketInternal = newWebsocketError(websocketErrorInternal, "internal server error")
+
+// toWebsocketError returns the error of the request. Errors of Validate without the code are validation errors,
+// errors of Execute without the code are internal. An error with a code that the method has not declared is
+// internal too.
+func toWebsocketError(req websocketRequest, err error, fallback websocketErrorCode) *websocketError {
+	wsErr, ok := err.(*websocketError)
+	if !ok {
+		if fallback == websocketErrorInternal {
+			return errWebsocketInternal
+		}
+		return newWebsocketError(fallback, "%s", err.Error())
+	}
+	for _, code := range websocketCommonErrors {
+		if wsErr.Code == code {
+			return wsErr
+		}
+	}
+	for _, code := range req.Errors() {
+		if wsErr.Code == code {
+			return wsErr
+		}
+	}
+	log.Warn().Str("method", req.Method()).Str("code", string(wsErr.Code)).Msg("error code is not declared by method")
+	return errWebsocketInternal
+}
@@ -0,0 +1,72 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// websocketEvent is a message initiated by the server. Events are sent with the field 'event' instead of 'method'
+// and have no echo.
+type websocketEvent interface {
+	Event() string
+}
+
+type websocketEventsPool struct {
+	mx     sync.Mutex
+	events map[string]reflect.Type
+}
+
+// Add registers the type of the event payload.
+func (p *websocketEventsPool) Add(event websocketEvent) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	typ := reflect.TypeOf(event).Elem()
+	event = reflect.New(typ).Interface().(websocketEvent)
+	p.events[event.Event()] = typ
+}
+
+// Has reports whether the event is registered.
+func (p *websocketEventsPool) Has(name string) bool {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	_, ok := p.events[name]
+	return ok
+}
+
+// Events returns names and types of payloads of all events in alphabetical order of names.
+func (p *websocketEventsPool) Events() ([]string, []reflect.Type) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	names := make([]string, 0, len(p.events))
+	for name := range p.events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	types := make([]reflect.Type, 0, len(names))
+	for _, name := range names {
+		types = append(types, p.events[name])
+	}
+	return names, types
+}
+
+var websocketEvents = websocketEventsPool{
+	events: make(map[string]reflect.Type),
+}
+
+// newWebsocketEventMessage returns the message of the registered event.
+func newWebsocketEventMessage(event websocketEvent) (websocketMessage, error) {
+	if !websocketEvents.Has(event.Event()) {
+		return websocketMessage{}, fmt.Errorf("event '%s' is not registered", event.Event())
+	}
+	bodyBts, err := json.Marshal(event)
+	if err != nil {
+		return websocketMessage{}, err
+	}
+	return websocketMessage{
+		Event: event.Event(),
+		Body:  bodyBts,
+	}, nil
+}
@@ -0,0 +1,147 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/sudoku/internal/sudoku_classic"
+	uuid "github.com/satori/go.uuid"
+	"math/rand"
+	"time"
+)
+
+func init() {
+	websocketPool.Add((*websocketGetHintRequest)(nil), (*websocketGetHintResponse)(nil))
+}
+
+type websocketGetHintRequest struct {
+	SessionID string `json:"sessionID"`
+}
+
+func (websocketGetHintRequest) Method() string {
+	return "getHint"
+}
+
+func (websocketGetHintRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketGetHintRequest) SessionKey() string {
+	return r.SessionID
+}
+
+func (r websocketGetHintRequest) Validate(ctx context.Context) error {
+	if r.SessionID == "" {
+		return fmt.Errorf("sessionID is empty")
+	}
+	if _, err := uuid.FromString(r.SessionID); err != nil {
+		return fmt.Errorf("sessionID is not UUID")
+	}
+	return nil
+}
+
+// Execute reveals one empty or wrong cell of the saved state.
+func (r websocketGetHintRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	redis := srv.redis.Get()
+	defer redis.Close()
+	now := time.Now()
+
+	session, timer, err := activeSudokuSession(ctx, redis, r.SessionID, now)
+	if err != nil {
+		return websocketGetHintResponse{}, err
+	}
+	if err := rejectCoopSudokuSession(session); err != nil {
+		return websocketGetHintResponse{}, err
+	}
+	if err := rejectRaceSudokuSession(session); err != nil {
+		return websocketGetHintResponse{}, err
+	}
+	stateStr, err := session.State()
+	if err != nil {
+		return websocketGetHintResponse{}, errWebsocketInternal
+	}
+	if stateStr == "" {
+		if stateStr, err = session.Sudoku().Puzzle(); err != nil {
+			return websocketGetHintResponse{}, errWebsocketInternal
+		}
+	}
+	boardStr, err := session.Sudoku().Board()
+	if err != nil {
+		return websocketGetHintResponse{}, errWebsocketInternal
+	}
+	state, board := sudoku_classic.PuzzleFromString(stateStr), sudoku_classic.PuzzleFromString(boardStr)
+
+	var points []data.Point
+	for row := 0; row < 9; row++ {
+		for col := 0; col < 9; col++ {
+			p := data.Point{Row: row, Col: col}
+			if state.In(p) != board.In(p) {
+				points = append(points, p)
+			}
+		}
+	}
+	if len(points) == 0 {
+		return websocketGetHintResponse{}, newWebsocketError(websocketErrorValidation, "no cells for hint")
+	}
+	point := points[rand.Intn(len(points))]
+	digit := board.In(point)
+
+	stateBts := []byte(state.String())
+	stateBts[point.Row*9+point.Col] = '0' + byte(digit)
+	if err := session.SetState(string(stateBts)); err != nil {
+		return websocketGetHintResponse{}, errWebsocketInternal
+	}
+	if err := session.IncrHints(); err != nil {
+		return websocketGetHintResponse{}, errWebsocketInternal
+	}
+
+	resp := websocketGetHintResponse{
+		Point: point,
+		Digit: digit,
+		State: string(stateBts),
+		Timer: newWebsocketTimer(timer, now),
+	}
+	if sudoku_classic.PuzzleFromString(resp.State).IsCorrectSolve() {
+		// WIN
+		solveTime, score, err := srv.finishSudokuSession(session, now)
+		if err == errSudokuSessionSolved {
+			return websocketGetHintResponse{}, err
+		}
+		if err != nil {
+			return websocketGetHintResponse{}, errWebsocketInternal
+		}
+		resp.Win, resp.Time, resp.Score = true, solveTime.Milliseconds(), score.Points
+		resp.Timer = websocketTimer{
+			Elapsed: solveTime.Milliseconds(),
+			Paused:  true,
+		}
+	}
+	if err := srv.recordSudokuSessionMoves(session, stateStr, resp.State, now, resp.Timer, resp.Win); err != nil {
+		return websocketGetHintResponse{}, errWebsocketInternal
+	}
+	srv.broadcastRace(redis, session)
+	return resp, nil
+}
+
+type websocketGetHintResponse struct {
+	Point data.Point     `json:"point"`
+	Digit int8           `json:"digit"`
+	State string         `json:"state"`
+	Win   bool           `json:"win,omitempty"`
+	Time  int64          `json:"time,omitempty"`
+	Score int64          `json:"score,omitempty"`
+	Timer websocketTimer `json:"timer"`
+}
+
+func (websocketGetHintResponse) Method() string {
+	return "getHint"
+}
+
+func (r websocketGetHintResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketGetHintResponse) Execute(ctx context.Context) error {
+	return nil
+}
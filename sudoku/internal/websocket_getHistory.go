@@ -0,0 +1,59 @@
+package sudoku
+
+import (
+	"context"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+)
+
+func init() {
+	websocketPool.Add((*websocketGetHistoryRequest)(nil), (*websocketGetHistoryResponse)(nil))
+}
+
+// websocketGetHistoryRequest returns the games history of the logged-in user from the oldest and statistics.
+type websocketGetHistoryRequest struct{}
+
+func (websocketGetHistoryRequest) Method() string {
+	return "getHistory"
+}
+
+func (websocketGetHistoryRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorUnauthorized}
+}
+
+func (r websocketGetHistoryRequest) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketGetHistoryRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	user := ctx.Value("user").(*model.User)
+	if user.IsNull() {
+		return websocketGetHistoryResponse{}, newWebsocketError(websocketErrorUnauthorized, "log in first")
+	}
+	history, err := user.History()
+	if err != nil {
+		return websocketGetHistoryResponse{}, errWebsocketInternal
+	}
+
+	return websocketGetHistoryResponse{
+		History: history,
+		Stats:   data.NewUserStats(history),
+	}, nil
+}
+
+type websocketGetHistoryResponse struct {
+	History []data.SudokuHistoryItem `json:"history"`
+	Stats   data.UserStats           `json:"stats"`
+}
+
+func (websocketGetHistoryResponse) Method() string {
+	return "getHistory"
+}
+
+func (r websocketGetHistoryResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketGetHistoryResponse) Execute(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,74 @@
+package sudoku
+
+import (
+	"context"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"time"
+)
+
+func init() {
+	websocketPool.Add((*websocketGetProfileRequest)(nil), (*websocketGetProfileResponse)(nil))
+}
+
+// websocketGetProfileRequest returns the profile of the logged-in user.
+type websocketGetProfileRequest struct{}
+
+func (websocketGetProfileRequest) Method() string {
+	return "getProfile"
+}
+
+func (websocketGetProfileRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorUnauthorized}
+}
+
+func (r websocketGetProfileRequest) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketGetProfileRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	user := ctx.Value("user").(*model.User)
+	if user.IsNull() {
+		return websocketGetProfileResponse{}, newWebsocketError(websocketErrorUnauthorized, "log in first")
+	}
+	username, err := user.Username()
+	if err != nil {
+		return websocketGetProfileResponse{}, errWebsocketInternal
+	}
+	info, err := user.UserInfo()
+	if err != nil {
+		return websocketGetProfileResponse{}, errWebsocketInternal
+	}
+	createdAt, err := user.CreatedAt()
+	if err != nil {
+		return websocketGetProfileResponse{}, errWebsocketInternal
+	}
+
+	return websocketGetProfileResponse{
+		ID:        user.ID(),
+		Username:  username,
+		Name:      info.Name,
+		BestScore: info.BestScore,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+type websocketGetProfileResponse struct {
+	ID        int64            `json:"id"`
+	Username  string           `json:"username"`
+	Name      string           `json:"name"`
+	BestScore data.SudokuScore `json:"bestScore"`
+	CreatedAt time.Time        `json:"createdAt"`
+}
+
+func (websocketGetProfileResponse) Method() string {
+	return "getProfile"
+}
+
+func (r websocketGetProfileResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketGetProfileResponse) Execute(ctx context.Context) error {
+	return nil
+}
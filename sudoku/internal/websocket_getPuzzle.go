@@ -3,8 +3,10 @@ package sudoku
 import (
 	"context"
 	"fmt"
+	"github.com/cnblvr/sudoku/data"
 	"github.com/cnblvr/sudoku/model"
 	uuid "github.com/satori/go.uuid"
+	"time"
 )
 
 func init() {
@@ -19,6 +21,15 @@ func (websocketGetPuzzleRequest) Method() string {
 	return "getPuzzle"
 }
 
+func (websocketGetPuzzleRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+// SessionKey serializes the start and the resumption of the timer with other changes of the session.
+func (r websocketGetPuzzleRequest) SessionKey() string {
+	return r.SessionID
+}
+
 func (r websocketGetPuzzleRequest) Validate(ctx context.Context) error {
 	if r.SessionID == "" {
 		return fmt.Errorf("sessionID is empty")
@@ -34,24 +45,62 @@ func (r websocketGetPuzzleRequest) Execute(ctx context.Context) (websocketRespon
 	srv := ctx.Value("srv").(*Service)
 	redis := srv.redis.Get()
 	defer redis.Close()
+	now := time.Now()
 
 	session, err := model.SudokuSessionByIDString(redis, r.SessionID)
 	if err != nil {
-		return websocketGetPuzzleResponse{}, fmt.Errorf("internal server error")
+		return websocketGetPuzzleResponse{}, errWebsocketInternal
+	}
+	if session.IsNull() {
+		return websocketGetPuzzleResponse{}, newWebsocketError(websocketErrorNotFound, "session not found")
+	}
+	// spectators receive the puzzle by watchSession
+	if err := authorizeSudokuSession(ctx, session); err != nil {
+		return websocketGetPuzzleResponse{}, err
 	}
 	puzzle, err := session.Sudoku().Puzzle()
 	if err != nil {
-		return websocketGetPuzzleResponse{}, fmt.Errorf("internal server error")
+		return websocketGetPuzzleResponse{}, errWebsocketInternal
+	}
+	state, err := session.State()
+	if err != nil {
+		return websocketGetPuzzleResponse{}, errWebsocketInternal
+	}
+
+	// the timer starts on the first request of the puzzle and is resumed when the client reconnects. The pause requested
+	// by the player lasts until the player resumes the game.
+	status, err := session.Status()
+	if err != nil {
+		return websocketGetPuzzleResponse{}, errWebsocketInternal
+	}
+	timer, err := session.Timer()
+	if err != nil {
+		return websocketGetPuzzleResponse{}, errWebsocketInternal
+	}
+	if status == data.SudokuSessionInProgress && !timer.IsPausedByPlayer() {
+		timer.Touch(now, sudokuIdleTimeout)
+		if err := session.SetTimer(timer); err != nil {
+			return websocketGetPuzzleResponse{}, errWebsocketInternal
+		}
+	}
+
+	// the player receives the game time and moves of other players and spectators of the session
+	if conn, ok := ctx.Value("conn").(*websocketConn); ok {
+		srv.hub.subscribe(sessionTopic(session.ID().String()), conn)
 	}
 
 	return websocketGetPuzzleResponse{
 		Puzzle: puzzle,
+		State:  state,
+		Timer:  newWebsocketTimer(timer, now),
 	}, nil
 }
 
 // TODO handle and test
 type websocketGetPuzzleResponse struct {
-	Puzzle string `json:"puzzle"`
+	Puzzle string         `json:"puzzle"`
+	State  string         `json:"state,omitempty"`
+	Timer  websocketTimer `json:"timer"`
 }
 
 func (websocketGetPuzzleResponse) Method() string {
@@ -0,0 +1,76 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"github.com/cnblvr/sudoku/data"
+	"testing"
+	"time"
+)
+
+// TestGetPuzzle checks that the puzzle request resumes the timer of the reconnected client, but not the pause
+// requested by the player.
+func TestGetPuzzle(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	sudoku := testSudoku(t, conn)
+
+	tests := []struct {
+		name       string
+		timer      func(now time.Time) data.SudokuSessionTimer
+		wantPaused bool
+	}{
+		{
+			name: "running",
+			timer: func(now time.Time) (timer data.SudokuSessionTimer) {
+				timer.Start(now.Add(-time.Minute))
+				return
+			},
+		},
+		{
+			name: "paused as idle",
+			timer: func(now time.Time) (timer data.SudokuSessionTimer) {
+				timer.Start(now.Add(-time.Hour))
+				timer.PauseIdle()
+				return
+			},
+		},
+		{
+			name: "paused by player",
+			timer: func(now time.Time) (timer data.SudokuSessionTimer) {
+				timer.Start(now.Add(-time.Hour))
+				timer.PauseByPlayer(now.Add(-time.Minute))
+				return
+			},
+			wantPaused: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := testSudokuSession(t, conn, sudoku)
+			if err := session.SetTimer(tt.timer(time.Now())); err != nil {
+				t.Fatal(err)
+			}
+			ctx := testContext(srv, session.ID().String())
+			body, _ := json.Marshal(websocketGetPuzzleRequest{SessionID: session.ID().String()})
+			respBody, wsErr := websocketRequestExecute(ctx, "getPuzzle", body)
+			if wsErr != nil {
+				t.Fatalf("getPuzzle() error = %v", wsErr)
+			}
+			var resp websocketGetPuzzleResponse
+			if err := json.Unmarshal(respBody, &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Timer.Paused != tt.wantPaused {
+				t.Errorf("getPuzzle() paused = %v, want %v", resp.Timer.Paused, tt.wantPaused)
+			}
+			timer, err := session.Timer()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if timer.IsPaused() != tt.wantPaused {
+				t.Errorf("saved timer paused = %v, want %v", timer.IsPaused(), tt.wantPaused)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package sudoku
 
 import (
 	"context"
+	"time"
 )
 
 func init() {
@@ -14,6 +15,15 @@ func (websocketHealthRequest) Method() string {
 	return "health"
 }
 
+func (websocketHealthRequest) Errors() []websocketErrorCode {
+	return nil
+}
+
+// Timeout is short because the health check must not hang when the database is unavailable.
+func (websocketHealthRequest) Timeout() time.Duration {
+	return 3 * time.Second
+}
+
 func (r websocketHealthRequest) Validate(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,456 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	"github.com/gomodule/redigo/redis"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+	uuid "github.com/satori/go.uuid"
+	"strings"
+	"sync"
+	"time"
+)
+
+// websocketSendBuffer is the number of messages queued for sending to the client. The connection of a client that
+// does not read messages is closed when the queue is full.
+const websocketSendBuffer = 64
+
+const (
+	// websocketWriteWait is the time allowed to write a message to the client.
+	websocketWriteWait = 10 * time.Second
+	// websocketPongWait is the time allowed to read the next pong or message from the client.
+	websocketPongWait = 60 * time.Second
+	// websocketPingPeriod is the period of pings to the client. It must be less than websocketPongWait.
+	websocketPingPeriod = websocketPongWait * 9 / 10
+)
+
+// websocketConn is a client connection. Responses to requests and server events are queued and written by the only
+// writer goroutine of the connection, so messages are never interleaved.
+type websocketConn struct {
+	conn *websocket.Conn
+	// Version of the protocol negotiated at connect.
+	version   int
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	limiter   websocketRateLimiter
+	// Connection of database for saving events for resuming. Events are not saved if it is nil.
+	redis *redis.Pool
+	// token identifies the connection for resuming.
+	token uuid.UUID
+
+	seqMx sync.Mutex
+	// seq is the sequence number of the last event of the connection.
+	seq int64
+	// parked is true if the client has dropped the connection. Events of the parked connection are only saved.
+	parked bool
+
+	mx sync.Mutex
+	// players are the joined cooperative sessions of the connection by session ID.
+	players map[string]data.CoopPlayer
+}
+
+// newWebsocketConn wraps the connection and starts its writer goroutine. Events are saved for resuming since the
+// second version of the protocol.
+func newWebsocketConn(conn *websocket.Conn, redis *redis.Pool) *websocketConn {
+	c := &websocketConn{
+		conn:    conn,
+		version: websocketProtocolVersion(conn.Subprotocol()),
+		send:    make(chan []byte, websocketSendBuffer),
+		done:    make(chan struct{}),
+		token:   uuid.NewV4(),
+		players: make(map[string]data.CoopPlayer),
+	}
+	if c.version >= websocketProtocolV2 {
+		c.redis = redis
+	}
+	go c.writeLoop()
+	return c
+}
+
+// writeLoop writes queued frames and pings to the client until the connection is closed.
+func (c *websocketConn) writeLoop() {
+	ping := time.NewTicker(websocketPingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case frame := <-c.send:
+			log.Debug().Msgf("ws send:     %s", frame)
+			_ = c.conn.SetWriteDeadline(time.Now().Add(websocketWriteWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, frame); err != nil {
+				log.Warn().Err(err).Msg("failed to write message")
+				c.close()
+				return
+			}
+		case <-ping.C:
+			_ = c.conn.SetWriteDeadline(time.Now().Add(websocketWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				log.Warn().Err(err).Msg("failed to write ping")
+				c.close()
+				return
+			}
+			// the state of the connection does not expire while the client is connected
+			c.updateResume("state", model.WebsocketResume.Keep)
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// write queues responses to the client. It waits if the queue is full. A batch is written as one frame.
+func (c *websocketConn) write(batch bool, msgs ...websocketMessage) error {
+	frame, err := encodeWebsocketMessages(c.version, batch, msgs...)
+	if err != nil {
+		return err
+	}
+	select {
+	case c.send <- frame:
+		return nil
+	case <-c.done:
+		return fmt.Errorf("connection is closed")
+	}
+}
+
+// push queues the event to the client and saves it for resuming. The connection is closed if the client does not
+// read messages.
+func (c *websocketConn) push(event websocketEvent) error {
+	msg, err := newWebsocketEventMessage(event)
+	if err != nil {
+		return err
+	}
+	saved, err := c.pushMessage(msg)
+	if saved != nil {
+		saveWebsocketEvents(c.redis, []model.WebsocketEvent{*saved})
+	}
+	return err
+}
+
+// pushMessage numbers the event and queues it to the client. The connection is closed if the client does not read
+// messages. Returns the numbered event that the caller saves for resuming, it is nil if events of the connection are
+// not saved.
+func (c *websocketConn) pushMessage(msg websocketMessage) (*model.WebsocketEvent, error) {
+	c.seqMx.Lock()
+	defer c.seqMx.Unlock()
+	if !c.parked {
+		select {
+		case <-c.done:
+			return nil, fmt.Errorf("connection is closed")
+		default:
+		}
+	}
+	c.seq++
+	msg.Seq = c.seq
+	frame, err := encodeWebsocketMessages(c.version, false, msg)
+	if err != nil {
+		return nil, err
+	}
+	var saved *model.WebsocketEvent
+	if c.redis != nil {
+		saved = &model.WebsocketEvent{
+			Token: c.token,
+			Seq:   c.seq,
+			Frame: frame,
+		}
+	}
+	if c.parked {
+		return saved, nil
+	}
+	select {
+	case c.send <- frame:
+		return saved, nil
+	case <-c.done:
+		return saved, fmt.Errorf("connection is closed")
+	default:
+		c.close()
+		return saved, fmt.Errorf("queue of messages is full")
+	}
+}
+
+// saveWebsocketEvents saves events of connections for resuming in one round trip to Redis. Errors are only logged,
+// because events have already been sent to clients.
+func saveWebsocketEvents(pool *redis.Pool, events []model.WebsocketEvent) {
+	if pool == nil || len(events) == 0 {
+		return
+	}
+	redis := pool.Get()
+	defer redis.Close()
+	if err := model.AddWebsocketEvents(redis, events); err != nil {
+		log.Error().Err(err).Msg("failed to save events for resuming")
+	}
+}
+
+// updateResume changes the saved state of the connection for resuming. Errors are only logged, because the
+// connection works without resuming.
+func (c *websocketConn) updateResume(what string, update func(model.WebsocketResume) error) {
+	if c.redis == nil {
+		return
+	}
+	redis := c.redis.Get()
+	defer redis.Close()
+	if err := update(model.WebsocketResumeByToken(redis, c.token)); err != nil {
+		log.Error().Err(err).Msgf("failed to save %s for resuming", what)
+	}
+}
+
+// saveTopic saves or removes the topic of the connection for resuming.
+func (c *websocketConn) saveTopic(topic string, subscribed bool) {
+	c.updateResume("topic", func(resume model.WebsocketResume) error {
+		if subscribed {
+			return resume.AddTopic(topic)
+		}
+		return resume.RemoveTopic(topic)
+	})
+}
+
+// park closes the dropped connection, but its events are still saved until the client resumes the connection.
+// Returns false if the connection cannot be resumed.
+func (c *websocketConn) park() bool {
+	c.close()
+	if c.redis == nil {
+		return false
+	}
+	c.updateResume("state", model.WebsocketResume.Keep)
+	c.seqMx.Lock()
+	defer c.seqMx.Unlock()
+	c.parked = true
+	return true
+}
+
+// close stops the writer goroutine and closes the connection.
+func (c *websocketConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		_ = c.conn.Close()
+	})
+}
+
+// closeWith sends the close message with the code to the client and closes the connection.
+func (c *websocketConn) closeWith(code int, text string) {
+	msg := websocket.FormatCloseMessage(code, text)
+	if err := c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(websocketWriteWait)); err != nil {
+		log.Debug().Err(err).Msg("failed to write close message")
+	}
+	c.close()
+}
+
+// setCoopPlayer remembers the player of the connection in the cooperative session. The player is restored when the
+// connection is resumed.
+func (c *websocketConn) setCoopPlayer(sessionID string, player data.CoopPlayer) {
+	c.mx.Lock()
+	c.players[sessionID] = player
+	c.mx.Unlock()
+	c.updateResume("co-op player", func(resume model.WebsocketResume) error {
+		return resume.SetCoopPlayer(sessionID, player)
+	})
+}
+
+// coopPlayer returns the player of the connection in the cooperative session. Returns false if the connection has not
+// joined the session.
+func (c *websocketConn) coopPlayer(sessionID string) (data.CoopPlayer, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	player, ok := c.players[sessionID]
+	return player, ok
+}
+
+// websocketHub is a set of topics. Each topic is a group of connections that receive the same events. Events are
+// published through Redis Pub/Sub, so they reach subscribers connected to any instance of the service.
+type websocketHub struct {
+	mx     sync.Mutex
+	topics map[string]map[*websocketConn]struct{}
+	// Connection of database for Pub/Sub. Events are only delivered locally if it is nil.
+	redis *redis.Pool
+}
+
+func newWebsocketHub(redis *redis.Pool) *websocketHub {
+	return &websocketHub{
+		topics: make(map[string]map[*websocketConn]struct{}),
+		redis:  redis,
+	}
+}
+
+// subscribe adds the connection to the topic.
+func (h *websocketHub) subscribe(topic string, conn *websocketConn) {
+	h.mx.Lock()
+	conns, ok := h.topics[topic]
+	if !ok {
+		conns = make(map[*websocketConn]struct{})
+		h.topics[topic] = conns
+	}
+	conns[conn] = struct{}{}
+	h.mx.Unlock()
+	conn.saveTopic(topic, true)
+}
+
+// unsubscribe removes the connection from the topic.
+func (h *websocketHub) unsubscribe(topic string, conn *websocketConn) {
+	h.mx.Lock()
+	h.unsubscribeLocked(topic, conn)
+	h.mx.Unlock()
+	conn.saveTopic(topic, false)
+}
+
+// unsubscribeAll removes the connection from all topics. It is called when the connection is closed.
+func (h *websocketHub) unsubscribeAll(conn *websocketConn) {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	for topic := range h.topics {
+		h.unsubscribeLocked(topic, conn)
+	}
+}
+
+func (h *websocketHub) unsubscribeLocked(topic string, conn *websocketConn) {
+	conns, ok := h.topics[topic]
+	if !ok {
+		return
+	}
+	delete(conns, conn)
+	if len(conns) == 0 {
+		delete(h.topics, topic)
+	}
+}
+
+// publish sends the event to subscribers of the topic on all instances of the service. If Redis is not available,
+// the event is delivered to local subscribers only.
+func (h *websocketHub) publish(topic string, event websocketEvent) {
+	msg, err := newWebsocketEventMessage(event)
+	if err != nil {
+		log.Error().Err(err).Str("topic", topic).Msg("failed to create event message")
+		return
+	}
+	if h.redis != nil {
+		err := func() error {
+			msgBts, err := json.Marshal(msg)
+			if err != nil {
+				return err
+			}
+			conn := h.redis.Get()
+			defer conn.Close()
+			_, err = conn.Do("PUBLISH", websocketChannel(topic), msgBts)
+			return err
+		}()
+		if err == nil {
+			return
+		}
+		log.Error().Err(err).Str("topic", topic).Msg("failed to publish event")
+	}
+	h.deliver(topic, msg)
+}
+
+// publishLocal sends the event to subscribers of the topic connected to this instance. It is used for events that
+// each instance generates itself.
+func (h *websocketHub) publishLocal(topic string, event websocketEvent) {
+	msg, err := newWebsocketEventMessage(event)
+	if err != nil {
+		log.Error().Err(err).Str("topic", topic).Msg("failed to create event message")
+		return
+	}
+	h.deliver(topic, msg)
+}
+
+// deliver sends the message to local subscribers of the topic. Events of all subscribers are saved for resuming at
+// once. The connection that has been resumed on another connection is stopped.
+func (h *websocketHub) deliver(topic string, msg websocketMessage) {
+	var pool *redis.Pool
+	var saved []model.WebsocketEvent
+	defer func() {
+		saveWebsocketEvents(pool, saved)
+	}()
+	for _, conn := range h.conns(topic) {
+		event, err := conn.pushMessage(msg)
+		if err != nil {
+			log.Warn().Err(err).Str("topic", topic).Msg("failed to deliver event")
+		}
+		if event != nil {
+			pool = conn.redis
+			saved = append(saved, *event)
+		}
+		if msg.Event == (websocketConnectionResumedEvent{}).Event() {
+			h.unsubscribeAll(conn)
+			conn.close()
+		}
+	}
+}
+
+// park keeps the dropped connection in its topics for model.WebsocketResumeTTL, so events for the client are saved
+// until it resumes the connection.
+func (h *websocketHub) park(conn *websocketConn) {
+	if !conn.park() {
+		h.unsubscribeAll(conn)
+		return
+	}
+	time.AfterFunc(model.WebsocketResumeTTL, func() {
+		h.unsubscribeAll(conn)
+	})
+}
+
+// closeAll closes all local connections with the code. It is used when the server shuts down.
+func (h *websocketHub) closeAll(code int, text string) {
+	for _, conn := range h.conns(websocketTopicAll) {
+		conn.closeWith(code, text)
+	}
+}
+
+// run receives events published by all instances and delivers them to local subscribers. It reconnects to Redis
+// after errors.
+func (h *websocketHub) run() {
+	for {
+		if err := h.receive(); err != nil {
+			log.Error().Err(err).Msg("failed to receive events from Redis Pub/Sub")
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func (h *websocketHub) receive() error {
+	conn := redis.PubSubConn{Conn: h.redis.Get()}
+	defer conn.Close()
+	if err := conn.PSubscribe(websocketChannel("*")); err != nil {
+		return err
+	}
+	for {
+		switch v := conn.Receive().(type) {
+		case redis.Message:
+			var msg websocketMessage
+			if err := json.Unmarshal(v.Data, &msg); err != nil {
+				log.Error().Err(err).Str("channel", v.Channel).Msg("failed to unmarshal event message")
+				continue
+			}
+			h.deliver(strings.TrimPrefix(v.Channel, websocketChannel("")), msg)
+		case error:
+			return v
+		}
+	}
+}
+
+// conns returns local connections of the topic.
+func (h *websocketHub) conns(topic string) []*websocketConn {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	conns := make([]*websocketConn, 0, len(h.topics[topic]))
+	for conn := range h.topics[topic] {
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// topicsWithPrefix returns names of the topics with local subscribers that start with the prefix.
+func (h *websocketHub) topicsWithPrefix(prefix string) []string {
+	h.mx.Lock()
+	defer h.mx.Unlock()
+	var topics []string
+	for topic := range h.topics {
+		if strings.HasPrefix(topic, prefix) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// websocketChannel returns the Redis Pub/Sub channel of the topic.
+func websocketChannel(topic string) string {
+	return fmt.Sprintf("ws:%s", topic)
+}
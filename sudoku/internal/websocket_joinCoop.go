@@ -0,0 +1,136 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	uuid "github.com/satori/go.uuid"
+)
+
+func init() {
+	websocketPool.Add((*websocketJoinCoopRequest)(nil), (*websocketJoinCoopResponse)(nil))
+	websocketEvents.Add((*websocketCoopPlayersEvent)(nil))
+}
+
+type websocketJoinCoopRequest struct {
+	SessionID string `json:"sessionID"`
+	// Token of the invitation link. Not needed for the owner of the session and for players who have joined the
+	// session on this connection.
+	Token string `json:"token,omitempty"`
+}
+
+func (websocketJoinCoopRequest) Method() string {
+	return "joinCoop"
+}
+
+func (websocketJoinCoopRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketJoinCoopRequest) SessionKey() string {
+	return r.SessionID
+}
+
+func (r websocketJoinCoopRequest) Validate(ctx context.Context) error {
+	if r.SessionID == "" {
+		return fmt.Errorf("sessionID is empty")
+	}
+	if _, err := uuid.FromString(r.SessionID); err != nil {
+		return fmt.Errorf("sessionID is not UUID")
+	}
+	return nil
+}
+
+// Execute adds the player to the cooperative session and subscribes the connection to moves and cursors of other
+// players. The anonymous player is bound to the connection: joining again on the same or the resumed connection keeps
+// the player, a new connection joins as a new player.
+func (r websocketJoinCoopRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	conn := ctx.Value("conn").(*websocketConn)
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	session, err := model.SudokuSessionByIDString(redis, r.SessionID)
+	if err != nil {
+		return websocketJoinCoopResponse{}, errWebsocketInternal
+	}
+	if session.IsNull() {
+		return websocketJoinCoopResponse{}, newWebsocketError(websocketErrorNotFound, "session not found")
+	}
+	isCoop, err := session.IsCoop()
+	if err != nil {
+		return websocketJoinCoopResponse{}, errWebsocketInternal
+	}
+	if !isCoop {
+		return websocketJoinCoopResponse{}, newWebsocketError(websocketErrorValidation, "session is not co-op game")
+	}
+	if err := authorizeCoopSudokuSession(ctx, session, r.Token); err != nil {
+		return websocketJoinCoopResponse{}, err
+	}
+
+	var playerID, username string
+	if player, ok := conn.coopPlayer(r.SessionID); ok {
+		playerID = player.ID
+	}
+	if user := ctx.Value("user").(*model.User); !user.IsNull() {
+		playerID = fmt.Sprintf("user:%d", user.ID())
+		if username, err = user.Username(); err != nil {
+			return websocketJoinCoopResponse{}, errWebsocketInternal
+		}
+	}
+	if playerID == "" {
+		playerID = uuid.NewV4().String()
+	}
+	player, err := session.JoinCoop(playerID, username)
+	if err != nil {
+		return websocketJoinCoopResponse{}, errWebsocketInternal
+	}
+	conn.setCoopPlayer(r.SessionID, player)
+	srv.hub.subscribe(sessionTopic(r.SessionID), conn)
+
+	players, err := session.CoopPlayers()
+	if err != nil {
+		return websocketJoinCoopResponse{}, errWebsocketInternal
+	}
+	cells, err := session.Cells()
+	if err != nil {
+		return websocketJoinCoopResponse{}, errWebsocketInternal
+	}
+	srv.hub.publish(sessionTopic(r.SessionID), websocketCoopPlayersEvent{
+		Players: players,
+	})
+
+	return websocketJoinCoopResponse{
+		Player:  player,
+		Players: players,
+		Cells:   cells,
+	}, nil
+}
+
+type websocketJoinCoopResponse struct {
+	Player  data.CoopPlayer   `json:"player"`
+	Players []data.CoopPlayer `json:"players"`
+	Cells   []data.SudokuCell `json:"cells,omitempty"`
+}
+
+func (websocketJoinCoopResponse) Method() string {
+	return "joinCoop"
+}
+
+func (r websocketJoinCoopResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketJoinCoopResponse) Execute(ctx context.Context) error {
+	return nil
+}
+
+// websocketCoopPlayersEvent is sent to all players of the cooperative session when a player joins.
+type websocketCoopPlayersEvent struct {
+	Players []data.CoopPlayer `json:"players"`
+}
+
+func (websocketCoopPlayersEvent) Event() string {
+	return "coopPlayers"
+}
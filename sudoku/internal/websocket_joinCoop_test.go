@@ -0,0 +1,137 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/cnblvr/sudoku/model"
+	"net/http"
+	"testing"
+)
+
+// TestJoinCoop checks that co-op games are joined only by the owner and clients with the token of the invitation link.
+func TestJoinCoop(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	sudoku := testSudoku(t, conn)
+	session := testSudokuSession(t, conn, sudoku)
+	if err := session.SetCoop(); err != nil {
+		t.Fatal(err)
+	}
+	token, err := session.CoopToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	solo := testSudokuSession(t, conn, sudoku)
+
+	tests := []struct {
+		name      string
+		session   model.SudokuSession
+		owner     bool
+		token     string
+		wantError websocketErrorCode
+	}{
+		{name: "owner", session: session, owner: true},
+		{name: "player with token", session: session, token: token},
+		{name: "player without token", session: session, wantError: websocketErrorUnauthorized},
+		{name: "player with wrong token", session: session, token: "00000000000000000000000000000000", wantError: websocketErrorUnauthorized},
+		{name: "not co-op game", session: solo, owner: true, wantError: websocketErrorValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testContext(srv)
+			if tt.owner {
+				ctx = testContext(srv, tt.session.ID().String())
+			}
+			ctx = context.WithValue(ctx, "conn", testWebsocketConn())
+			body, _ := json.Marshal(websocketJoinCoopRequest{SessionID: tt.session.ID().String(), Token: tt.token})
+			_, wsErr := websocketRequestExecute(ctx, "joinCoop", body)
+			var gotError websocketErrorCode
+			if wsErr != nil {
+				gotError = wsErr.Code
+			}
+			if gotError != tt.wantError {
+				t.Errorf("joinCoop() error = %v, want code '%s'", wsErr, tt.wantError)
+			}
+		})
+	}
+}
+
+// TestJoinCoop_Player checks that the anonymous player is bound to the connection and can not be chosen by the client.
+func TestJoinCoop_Player(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	session := testSudokuSession(t, conn, testSudoku(t, conn))
+	if err := session.SetCoop(); err != nil {
+		t.Fatal(err)
+	}
+	token, err := session.CoopToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(websocketJoinCoopRequest{SessionID: session.ID().String(), Token: token})
+	join := func(wsConn *websocketConn) string {
+		t.Helper()
+		ctx := context.WithValue(testContext(srv), "conn", wsConn)
+		resp, wsErr := websocketRequestExecute(ctx, "joinCoop", body)
+		if wsErr != nil {
+			t.Fatalf("joinCoop() error = %v", wsErr)
+		}
+		var got websocketJoinCoopResponse
+		if err := json.Unmarshal(resp, &got); err != nil {
+			t.Fatal(err)
+		}
+		return got.Player.ID
+	}
+
+	first := testWebsocketConn()
+	player := join(first)
+	if got := join(first); got != player {
+		t.Errorf("joinCoop() on the same connection player = %s, want %s", got, player)
+	}
+	if got := join(testWebsocketConn()); got == player {
+		t.Errorf("joinCoop() on another connection player = %s, want a new player", got)
+	}
+}
+
+// TestSudokuTokens checks that links of the co-op game are shown only to the owner and to players invited by the link.
+func TestSudokuTokens(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	session := testSudokuSession(t, conn, testSudoku(t, conn))
+	if err := session.SetCoop(); err != nil {
+		t.Fatal(err)
+	}
+	sessionID := session.ID().String()
+	token, err := session.CoopToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		request       *http.Request
+		wantCoopToken string
+	}{
+		{name: "owner", request: testAPIRequest(http.MethodGet, "/sudoku/"+sessionID, "", nil, sessionID), wantCoopToken: token},
+		{name: "invited player", request: testAPIRequest(http.MethodGet, "/sudoku/"+sessionID+"?coop="+token, "", nil), wantCoopToken: token},
+		{name: "stranger", request: testAPIRequest(http.MethodGet, "/sudoku/"+sessionID, "", nil)},
+		{name: "wrong token", request: testAPIRequest(http.MethodGet, "/sudoku/"+sessionID+"?coop=0", "", nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watchToken, coopToken, err := sudokuTokens(conn, tt.request, session, true)
+			if err != nil {
+				t.Fatalf("sudokuTokens() error = %v", err)
+			}
+			if coopToken != tt.wantCoopToken {
+				t.Errorf("sudokuTokens() coopToken = %q, want %q", coopToken, tt.wantCoopToken)
+			}
+			if (watchToken != "") != (tt.wantCoopToken != "") {
+				t.Errorf("sudokuTokens() watchToken = %q", watchToken)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	uuid "github.com/satori/go.uuid"
+)
+
+func init() {
+	websocketPool.Add((*websocketJoinRaceRequest)(nil), (*websocketJoinRaceResponse)(nil))
+}
+
+type websocketJoinRaceRequest struct {
+	RaceID string `json:"raceID"`
+	// Session of the anonymous player from the previous join. Optional.
+	SessionID string `json:"sessionID,omitempty"`
+}
+
+func (websocketJoinRaceRequest) Method() string {
+	return "joinRace"
+}
+
+func (websocketJoinRaceRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (websocketJoinRaceRequest) SessionKey() string {
+	return ""
+}
+
+func (r websocketJoinRaceRequest) Validate(ctx context.Context) error {
+	if r.RaceID == "" {
+		return fmt.Errorf("raceID is empty")
+	}
+	if _, err := uuid.FromString(r.RaceID); err != nil {
+		return fmt.Errorf("raceID is not UUID")
+	}
+	if r.SessionID != "" {
+		if _, err := uuid.FromString(r.SessionID); err != nil {
+			return fmt.Errorf("sessionID is not UUID")
+		}
+	}
+	return nil
+}
+
+// Execute returns the player's session of the race and subscribes the connection to the progress of other players.
+func (r websocketJoinRaceRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	conn := ctx.Value("conn").(*websocketConn)
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	race, err := model.RaceByIDString(redis, r.RaceID)
+	if err != nil {
+		return websocketJoinRaceResponse{}, errWebsocketInternal
+	}
+	if race.IsNull() {
+		return websocketJoinRaceResponse{}, newWebsocketError(websocketErrorNotFound, "race not found")
+	}
+	// IDs of sessions are visible to all players after the end of the race, so an anonymous player can only return to
+	// the session created in their browser
+	user := ctx.Value("user").(*model.User)
+	if user.IsNull() && r.SessionID != "" && !ctx.Value("anonymousSessions").(*anonymousSessions).has(r.SessionID) {
+		return websocketJoinRaceResponse{}, newWebsocketError(websocketErrorUnauthorized, "session belongs to another player")
+	}
+	session, err := race.Join(*user, r.SessionID)
+	if err != nil {
+		return websocketJoinRaceResponse{}, errWebsocketInternal
+	}
+	if session.IsNull() {
+		return websocketJoinRaceResponse{}, newWebsocketError(websocketErrorNotFound, "session not found")
+	}
+	srv.hub.subscribe(raceTopic(race), conn)
+	state, err := raceState(race)
+	if err != nil {
+		return websocketJoinRaceResponse{}, errWebsocketInternal
+	}
+	srv.hub.publish(raceTopic(race), websocketRaceProgressEvent{state})
+
+	return websocketJoinRaceResponse{
+		SessionID: session.ID().String(),
+		Race:      state,
+	}, nil
+}
+
+type websocketJoinRaceResponse struct {
+	SessionID string         `json:"sessionID"`
+	Race      data.RaceState `json:"race"`
+}
+
+func (websocketJoinRaceResponse) Method() string {
+	return "joinRace"
+}
+
+func (r websocketJoinRaceResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketJoinRaceResponse) Execute(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,204 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/sudoku/internal/sudoku_classic"
+	uuid "github.com/satori/go.uuid"
+	"sort"
+	"time"
+)
+
+func init() {
+	websocketPool.Add((*websocketMakeMoveRequest)(nil), (*websocketMakeMoveResponse)(nil))
+	websocketEvents.Add((*websocketCoopMoveEvent)(nil))
+}
+
+// websocketMakeMoveRequest changes one cell of the cooperative session. Version is the version of the cell that the
+// player saw before the move.
+type websocketMakeMoveRequest struct {
+	SessionID string     `json:"sessionID"`
+	Point     data.Point `json:"point"`
+	Digit     int8       `json:"digit"`
+	Marks     []int8     `json:"marks,omitempty"`
+	Version   int64      `json:"version"`
+}
+
+func (websocketMakeMoveRequest) Method() string {
+	return "makeMove"
+}
+
+func (websocketMakeMoveRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketMakeMoveRequest) SessionKey() string {
+	return r.SessionID
+}
+
+func (r websocketMakeMoveRequest) Validate(ctx context.Context) error {
+	if r.SessionID == "" {
+		return fmt.Errorf("sessionID is empty")
+	}
+	if _, err := uuid.FromString(r.SessionID); err != nil {
+		return fmt.Errorf("sessionID is not UUID")
+	}
+	if !r.Point.IsValid() {
+		return fmt.Errorf("point is out of grid")
+	}
+	if r.Digit < 0 || r.Digit > 9 {
+		return fmt.Errorf("digit is invalid")
+	}
+	if r.Digit > 0 && len(r.Marks) > 0 {
+		return fmt.Errorf("marks are only allowed in empty cell")
+	}
+	unique := make(map[int8]struct{})
+	for _, m := range r.Marks {
+		if m < 1 || m > 9 {
+			return fmt.Errorf("mark is invalid")
+		}
+		if _, ok := unique[m]; ok {
+			return fmt.Errorf("marks are repeated")
+		}
+		unique[m] = struct{}{}
+	}
+	if r.Version < 0 {
+		return fmt.Errorf("version is invalid")
+	}
+	return nil
+}
+
+// Execute applies the move and sends it to all players of the session. If another player has changed the cell after
+// the version seen by the player, the move is rejected and the current cell is returned.
+func (r websocketMakeMoveRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	conn := ctx.Value("conn").(*websocketConn)
+	redis := srv.redis.Get()
+	defer redis.Close()
+	now := time.Now()
+
+	player, ok := conn.coopPlayer(r.SessionID)
+	if !ok {
+		return websocketMakeMoveResponse{}, newWebsocketError(websocketErrorUnauthorized, "join the co-op game first")
+	}
+	session, timer, err := activeSudokuSession(ctx, redis, r.SessionID, now)
+	if err != nil {
+		return websocketMakeMoveResponse{}, err
+	}
+	puzzleStr, err := session.Sudoku().Puzzle()
+	if err != nil {
+		return websocketMakeMoveResponse{}, errWebsocketInternal
+	}
+	if sudoku_classic.PuzzleFromString(puzzleStr).In(r.Point) != 0 {
+		return websocketMakeMoveResponse{}, newWebsocketError(websocketErrorValidation, "cell is hint of puzzle")
+	}
+
+	marks := append([]int8(nil), r.Marks...)
+	sort.Slice(marks, func(i, j int) bool {
+		return marks[i] < marks[j]
+	})
+	move, applied, err := session.ApplyMove(data.SudokuMove{
+		SudokuCell: data.SudokuCell{
+			Point:   r.Point,
+			Digit:   r.Digit,
+			Marks:   marks,
+			Version: r.Version,
+		},
+		Player: player.ID,
+		At:     now,
+	})
+	if err != nil {
+		return websocketMakeMoveResponse{}, errWebsocketInternal
+	}
+	if !applied {
+		cell, err := session.Cell(r.Point)
+		if err != nil {
+			return websocketMakeMoveResponse{}, errWebsocketInternal
+		}
+		return websocketMakeMoveResponse{
+			Cell:  cell,
+			Timer: newWebsocketTimer(timer, now),
+		}, nil
+	}
+
+	boardStr, err := session.Sudoku().Board()
+	if err != nil {
+		return websocketMakeMoveResponse{}, errWebsocketInternal
+	}
+	if r.Digit != 0 && r.Digit != sudoku_classic.PuzzleFromString(boardStr).In(r.Point) {
+		if err := session.IncrMistakes(1); err != nil {
+			return websocketMakeMoveResponse{}, errWebsocketInternal
+		}
+	}
+
+	event := websocketCoopMoveEvent{
+		Move:  move,
+		Timer: newWebsocketTimer(timer, now),
+	}
+	stateStr, err := session.State()
+	if err != nil {
+		return websocketMakeMoveResponse{}, errWebsocketInternal
+	}
+	if sudoku_classic.PuzzleFromString(stateStr).IsCorrectSolve() {
+		// WIN
+		solveTime, score, err := srv.finishSudokuSession(session, now)
+		if err == errSudokuSessionSolved {
+			return websocketMakeMoveResponse{}, err
+		}
+		if err != nil {
+			return websocketMakeMoveResponse{}, errWebsocketInternal
+		}
+		event.Win, event.Time, event.Score = true, solveTime.Milliseconds(), score.Points
+		event.Timer = websocketTimer{
+			Elapsed: solveTime.Milliseconds(),
+			Paused:  true,
+		}
+	}
+	srv.hub.publish(sessionTopic(r.SessionID), event)
+
+	return websocketMakeMoveResponse{
+		Applied: true,
+		Cell:    move.SudokuCell,
+		Win:     event.Win,
+		Time:    event.Time,
+		Score:   event.Score,
+		Timer:   event.Timer,
+	}, nil
+}
+
+type websocketMakeMoveResponse struct {
+	// Applied is false if the cell was changed by another player.
+	Applied bool `json:"applied"`
+	// Cell is the current value of the cell.
+	Cell  data.SudokuCell `json:"cell"`
+	Win   bool            `json:"win,omitempty"`
+	Time  int64           `json:"time,omitempty"`
+	Score int64           `json:"score,omitempty"`
+	Timer websocketTimer  `json:"timer"`
+}
+
+func (websocketMakeMoveResponse) Method() string {
+	return "makeMove"
+}
+
+func (r websocketMakeMoveResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketMakeMoveResponse) Execute(ctx context.Context) error {
+	return nil
+}
+
+// websocketCoopMoveEvent is sent to all players of the cooperative session after each applied move.
+type websocketCoopMoveEvent struct {
+	Move  data.SudokuMove `json:"move"`
+	Win   bool            `json:"win,omitempty"`
+	Time  int64           `json:"time,omitempty"`
+	Score int64           `json:"score,omitempty"`
+	Timer websocketTimer  `json:"timer"`
+}
+
+func (websocketCoopMoveEvent) Event() string {
+	return "coopMove"
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"github.com/cnblvr/sudoku/data"
-	"github.com/cnblvr/sudoku/model"
 	"github.com/cnblvr/sudoku/sudoku/internal/sudoku_classic"
 	uuid "github.com/satori/go.uuid"
 	"sort"
+	"time"
 )
 
 func init() {
@@ -23,6 +23,14 @@ func (websocketMakeStepRequest) Method() string {
 	return "makeStep"
 }
 
+func (websocketMakeStepRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketMakeStepRequest) SessionKey() string {
+	return r.SessionID
+}
+
 func (r websocketMakeStepRequest) Validate(ctx context.Context) error {
 	if r.SessionID == "" {
 		return fmt.Errorf("sessionID is empty")
@@ -30,9 +38,14 @@ func (r websocketMakeStepRequest) Validate(ctx context.Context) error {
 	if _, err := uuid.FromString(r.SessionID); err != nil {
 		return fmt.Errorf("sessionID is not UUID")
 	}
-	if len(r.State) < 81 {
+	if len(r.State) != 81 {
 		return fmt.Errorf("state format invalid")
 	}
+	for _, ch := range r.State {
+		if !('0' <= ch && ch <= '9') && ch != '.' {
+			return fmt.Errorf("state format invalid")
+		}
+	}
 	return nil
 }
 
@@ -40,37 +53,90 @@ func (r websocketMakeStepRequest) Execute(ctx context.Context) (websocketRespons
 	srv := ctx.Value("srv").(*Service)
 	redis := srv.redis.Get()
 	defer redis.Close()
+	now := time.Now()
 
 	uniqueErrs := make(map[data.Point]struct{})
 
-	session, err := model.SudokuSessionByIDString(redis, r.SessionID)
+	session, timer, err := activeSudokuSession(ctx, redis, r.SessionID, now)
+	if err != nil {
+		return websocketMakeStepResponse{}, err
+	}
+	if err := rejectCoopSudokuSession(session); err != nil {
+		return websocketMakeStepResponse{}, err
+	}
+
+	puzzleStr, err := session.Sudoku().Puzzle()
 	if err != nil {
-		return websocketMakeStepResponse{}, fmt.Errorf("internal server error")
+		return websocketMakeStepResponse{}, errWebsocketInternal
+	}
+	userState := sudoku_classic.PuzzleFromString(r.State)
+	if changed := userState.FindChangedHints(sudoku_classic.PuzzleFromString(puzzleStr)); len(changed) > 0 {
+		return websocketMakeStepResponse{}, newWebsocketError(websocketErrorValidation, "state changes hints of puzzle")
 	}
 
+	prevStateStr, err := session.State()
+	if err != nil {
+		return websocketMakeStepResponse{}, errWebsocketInternal
+	}
+	if prevStateStr == "" {
+		prevStateStr = puzzleStr
+	}
 	boardStr, err := session.Sudoku().Board()
 	if err != nil {
-		return websocketMakeStepResponse{}, fmt.Errorf("internal server error")
+		return websocketMakeStepResponse{}, errWebsocketInternal
+	}
+	board := sudoku_classic.PuzzleFromString(boardStr)
+	if mistakes := countMistakes(sudoku_classic.PuzzleFromString(prevStateStr), userState, board); mistakes > 0 {
+		if err := session.IncrMistakes(mistakes); err != nil {
+			return websocketMakeStepResponse{}, errWebsocketInternal
+		}
+	}
+	if err := session.SetState(r.State); err != nil {
+		return websocketMakeStepResponse{}, errWebsocketInternal
 	}
-	if r.State == boardStr {
+
+	if userState.IsCorrectSolve() {
 		// WIN
-		return websocketMakeStepResponse{
-			Win: true,
-		}, nil
+		solveTime, score, err := srv.finishSudokuSession(session, now)
+		if err == errSudokuSessionSolved {
+			return websocketMakeStepResponse{}, err
+		}
+		if err != nil {
+			return websocketMakeStepResponse{}, errWebsocketInternal
+		}
+		resp := websocketMakeStepResponse{
+			Win:   true,
+			Time:  solveTime.Milliseconds(),
+			Score: score.Points,
+			Timer: websocketTimer{
+				Elapsed: solveTime.Milliseconds(),
+				Paused:  true,
+			},
+		}
+		if err := srv.recordSudokuSessionMoves(session, prevStateStr, r.State, now, resp.Timer, true); err != nil {
+			return websocketMakeStepResponse{}, errWebsocketInternal
+		}
+		srv.broadcastRace(redis, session)
+		return resp, nil
 	}
 
-	userState := sudoku_classic.PuzzleFromString(r.State)
+	if err := srv.recordSudokuSessionMoves(session, prevStateStr, r.State, now, newWebsocketTimer(timer, now), false); err != nil {
+		return websocketMakeStepResponse{}, errWebsocketInternal
+	}
+	srv.broadcastRace(redis, session)
+
 	for _, p := range userState.FindUserErrors() {
 		uniqueErrs[p] = struct{}{}
 	}
 
 	// TODO new method "compare with answer" and use this function
-	//board := sudoku_classic.PuzzleFromString(boardStr)
 	//for _, p := range board.FindErrors(userState) {
 	//	uniqueErrs[p] = struct{}{}
 	//}
 
-	resp := websocketMakeStepResponse{}
+	resp := websocketMakeStepResponse{
+		Timer: newWebsocketTimer(timer, now),
+	}
 	for p := range uniqueErrs {
 		resp.Errors = append(resp.Errors, p)
 	}
@@ -85,8 +151,11 @@ func (r websocketMakeStepRequest) Execute(ctx context.Context) (websocketRespons
 
 // TODO handle and test
 type websocketMakeStepResponse struct {
-	Errors []data.Point `json:"errors,omitempty"`
-	Win    bool         `json:"win,omitempty"`
+	Errors []data.Point   `json:"errors,omitempty"`
+	Win    bool           `json:"win,omitempty"`
+	Time   int64          `json:"time,omitempty"`
+	Score  int64          `json:"score,omitempty"`
+	Timer  websocketTimer `json:"timer"`
 }
 
 func (websocketMakeStepResponse) Method() string {
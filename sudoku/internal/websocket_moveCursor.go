@@ -0,0 +1,79 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	uuid "github.com/satori/go.uuid"
+)
+
+func init() {
+	websocketPool.Add((*websocketMoveCursorRequest)(nil), (*websocketMoveCursorResponse)(nil))
+	websocketEvents.Add((*websocketCoopCursorEvent)(nil))
+}
+
+type websocketMoveCursorRequest struct {
+	SessionID string     `json:"sessionID"`
+	Point     data.Point `json:"point"`
+}
+
+func (websocketMoveCursorRequest) Method() string {
+	return "moveCursor"
+}
+
+func (websocketMoveCursorRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorUnauthorized}
+}
+
+func (r websocketMoveCursorRequest) Validate(ctx context.Context) error {
+	if r.SessionID == "" {
+		return fmt.Errorf("sessionID is empty")
+	}
+	if _, err := uuid.FromString(r.SessionID); err != nil {
+		return fmt.Errorf("sessionID is not UUID")
+	}
+	if !r.Point.IsValid() {
+		return fmt.Errorf("point is out of grid")
+	}
+	return nil
+}
+
+// Execute shows the cursor of the player to other players of the cooperative session. The cursor is not saved.
+func (r websocketMoveCursorRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	conn := ctx.Value("conn").(*websocketConn)
+
+	player, ok := conn.coopPlayer(r.SessionID)
+	if !ok {
+		return websocketMoveCursorResponse{}, newWebsocketError(websocketErrorUnauthorized, "join the co-op game first")
+	}
+	srv.hub.publish(sessionTopic(r.SessionID), websocketCoopCursorEvent{
+		Player: player,
+		Point:  r.Point,
+	})
+	return websocketMoveCursorResponse{}, nil
+}
+
+type websocketMoveCursorResponse struct{}
+
+func (websocketMoveCursorResponse) Method() string {
+	return "moveCursor"
+}
+
+func (r websocketMoveCursorResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketMoveCursorResponse) Execute(ctx context.Context) error {
+	return nil
+}
+
+// websocketCoopCursorEvent is sent to all players of the cooperative session when a player selects a cell.
+type websocketCoopCursorEvent struct {
+	Player data.CoopPlayer `json:"player"`
+	Point  data.Point      `json:"point"`
+}
+
+func (websocketCoopCursorEvent) Event() string {
+	return "coopCursor"
+}
@@ -0,0 +1,79 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	uuid "github.com/satori/go.uuid"
+	"time"
+)
+
+func init() {
+	websocketPool.Add((*websocketPauseRequest)(nil), (*websocketPauseResponse)(nil))
+}
+
+type websocketPauseRequest struct {
+	SessionID string `json:"sessionID"`
+}
+
+func (websocketPauseRequest) Method() string {
+	return "pause"
+}
+
+func (websocketPauseRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketPauseRequest) SessionKey() string {
+	return r.SessionID
+}
+
+func (r websocketPauseRequest) Validate(ctx context.Context) error {
+	if r.SessionID == "" {
+		return fmt.Errorf("sessionID is empty")
+	}
+	if _, err := uuid.FromString(r.SessionID); err != nil {
+		return fmt.Errorf("sessionID is not UUID")
+	}
+	return nil
+}
+
+func (r websocketPauseRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	redis := srv.redis.Get()
+	defer redis.Close()
+	now := time.Now()
+
+	session, timer, err := activeSudokuSession(ctx, redis, r.SessionID, now)
+	if err != nil {
+		return websocketPauseResponse{}, err
+	}
+	timer.PauseByPlayer(now)
+	if err := session.SetTimer(timer); err != nil {
+		return websocketPauseResponse{}, errWebsocketInternal
+	}
+
+	resp := websocketPauseResponse{
+		Timer: newWebsocketTimer(timer, now),
+	}
+	srv.hub.publish(sessionTopic(session.ID().String()), websocketTimerTickEvent{
+		SessionID: session.ID().String(),
+		Timer:     resp.Timer,
+	})
+	return resp, nil
+}
+
+type websocketPauseResponse struct {
+	Timer websocketTimer `json:"timer"`
+}
+
+func (websocketPauseResponse) Method() string {
+	return "pause"
+}
+
+func (r websocketPauseResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketPauseResponse) Execute(ctx context.Context) error {
+	return nil
+}
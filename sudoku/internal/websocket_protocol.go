@@ -0,0 +1,97 @@
+package sudoku
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Versions of the websocket protocol. The client chooses the version with the subprotocol of the handshake, the first
+// version is used if the client has not chosen any.
+//
+// In the first version errors are strings. In the second version errors are objects with the code and the message,
+// and a frame can contain an array of requests that are answered with an array of responses in the same order.
+const (
+	websocketProtocolV1 = 1
+	websocketProtocolV2 = 2
+)
+
+// websocketSubprotocols are names of versions of the protocol, the newest version first.
+var websocketSubprotocols = []string{"sudoku.v2", "sudoku.v1"}
+
+// websocketProtocolVersion returns the version of the protocol by the negotiated subprotocol.
+func websocketProtocolVersion(subprotocol string) int {
+	switch subprotocol {
+	case "sudoku.v2":
+		return websocketProtocolV2
+	default:
+		return websocketProtocolV1
+	}
+}
+
+const (
+	// websocketBatchLimit is the maximum number of requests in one frame.
+	websocketBatchLimit = 20
+	// websocketRateLimit is the number of requests per second that a client can send for a long time.
+	websocketRateLimit = 20
+	// websocketRateBurst is the number of requests that a client can send at once.
+	websocketRateBurst = 40
+)
+
+// websocketRateLimiter is a token bucket of requests of the connection.
+type websocketRateLimiter struct {
+	tokens float64
+	at     time.Time
+}
+
+// allow takes a token for the request. Returns false if the client has exceeded the limit.
+func (l *websocketRateLimiter) allow(now time.Time) bool {
+	if l.at.IsZero() {
+		l.tokens = websocketRateBurst
+	} else {
+		l.tokens += now.Sub(l.at).Seconds() * websocketRateLimit
+		if l.tokens > websocketRateBurst {
+			l.tokens = websocketRateBurst
+		}
+	}
+	l.at = now
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// websocketMessageV1 is the message of the first version of the protocol.
+type websocketMessageV1 struct {
+	Method string          `json:"method,omitempty"`
+	Event  string          `json:"event,omitempty"`
+	Echo   string          `json:"echo,omitempty"`
+	Error  string          `json:"error,omitempty"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
+
+// encodeWebsocketMessages returns the frame of messages in the version of the protocol. A batch is encoded as an
+// array even if it contains one message.
+func encodeWebsocketMessages(version int, batch bool, msgs ...websocketMessage) ([]byte, error) {
+	var values []interface{}
+	for _, msg := range msgs {
+		if version == websocketProtocolV1 {
+			msgV1 := websocketMessageV1{
+				Method: msg.Method,
+				Event:  msg.Event,
+				Echo:   msg.Echo,
+				Body:   msg.Body,
+			}
+			if msg.Error != nil {
+				msgV1.Error = msg.Error.Message
+			}
+			values = append(values, msgV1)
+			continue
+		}
+		values = append(values, msg)
+	}
+	if !batch && len(values) == 1 {
+		return json.Marshal(values[0])
+	}
+	return json.Marshal(values)
+}
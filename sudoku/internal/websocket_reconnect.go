@@ -0,0 +1,213 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	uuid "github.com/satori/go.uuid"
+	"strings"
+)
+
+func init() {
+	websocketPool.Add((*websocketReconnectRequest)(nil), (*websocketReconnectResponse)(nil))
+	websocketPool.Add((*websocketAckRequest)(nil), (*websocketAckResponse)(nil))
+	websocketEvents.Add((*websocketConnectedEvent)(nil))
+	websocketEvents.Add((*websocketConnectionResumedEvent)(nil))
+}
+
+// connectionTopic returns the websocket topic of the connection itself. It is used to stop the dropped connection
+// when the client resumes it on another connection.
+func connectionTopic(token string) string {
+	return fmt.Sprintf("connection:%s", token)
+}
+
+// websocketConnectedEvent is the first event of the connection. The client resumes the connection by the token after
+// reconnecting.
+type websocketConnectedEvent struct {
+	Token   string `json:"token"`
+	Version int    `json:"version"`
+}
+
+func (websocketConnectedEvent) Event() string {
+	return "connected"
+}
+
+// websocketConnectionResumedEvent is sent to the previous connection of the client when it is resumed. The hub stops
+// the previous connection after this event.
+type websocketConnectionResumedEvent struct{}
+
+func (websocketConnectionResumedEvent) Event() string {
+	return "connectionResumed"
+}
+
+// websocketReconnectRequest resumes the dropped connection of the same user: the connection is subscribed to the
+// topics of the previous one, plays its co-op games and anonymous sessions and receives the events missed after the
+// sequence number Seq. Events can be received twice if they were sent while the connection was resumed.
+type websocketReconnectRequest struct {
+	Token string `json:"token"`
+	Seq   int64  `json:"seq"`
+}
+
+func (websocketReconnectRequest) Method() string {
+	return "reconnect"
+}
+
+func (websocketReconnectRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketReconnectRequest) Validate(ctx context.Context) error {
+	if r.Token == "" {
+		return fmt.Errorf("token is empty")
+	}
+	if _, err := uuid.FromString(r.Token); err != nil {
+		return fmt.Errorf("token is not UUID")
+	}
+	if r.Seq < 0 {
+		return fmt.Errorf("seq is invalid")
+	}
+	if r.Token == ctx.Value("conn").(*websocketConn).token.String() {
+		return fmt.Errorf("connection is already active")
+	}
+	return nil
+}
+
+func (r websocketReconnectRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	conn := ctx.Value("conn").(*websocketConn)
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	resume := model.WebsocketResumeByToken(redis, uuid.FromStringOrNil(r.Token))
+	userID, isExists, err := resume.UserID()
+	if err != nil {
+		return websocketReconnectResponse{}, errWebsocketInternal
+	}
+	if !isExists {
+		return websocketReconnectResponse{}, newWebsocketError(websocketErrorNotFound, "connection expired")
+	}
+	if userID != ctx.Value("auth").(*data.Auth).ID {
+		return websocketReconnectResponse{}, newWebsocketError(websocketErrorUnauthorized, "connection belongs to another user")
+	}
+	topics, err := resume.Topics()
+	if err != nil {
+		return websocketReconnectResponse{}, errWebsocketInternal
+	}
+	if len(topics) == 0 {
+		return websocketReconnectResponse{}, newWebsocketError(websocketErrorNotFound, "connection expired")
+	}
+	// sessions are restored before topics, because topics of sessions are checked by players
+	sessionIDs, err := resume.AnonymousSessions()
+	if err != nil {
+		return websocketReconnectResponse{}, errWebsocketInternal
+	}
+	sessions := ctx.Value("anonymousSessions").(*anonymousSessions)
+	for _, id := range sessionIDs {
+		if !sessions.has(id) {
+			sessions.add(id)
+		}
+	}
+	conn.updateResume("anonymous sessions", func(resume model.WebsocketResume) error {
+		return resume.AddAnonymousSessions(sessionIDs...)
+	})
+	players, err := resume.CoopPlayers()
+	if err != nil {
+		return websocketReconnectResponse{}, errWebsocketInternal
+	}
+	for sessionID, player := range players {
+		conn.setCoopPlayer(sessionID, player)
+	}
+	// the connection is subscribed before the previous one is stopped, so no events are lost
+	for _, topic := range topics {
+		if topic == websocketTopicAll || strings.HasPrefix(topic, connectionTopic("")) {
+			continue
+		}
+		if err := checkTopic(ctx, redis, topic); err != nil {
+			continue
+		}
+		srv.hub.subscribe(topic, conn)
+	}
+	srv.hub.publish(connectionTopic(r.Token), websocketConnectionResumedEvent{})
+
+	events, err := resume.Events(r.Seq)
+	if err != nil {
+		return websocketReconnectResponse{}, errWebsocketInternal
+	}
+	resp := websocketReconnectResponse{
+		Events: make([]json.RawMessage, 0, len(events)),
+	}
+	for _, event := range events {
+		resp.Events = append(resp.Events, event)
+	}
+	return resp, nil
+}
+
+type websocketReconnectResponse struct {
+	// Events are the missed events in order of their sequence numbers.
+	Events []json.RawMessage `json:"events"`
+}
+
+func (websocketReconnectResponse) Method() string {
+	return "reconnect"
+}
+
+func (r websocketReconnectResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketReconnectResponse) Execute(ctx context.Context) error {
+	return nil
+}
+
+// websocketAckRequest acknowledges events of the connection up to the sequence number Seq. Acknowledged events are
+// not saved for resuming anymore.
+type websocketAckRequest struct {
+	Seq int64 `json:"seq"`
+}
+
+func (websocketAckRequest) Method() string {
+	return "ack"
+}
+
+func (websocketAckRequest) Errors() []websocketErrorCode {
+	return nil
+}
+
+func (r websocketAckRequest) Validate(ctx context.Context) error {
+	if r.Seq <= 0 {
+		return fmt.Errorf("seq is invalid")
+	}
+	return nil
+}
+
+func (r websocketAckRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	conn := ctx.Value("conn").(*websocketConn)
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	if err := model.WebsocketResumeByToken(redis, conn.token).Ack(r.Seq); err != nil {
+		return websocketAckResponse{}, errWebsocketInternal
+	}
+	return websocketAckResponse{
+		Seq: r.Seq,
+	}, nil
+}
+
+type websocketAckResponse struct {
+	Seq int64 `json:"seq"`
+}
+
+func (websocketAckResponse) Method() string {
+	return "ack"
+}
+
+func (r websocketAckResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketAckResponse) Execute(ctx context.Context) error {
+	return nil
+}
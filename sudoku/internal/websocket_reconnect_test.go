@@ -0,0 +1,80 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	uuid "github.com/satori/go.uuid"
+	"testing"
+)
+
+// testResumableWebsocketConn returns the connection that saves its state for resuming.
+func testResumableWebsocketConn(srv *Service) *websocketConn {
+	conn := testWebsocketConn()
+	conn.redis = srv.redis
+	conn.token = uuid.NewV4()
+	return conn
+}
+
+// TestReconnect checks that the connection is resumed by the same user with topics, co-op players, anonymous sessions
+// and missed events of the previous connection.
+func TestReconnect(t *testing.T) {
+	srv := testService(t)
+	redis := srv.redis.Get()
+	defer redis.Close()
+	session := testSudokuSession(t, redis, testSudoku(t, redis))
+	sessionID := session.ID().String()
+	player := data.CoopPlayer{ID: "player", Color: data.CoopColors[0]}
+
+	prev := testResumableWebsocketConn(srv)
+	prev.updateResume("user", func(resume model.WebsocketResume) error {
+		if err := resume.SetUser(0); err != nil {
+			return err
+		}
+		return resume.AddAnonymousSessions(sessionID)
+	})
+	prev.setCoopPlayer(sessionID, player)
+	srv.hub.subscribe(sessionTopic(sessionID), prev)
+	if err := prev.push(websocketSessionExpiredEvent{SessionID: sessionID}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("another user", func(t *testing.T) {
+		ctx := context.WithValue(testContext(srv), "auth", &data.Auth{IsAuthorized: true, ID: 1})
+		ctx = context.WithValue(ctx, "conn", testResumableWebsocketConn(srv))
+		body, _ := json.Marshal(websocketReconnectRequest{Token: prev.token.String()})
+		if _, wsErr := websocketRequestExecute(ctx, "reconnect", body); wsErr == nil || wsErr.Code != websocketErrorUnauthorized {
+			t.Errorf("reconnect() error = %v, want code '%s'", wsErr, websocketErrorUnauthorized)
+		}
+	})
+	t.Run("same user", func(t *testing.T) {
+		conn := testResumableWebsocketConn(srv)
+		ctx := context.WithValue(testContext(srv), "conn", conn)
+		body, _ := json.Marshal(websocketReconnectRequest{Token: prev.token.String()})
+		respBody, wsErr := websocketRequestExecute(ctx, "reconnect", body)
+		if wsErr != nil {
+			t.Fatalf("reconnect() error = %v", wsErr)
+		}
+		var resp websocketReconnectResponse
+		if err := json.Unmarshal(respBody, &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Events) != 1 {
+			t.Errorf("reconnect() returns %d events, want 1", len(resp.Events))
+		}
+		if got, ok := conn.coopPlayer(sessionID); !ok || got != player {
+			t.Errorf("co-op player = %v, %v, want %v, true", got, ok, player)
+		}
+		if !ctx.Value("anonymousSessions").(*anonymousSessions).has(sessionID) {
+			t.Errorf("anonymous session %s is not restored", sessionID)
+		}
+		subscribed := false
+		for _, c := range srv.hub.conns(sessionTopic(sessionID)) {
+			subscribed = subscribed || c == conn
+		}
+		if !subscribed {
+			t.Errorf("connection is not subscribed to the topic of the session")
+		}
+	})
+}
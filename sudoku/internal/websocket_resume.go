@@ -0,0 +1,75 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	uuid "github.com/satori/go.uuid"
+	"time"
+)
+
+func init() {
+	websocketPool.Add((*websocketResumeRequest)(nil), (*websocketResumeResponse)(nil))
+}
+
+type websocketResumeRequest struct {
+	SessionID string `json:"sessionID"`
+}
+
+func (websocketResumeRequest) Method() string {
+	return "resume"
+}
+
+func (websocketResumeRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketResumeRequest) SessionKey() string {
+	return r.SessionID
+}
+
+func (r websocketResumeRequest) Validate(ctx context.Context) error {
+	if r.SessionID == "" {
+		return fmt.Errorf("sessionID is empty")
+	}
+	if _, err := uuid.FromString(r.SessionID); err != nil {
+		return fmt.Errorf("sessionID is not UUID")
+	}
+	return nil
+}
+
+func (r websocketResumeRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	redis := srv.redis.Get()
+	defer redis.Close()
+	now := time.Now()
+
+	session, timer, err := activeSudokuSession(ctx, redis, r.SessionID, now)
+	if err != nil {
+		return websocketResumeResponse{}, err
+	}
+
+	resp := websocketResumeResponse{
+		Timer: newWebsocketTimer(timer, now),
+	}
+	srv.hub.publish(sessionTopic(session.ID().String()), websocketTimerTickEvent{
+		SessionID: session.ID().String(),
+		Timer:     resp.Timer,
+	})
+	return resp, nil
+}
+
+type websocketResumeResponse struct {
+	Timer websocketTimer `json:"timer"`
+}
+
+func (websocketResumeResponse) Method() string {
+	return "resume"
+}
+
+func (r websocketResumeResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketResumeResponse) Execute(ctx context.Context) error {
+	return nil
+}
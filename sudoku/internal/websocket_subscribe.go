@@ -0,0 +1,63 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+)
+
+func init() {
+	websocketPool.Add((*websocketSubscribeRequest)(nil), (*websocketSubscribeResponse)(nil))
+}
+
+// websocketSubscribeRequest subscribes the connection to events of the topic. Topics are "session:<id>", "race:<id>",
+// "leaderboard:<level>:<period>" and "user:<id>" of the logged-in user.
+type websocketSubscribeRequest struct {
+	Topic string `json:"topic"`
+}
+
+func (websocketSubscribeRequest) Method() string {
+	return "subscribe"
+}
+
+func (websocketSubscribeRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketSubscribeRequest) Validate(ctx context.Context) error {
+	if r.Topic == "" {
+		return fmt.Errorf("topic is empty")
+	}
+	return nil
+}
+
+func (r websocketSubscribeRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	conn := ctx.Value("conn").(*websocketConn)
+	redis := srv.redis.Get()
+	defer redis.Close()
+
+	if err := checkTopic(ctx, redis, r.Topic); err != nil {
+		return websocketSubscribeResponse{}, err
+	}
+	srv.hub.subscribe(r.Topic, conn)
+
+	return websocketSubscribeResponse{
+		Topic: r.Topic,
+	}, nil
+}
+
+type websocketSubscribeResponse struct {
+	Topic string `json:"topic"`
+}
+
+func (websocketSubscribeResponse) Method() string {
+	return "subscribe"
+}
+
+func (r websocketSubscribeResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketSubscribeResponse) Execute(ctx context.Context) error {
+	return nil
+}
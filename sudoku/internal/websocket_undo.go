@@ -0,0 +1,97 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	uuid "github.com/satori/go.uuid"
+	"time"
+)
+
+func init() {
+	websocketPool.Add((*websocketUndoRequest)(nil), (*websocketUndoResponse)(nil))
+}
+
+type websocketUndoRequest struct {
+	SessionID string `json:"sessionID"`
+}
+
+func (websocketUndoRequest) Method() string {
+	return "undo"
+}
+
+func (websocketUndoRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketUndoRequest) SessionKey() string {
+	return r.SessionID
+}
+
+func (r websocketUndoRequest) Validate(ctx context.Context) error {
+	if r.SessionID == "" {
+		return fmt.Errorf("sessionID is empty")
+	}
+	if _, err := uuid.FromString(r.SessionID); err != nil {
+		return fmt.Errorf("sessionID is not UUID")
+	}
+	return nil
+}
+
+// Execute restores the previous state of the puzzle.
+func (r websocketUndoRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	redis := srv.redis.Get()
+	defer redis.Close()
+	now := time.Now()
+
+	session, timer, err := activeSudokuSession(ctx, redis, r.SessionID, now)
+	if err != nil {
+		return websocketUndoResponse{}, err
+	}
+	if err := rejectCoopSudokuSession(session); err != nil {
+		return websocketUndoResponse{}, err
+	}
+	if err := rejectRaceSudokuSession(session); err != nil {
+		return websocketUndoResponse{}, err
+	}
+	prevState, err := session.State()
+	if err != nil {
+		return websocketUndoResponse{}, errWebsocketInternal
+	}
+	state, ok, err := session.UndoState()
+	if err != nil {
+		return websocketUndoResponse{}, errWebsocketInternal
+	}
+	if !ok {
+		return websocketUndoResponse{}, newWebsocketError(websocketErrorValidation, "nothing to undo")
+	}
+	if err := session.IncrUndos(); err != nil {
+		return websocketUndoResponse{}, errWebsocketInternal
+	}
+	resp := websocketUndoResponse{
+		State: state,
+		Timer: newWebsocketTimer(timer, now),
+	}
+	if err := srv.recordSudokuSessionMoves(session, prevState, state, now, resp.Timer, false); err != nil {
+		return websocketUndoResponse{}, errWebsocketInternal
+	}
+	srv.broadcastRace(redis, session)
+	return resp, nil
+}
+
+type websocketUndoResponse struct {
+	State string         `json:"state"`
+	Timer websocketTimer `json:"timer"`
+}
+
+func (websocketUndoResponse) Method() string {
+	return "undo"
+}
+
+func (r websocketUndoResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketUndoResponse) Execute(ctx context.Context) error {
+	return nil
+}
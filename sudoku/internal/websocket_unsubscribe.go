@@ -0,0 +1,58 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+)
+
+func init() {
+	websocketPool.Add((*websocketUnsubscribeRequest)(nil), (*websocketUnsubscribeResponse)(nil))
+}
+
+// websocketUnsubscribeRequest stops events of the topic for the connection.
+type websocketUnsubscribeRequest struct {
+	Topic string `json:"topic"`
+}
+
+func (websocketUnsubscribeRequest) Method() string {
+	return "unsubscribe"
+}
+
+func (websocketUnsubscribeRequest) Errors() []websocketErrorCode {
+	return nil
+}
+
+func (r websocketUnsubscribeRequest) Validate(ctx context.Context) error {
+	if r.Topic == "" {
+		return fmt.Errorf("topic is empty")
+	}
+	if r.Topic == websocketTopicAll {
+		return fmt.Errorf("topic is required")
+	}
+	return nil
+}
+
+func (r websocketUnsubscribeRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	srv.hub.unsubscribe(r.Topic, ctx.Value("conn").(*websocketConn))
+
+	return websocketUnsubscribeResponse{
+		Topic: r.Topic,
+	}, nil
+}
+
+type websocketUnsubscribeResponse struct {
+	Topic string `json:"topic"`
+}
+
+func (websocketUnsubscribeResponse) Method() string {
+	return "unsubscribe"
+}
+
+func (r websocketUnsubscribeResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketUnsubscribeResponse) Execute(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,109 @@
+package sudoku
+
+import (
+	"context"
+	"fmt"
+	"github.com/cnblvr/sudoku/data"
+	"github.com/cnblvr/sudoku/model"
+	uuid "github.com/satori/go.uuid"
+	"time"
+)
+
+func init() {
+	websocketPool.Add((*websocketWatchSessionRequest)(nil), (*websocketWatchSessionResponse)(nil))
+}
+
+type websocketWatchSessionRequest struct {
+	SessionID string `json:"sessionID"`
+	// Token of the link for spectators. Players of the session watch it without the token.
+	Token string `json:"token,omitempty"`
+}
+
+func (websocketWatchSessionRequest) Method() string {
+	return "watchSession"
+}
+
+func (websocketWatchSessionRequest) Errors() []websocketErrorCode {
+	return []websocketErrorCode{websocketErrorNotFound, websocketErrorUnauthorized}
+}
+
+func (r websocketWatchSessionRequest) Validate(ctx context.Context) error {
+	if r.SessionID == "" {
+		return fmt.Errorf("sessionID is empty")
+	}
+	if _, err := uuid.FromString(r.SessionID); err != nil {
+		return fmt.Errorf("sessionID is not UUID")
+	}
+	return nil
+}
+
+// Execute returns the current state of the session and subscribes the connection to moves of the session. The
+// spectator can not change the session and does not affect its timer. Games in progress are watched by their players
+// and by clients with the token of the link for spectators.
+func (r websocketWatchSessionRequest) Execute(ctx context.Context) (websocketResponse, error) {
+	srv := ctx.Value("srv").(*Service)
+	conn := ctx.Value("conn").(*websocketConn)
+	redis := srv.redis.Get()
+	defer redis.Close()
+	now := time.Now()
+
+	session, err := model.SudokuSessionByIDString(redis, r.SessionID)
+	if err != nil {
+		return websocketWatchSessionResponse{}, errWebsocketInternal
+	}
+	if session.IsNull() {
+		return websocketWatchSessionResponse{}, newWebsocketError(websocketErrorNotFound, "session not found")
+	}
+	if err := authorizeWatchSudokuSession(ctx, session, r.Token); err != nil {
+		return websocketWatchSessionResponse{}, err
+	}
+	puzzle, err := session.Sudoku().Puzzle()
+	if err != nil {
+		return websocketWatchSessionResponse{}, errWebsocketInternal
+	}
+	state, err := session.State()
+	if err != nil {
+		return websocketWatchSessionResponse{}, errWebsocketInternal
+	}
+	cells, err := session.Cells()
+	if err != nil {
+		return websocketWatchSessionResponse{}, errWebsocketInternal
+	}
+	status, err := session.Status()
+	if err != nil {
+		return websocketWatchSessionResponse{}, errWebsocketInternal
+	}
+	timer, err := session.Timer()
+	if err != nil {
+		return websocketWatchSessionResponse{}, errWebsocketInternal
+	}
+	srv.hub.subscribe(sessionTopic(r.SessionID), conn)
+
+	return websocketWatchSessionResponse{
+		Puzzle: puzzle,
+		State:  state,
+		Cells:  cells,
+		Status: status,
+		Timer:  newWebsocketTimer(timer, now),
+	}, nil
+}
+
+type websocketWatchSessionResponse struct {
+	Puzzle string                   `json:"puzzle"`
+	State  string                   `json:"state,omitempty"`
+	Cells  []data.SudokuCell        `json:"cells,omitempty"`
+	Status data.SudokuSessionStatus `json:"status"`
+	Timer  websocketTimer           `json:"timer"`
+}
+
+func (websocketWatchSessionResponse) Method() string {
+	return "watchSession"
+}
+
+func (r websocketWatchSessionResponse) Validate(ctx context.Context) error {
+	return nil
+}
+
+func (r websocketWatchSessionResponse) Execute(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,61 @@
+package sudoku
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/cnblvr/sudoku/model"
+	"testing"
+	"time"
+)
+
+// TestWatchSession checks that games in progress are watched only by players and clients with the token.
+func TestWatchSession(t *testing.T) {
+	srv := testService(t)
+	conn := srv.redis.Get()
+	defer conn.Close()
+	sudoku := testSudoku(t, conn)
+	session := testSudokuSession(t, conn, sudoku)
+	token, err := session.WatchToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	solved := testSudokuSession(t, conn, sudoku)
+	if _, err := solved.Solve(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		session   model.SudokuSession
+		player    bool
+		token     string
+		wantError websocketErrorCode
+	}{
+		{name: "player", session: session, player: true},
+		{name: "spectator with token", session: session, token: token},
+		{name: "spectator without token", session: session, wantError: websocketErrorUnauthorized},
+		{name: "spectator with wrong token", session: session, token: "00000000000000000000000000000000", wantError: websocketErrorUnauthorized},
+		{name: "finished game", session: solved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := testContext(srv)
+			if tt.player {
+				ctx = testContext(srv, tt.session.ID().String())
+			}
+			ctx = context.WithValue(ctx, "conn", testWebsocketConn())
+			body, _ := json.Marshal(websocketWatchSessionRequest{SessionID: tt.session.ID().String(), Token: tt.token})
+			_, wsErr := websocketRequestExecute(ctx, "watchSession", body)
+			var gotError websocketErrorCode
+			if wsErr != nil {
+				gotError = wsErr.Code
+			}
+			if gotError != tt.wantError {
+				t.Errorf("watchSession() error = %v, want code '%s'", wsErr, tt.wantError)
+			}
+			if err := checkTopic(ctx, conn, sessionTopic(tt.session.ID().String())); (err == nil) != (tt.player || tt.session == solved) {
+				t.Errorf("checkTopic() error = %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package sudoku
+
+import "sync"
+
+// websocketWorkerPool executes requests of one connection. At most websocketWorkers frames are executed at the same
+// time. Frames that change data are executed one by one in the order of the client, so the later change of the session
+// is never overwritten by the earlier one. Other frames are executed concurrently and can be answered out of order.
+type websocketWorkerPool struct {
+	workers chan struct{}
+	ordered chan func()
+	wg      sync.WaitGroup
+}
+
+func newWebsocketWorkerPool(workers int) *websocketWorkerPool {
+	p := &websocketWorkerPool{
+		workers: make(chan struct{}, workers),
+		// each queued function holds a worker, so the queue is never full
+		ordered: make(chan func(), workers),
+	}
+	go func() {
+		for f := range p.ordered {
+			f()
+		}
+	}()
+	return p
+}
+
+// execute waits for a free worker and executes f in the background. Returns false if done is closed before a worker
+// is free.
+func (p *websocketWorkerPool) execute(ordered bool, done <-chan struct{}, f func()) bool {
+	select {
+	case p.workers <- struct{}{}:
+	case <-done:
+		return false
+	}
+	p.wg.Add(1)
+	run := func() {
+		defer p.wg.Done()
+		defer func() { <-p.workers }()
+		f()
+	}
+	if ordered {
+		p.ordered <- run
+	} else {
+		go run()
+	}
+	return true
+}
+
+// close waits for the completion of executed functions. The pool cannot be used after closing.
+func (p *websocketWorkerPool) close() {
+	close(p.ordered)
+	p.wg.Wait()
+}
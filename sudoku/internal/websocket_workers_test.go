@@ -0,0 +1,69 @@
+package sudoku
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestWebsocketWorkerPool checks the limit of executed functions and the order of ordered functions.
+func TestWebsocketWorkerPool(t *testing.T) {
+	const workers, calls = 3, 20
+	pool := newWebsocketWorkerPool(workers)
+	done := make(chan struct{})
+
+	var mx sync.Mutex
+	running, maxRunning := 0, 0
+	var order []int
+	for i := 0; i < calls; i++ {
+		i := i
+		ordered := i%2 == 0
+		if !pool.execute(ordered, done, func() {
+			mx.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			if ordered {
+				order = append(order, i)
+			}
+			mx.Unlock()
+			time.Sleep(time.Millisecond)
+			mx.Lock()
+			running--
+			mx.Unlock()
+		}) {
+			t.Fatalf("execute() = false, want true")
+		}
+	}
+	pool.close()
+
+	if maxRunning > workers {
+		t.Errorf("%d functions are executed at the same time, want at most %d", maxRunning, workers)
+	}
+	if len(order) != calls/2 {
+		t.Fatalf("%d ordered functions are executed, want %d", len(order), calls/2)
+	}
+	for j, i := range order {
+		if i != j*2 {
+			t.Errorf("ordered functions are executed in order %v", order)
+			break
+		}
+	}
+}
+
+// TestWebsocketWorkerPool_Done checks that the waiting for a free worker stops when the connection is closed.
+func TestWebsocketWorkerPool_Done(t *testing.T) {
+	pool := newWebsocketWorkerPool(1)
+	done := make(chan struct{})
+	release := make(chan struct{})
+	if !pool.execute(false, done, func() { <-release }) {
+		t.Fatalf("execute() = false, want true")
+	}
+	close(done)
+	if pool.execute(false, done, func() {}) {
+		t.Errorf("execute() = true without free workers after closing, want false")
+	}
+	close(release)
+	pool.close()
+}
@@ -0,0 +1,101 @@
+let sudoku = undefined;
+let replay = undefined;
+let playing = undefined;
+
+document.addEventListener('DOMContentLoaded', () => {
+    sudoku = document.querySelector('#sudoku');
+    if (!sudoku) return;
+
+    // Creating board in table element.
+    for (let row = 0; row < 9; row++) {
+        let tr = document.createElement('tr');
+        for (let col = 0; col < 9; col++) {
+            let td = document.createElement('td');
+            td.id = String.fromCharCode('a'.charCodeAt(0)+row)+(col+1);
+            tr.appendChild(td);
+        }
+        sudoku.appendChild(tr);
+    }
+
+    let scrubber = document.querySelector('#_scrubber');
+    scrubber.addEventListener('input', () => {
+        stop();
+        renderMove(parseInt(scrubber.value));
+    });
+    document.querySelector('#_play').addEventListener('click', () => {
+        if (playing) {
+            stop();
+            return;
+        }
+        if (parseInt(scrubber.value) >= replay.moves.length) renderMove(0);
+        document.querySelector('#_play').textContent = 'Stop';
+        playNext();
+    });
+
+    let sessionID = document.querySelector('#_session').textContent;
+    fetch('/sudoku/'+sessionID+'/replay?format=json')
+        .then((resp) => resp.json())
+        .then((body) => {
+            replay = body;
+            replay.moves = replay.moves || [];
+            renderMove(0);
+        })
+        .catch((e) => console.error('replay: failed to load moves:', e));
+}, false);
+
+// renderMove renders the state of the puzzle after the first n moves.
+let renderMove = (n) => {
+    sudoku.querySelectorAll('tr').forEach((tr, row) => {
+        tr.querySelectorAll('td').forEach((td, col) => {
+            let d = replay.puzzle[row*9+col];
+            td.textContent = ('1' <= d && d <= '9') ? d : '';
+            td.classList.toggle('hint', '1' <= d && d <= '9');
+            td.classList.remove('active');
+        });
+    });
+    replay.moves.slice(0, n).forEach((move) => {
+        let td = sudoku.querySelector('#'+move.point);
+        if (!td) return;
+        td.textContent = move.digit ? String(move.digit) : '';
+        if (!move.digit && move.marks) {
+            let span = document.createElement('span');
+            span.className = 'marks';
+            span.textContent = move.marks.join(' ');
+            td.appendChild(span);
+        }
+    });
+    if (n > 0) {
+        let td = sudoku.querySelector('#'+replay.moves[n-1].point);
+        if (td) td.classList.add('active');
+    }
+    document.querySelector('#_scrubber').value = n;
+    document.querySelector('#_move').textContent = n;
+    document.querySelector('#_timer').textContent = formatTime(n > 0 ? replay.moves[n-1].offset : 0);
+}
+
+// playNext shows the next move after the real pause between moves, but not longer than a second.
+let playNext = () => {
+    let n = parseInt(document.querySelector('#_scrubber').value);
+    if (n >= replay.moves.length) {
+        stop();
+        return;
+    }
+    let delay = replay.moves[n].offset - (n > 0 ? replay.moves[n-1].offset : 0);
+    playing = setTimeout(() => {
+        renderMove(n+1);
+        playNext();
+    }, Math.min(Math.max(delay, 100), 1000));
+}
+
+let stop = () => {
+    clearTimeout(playing);
+    playing = undefined;
+    document.querySelector('#_play').textContent = 'Play';
+}
+
+let formatTime = (elapsed) => {
+    let seconds = Math.floor(elapsed / 1000);
+    let minutes = Math.floor(seconds / 60);
+    seconds %= 60;
+    return minutes + ':' + (seconds < 10 ? '0' : '') + seconds;
+}
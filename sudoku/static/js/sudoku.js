@@ -1,6 +1,14 @@
 let ws = undefined;
 let sessionID = undefined;
 let sudoku = undefined;
+// Game time received from the server and the local moment of its receipt.
+let timer = {elapsed: 0, paused: true, receivedAt: 0};
+// Co-op game: the player of this page, versions of changed cells and cursors of other players.
+let coop = undefined;
+let cellVersions = {};
+let cursors = {};
+// Spectators only receive moves of the session.
+let watch = false;
 
 document.addEventListener('DOMContentLoaded', () => {
     sudoku = document.querySelector('#sudoku');
@@ -28,9 +36,21 @@ document.addEventListener('DOMContentLoaded', () => {
         if (e.defaultPrevented) {
             return;
         }
-        let isWin = sudoku.classList.contains('win');
+        let isWin = sudoku.classList.contains('win') || timer.paused || watch;
         let changed = false;
         let td = document.querySelector('#sudoku tr td.active');
+        if (coop && !isWin && td && !td.classList.contains('hint')) {
+            let mark = e.code.match(/^(?:Digit|Numpad)([1-9])$/);
+            if (mark && (e.shiftKey || document.querySelector('#_pencil').checked)) {
+                toggleMark(td, parseInt(mark[1]));
+                apiMakeMove(td);
+                return;
+            }
+        }
+        if (e.code === 'KeyZ' && (e.ctrlKey || e.metaKey)) {
+            if (!isWin) wsApi('undo', {sessionID: sessionID});
+            return;
+        }
         switch (e.code) {
             case 'ArrowUp':    setActive(td, 'up');    break;
             case 'ArrowRight': setActive(td, 'right'); break;
@@ -49,21 +69,30 @@ document.addEventListener('DOMContentLoaded', () => {
             td.textContent = e.key;
             changed = true;
         }
-        if (changed) apiMakeStep();
+        if (changed) coop ? apiMakeMove(td) : apiMakeStep();
     });
 
     sudoku.addEventListener('api_getPuzzle', (e) => {
         let body = e.detail.body;
-        sudoku.querySelectorAll('tr').forEach((tr, row) => {
-            tr.querySelectorAll('td').forEach((td, col) => {
-                td.textContent = '';
-                let d = body.puzzle[row*9+col];
-                if ('1' <= d && d <= '9') {
-                    td.textContent = d;
-                    td.classList.add('hint');
-                }
-            });
-        });
+        setTimer(body.timer);
+        renderPuzzle(body.puzzle);
+        if (body.state) renderState(body.state);
+    });
+
+    sudoku.addEventListener('api_getHint', (e) => {
+        let body = e.detail.body;
+        renderState(body.state);
+        if (body.win) {
+            setWin(body);
+            return;
+        }
+        setTimer(body.timer);
+    });
+
+    sudoku.addEventListener('api_undo', (e) => {
+        let body = e.detail.body;
+        renderState(body.state);
+        setTimer(body.timer);
     });
 
     sudoku.addEventListener('api_makeStep', (e) => {
@@ -72,9 +101,10 @@ document.addEventListener('DOMContentLoaded', () => {
             td.classList.remove('error');
         });
         if (body.win) {
-            sudoku.classList.add('win');
+            setWin(body);
             return;
         }
+        setTimer(body.timer);
         if (!body.errors) {
             return;
         }
@@ -90,6 +120,54 @@ document.addEventListener('DOMContentLoaded', () => {
         });
     });
 
+    sudoku.addEventListener('api_pause', (e) => {
+        setTimer(e.detail.body.timer);
+    });
+    sudoku.addEventListener('api_resume', (e) => {
+        setTimer(e.detail.body.timer);
+    });
+
+    // Hint and undo of the last step.
+    document.querySelector('#_hint').addEventListener('click', () => {
+        if (sudoku.classList.contains('win') || timer.paused) return;
+        wsApi('getHint', {sessionID: sessionID});
+    });
+    document.querySelector('#_undo').addEventListener('click', () => {
+        if (sudoku.classList.contains('win') || timer.paused) return;
+        wsApi('undo', {sessionID: sessionID});
+    });
+
+    // Pause and resume of the game.
+    document.querySelector('#_pause').addEventListener('click', () => {
+        if (sudoku.classList.contains('win')) return;
+        wsApi(timer.paused ? 'resume' : 'pause', {
+            sessionID: sessionID,
+        });
+    });
+    document.addEventListener('visibilitychange', () => {
+        if (!sessionID || watch || sudoku.classList.contains('win')) return;
+        if (document.hidden && !timer.paused) {
+            wsApi('pause', {sessionID: sessionID});
+        }
+    });
+    setInterval(renderTimer, 500);
+
+    // Events of the game time and of new puzzles of the day.
+    sudoku.addEventListener('event_timerTick', (e) => {
+        let body = e.detail.body;
+        if (body.sessionID === sessionID && !sudoku.classList.contains('win')) setTimer(body.timer);
+    });
+    sudoku.addEventListener('event_sessionExpired', (e) => {
+        let body = e.detail.body;
+        if (body.sessionID === sessionID) setTimer(body.timer);
+    });
+    sudoku.addEventListener('event_dailyAvailable', (e) => {
+        let notice = document.createElement('p');
+        notice.className = 'share';
+        notice.innerHTML = 'New puzzles of the day are available on the <a href="/">main page</a>.';
+        sudoku.after(notice);
+    });
+
     // websocket
     connectWs();
     // setInterval(()=>{
@@ -99,6 +177,122 @@ document.addEventListener('DOMContentLoaded', () => {
     //     ws.send(JSON.stringify({method: 'health', echo: ''+Math.floor(Math.random() * 1e9)}));
     // }, 10000);
 
+    if (document.querySelector('#_watch')) {
+        // Spectators subscribe to moves of the session again after reconnecting.
+        watch = true;
+        sessionID = document.querySelector('#_session').textContent;
+        document.querySelectorAll('#timer button').forEach((button) => {
+            button.hidden = true;
+        });
+        let token = document.querySelector('#_watch').textContent;
+        sudoku.addEventListener('apiReady', () => {
+            wsApi('watchSession', {sessionID: sessionID, token: token});
+        });
+        sudoku.addEventListener('api_watchSession', (e) => {
+            let body = e.detail.body;
+            renderPuzzle(body.puzzle);
+            if (body.state) renderState(body.state);
+            (body.cells || []).forEach(renderCell);
+            if (body.status === 'solved') sudoku.classList.add('win');
+            setTimer(body.timer);
+        });
+        sudoku.addEventListener('event_sessionMoves', (e) => {
+            let body = e.detail.body;
+            body.moves.forEach(renderCell);
+            body.win ? setWin(body) : setTimer(body.timer);
+        });
+        sudoku.addEventListener('event_coopMove', (e) => {
+            let body = e.detail.body;
+            renderCell(body.move);
+            body.win ? setWin(body) : setTimer(body.timer);
+        });
+        return;
+    }
+
+    let race = document.querySelector('#_race');
+    if (race) {
+        // The session of the race player is received on joining. The race is joined again after reconnecting to
+        // receive the progress of other players.
+        let raceID = race.textContent;
+        // Hints and undo are not available in races.
+        document.querySelector('#_hint').hidden = true;
+        document.querySelector('#_undo').hidden = true;
+        sudoku.addEventListener('apiReady', () => {
+            wsApi('joinRace', {
+                raceID: raceID,
+                sessionID: sessionID || document.querySelector('#_session').textContent || undefined,
+            });
+        });
+        sudoku.addEventListener('api_joinRace', (e) => {
+            let body = e.detail.body;
+            let isFirst = !sessionID;
+            sessionID = body.sessionID;
+            renderRace(body.race);
+            if (isFirst) wsApi('getPuzzle', {sessionID: sessionID});
+        });
+        sudoku.addEventListener('event_raceProgress', (e) => {
+            renderRace(e.detail.body);
+        });
+        return;
+    }
+
+    if (document.querySelector('#_coop')) {
+        // The co-op game is joined again after reconnecting to receive moves and cursors of other players.
+        coop = {player: undefined, cells: []};
+        sessionID = document.querySelector('#_session').textContent;
+        let token = document.querySelector('#_coop').textContent;
+        sudoku.addEventListener('apiReady', () => {
+            wsApi('joinCoop', {
+                sessionID: sessionID,
+                token: token || undefined,
+            });
+        });
+        sudoku.addEventListener('api_joinCoop', (e) => {
+            let body = e.detail.body;
+            let isFirst = !coop.player;
+            coop.player = body.player;
+            renderCoopPlayers(body.players);
+            coop.cells = body.cells || [];
+            if (isFirst) {
+                wsApi('getPuzzle', {sessionID: sessionID});
+                return;
+            }
+            coop.cells.forEach(renderCell);
+        });
+        sudoku.addEventListener('api_getPuzzle', () => {
+            coop.cells.forEach(renderCell);
+        });
+        sudoku.addEventListener('api_makeMove', (e) => {
+            let body = e.detail.body;
+            // the rejected move is replaced by the move of another player
+            renderCell(body.cell);
+            if (body.win) {
+                setWin(body);
+                return;
+            }
+            setTimer(body.timer);
+        });
+        sudoku.addEventListener('event_coopMove', (e) => {
+            let body = e.detail.body;
+            if ((cellVersions[body.move.point] || 0) < body.move.version) renderCell(body.move);
+            if (body.win) {
+                setWin(body);
+                return;
+            }
+            setTimer(body.timer);
+        });
+        sudoku.addEventListener('event_coopPlayers', (e) => {
+            renderCoopPlayers(e.detail.body.players);
+        });
+        sudoku.addEventListener('event_coopCursor', (e) => {
+            let body = e.detail.body;
+            if (body.player.id === coop.player.id) return;
+            cursors[body.player.id] = {point: body.point, color: body.player.color};
+            renderCursors();
+        });
+        return;
+    }
+
     sudoku.addEventListener('apiReady', () => {
         sessionID = document.querySelector('#_session').textContent;
         wsApi('getPuzzle', {
@@ -137,7 +331,10 @@ let setActive = (td, dir) => {
     document.querySelectorAll('#sudoku tr td.active').forEach((active) => {
         active.classList.remove('active');
     });
-    if (!isAlreadyActive) td.classList.add('active');
+    if (!isAlreadyActive) {
+        td.classList.add('active');
+        if (coop && coop.player) wsApi('moveCursor', {sessionID: sessionID, point: td.id});
+    }
 }
 
 function getIndex(node) {
@@ -149,7 +346,7 @@ function getIndex(node) {
 }
 
 let connectWs = () => {
-    ws = new WebSocket('ws://'+location.host+'/ws');
+    ws = new WebSocket('ws://'+location.host+'/ws', ['sudoku.v2']);
     ws.onopen = (e) => {
         console.log('ws: open connection');
         sudoku.dispatchEvent(new CustomEvent('apiReady'));
@@ -157,18 +354,14 @@ let connectWs = () => {
     ws.onclose = (e) => {
         console.log('ws: close connection');
         ws = undefined;
-        // reconnect
-        setTimeout(connectWs, 3000);
+        // reconnect, the restarting server is available on another instance at once
+        setTimeout(connectWs, e.code === 1012 ? 500 : 3000);
     }
     ws.onmessage = (e) => {
         console.log('ws: receive message:', e.data);
-        let msg = JSON.parse(e.data);
-        if (!msg.method) return;
-        if (msg.error) {
-            console.error('api', msg.method, 'error:', msg.error);
-            return;
-        }
-        sudoku.dispatchEvent(new CustomEvent('api_'+msg.method, {detail: msg}));
+        let frame = JSON.parse(e.data);
+        // responses to a batch of requests come in one frame
+        (Array.isArray(frame) ? frame : [frame]).forEach((msg) => receiveMessage(msg, false));
     }
     ws.onerror = (e) => {
         console.error('ws: error '+e.code+':', e.reason, e);
@@ -176,6 +369,161 @@ let connectWs = () => {
     }
 }
 
+// The dropped connection is resumed by the token: the server sends events missed after the last received one.
+let wsResume = {token: undefined, seq: 0};
+const wsAckEvery = 20;
+
+let receiveMessage = (msg, replayed) => {
+    if (msg.event === 'connected') {
+        let prev = wsResume;
+        wsResume = {token: msg.body.token, seq: msg.seq};
+        if (prev.token) wsApi('reconnect', {token: prev.token, seq: prev.seq});
+        return;
+    }
+    if (msg.seq && !replayed) {
+        wsResume.seq = msg.seq;
+        if (msg.seq % wsAckEvery === 0) wsApi('ack', {seq: msg.seq});
+    }
+    if (msg.method === 'reconnect' && !msg.error) {
+        msg.body.events.forEach((event) => receiveMessage(event, true));
+        return;
+    }
+    if (msg.event) {
+        // event initiated by the server
+        sudoku.dispatchEvent(new CustomEvent('event_'+msg.event, {detail: msg}));
+        return;
+    }
+    if (msg.error) {
+        console.error('api', msg.method, 'error', msg.error.code+':', msg.error.message);
+        return;
+    }
+    if (!msg.method) return;
+    sudoku.dispatchEvent(new CustomEvent('api_'+msg.method, {detail: msg}));
+}
+
+let renderPuzzle = (puzzle) => {
+    sudoku.querySelectorAll('tr').forEach((tr, row) => {
+        tr.querySelectorAll('td').forEach((td, col) => {
+            td.textContent = '';
+            let d = puzzle[row*9+col];
+            if ('1' <= d && d <= '9') {
+                td.textContent = d;
+                td.classList.add('hint');
+            }
+        });
+    });
+}
+
+let renderState = (state) => {
+    sudoku.querySelectorAll('tr').forEach((tr, row) => {
+        tr.querySelectorAll('td').forEach((td, col) => {
+            if (td.classList.contains('hint')) return;
+            td.classList.remove('error');
+            let d = state[row*9+col];
+            td.textContent = ('1' <= d && d <= '9') ? d : '';
+        });
+    });
+}
+
+let renderCell = (cell) => {
+    let td = sudoku.querySelector('#'+cell.point);
+    if (!td || td.classList.contains('hint')) return;
+    cellVersions[cell.point] = cell.version;
+    td.classList.remove('error');
+    td.textContent = cell.digit ? String(cell.digit) : '';
+    if (!cell.digit && cell.marks) renderMarks(td, cell.marks);
+}
+
+let renderMarks = (td, marks) => {
+    td.textContent = '';
+    if (!marks.length) return;
+    let span = document.createElement('span');
+    span.className = 'marks';
+    span.textContent = marks.join(' ');
+    td.appendChild(span);
+}
+
+let cellMarks = (td) => {
+    let span = td.querySelector('.marks');
+    if (!span) return [];
+    return span.textContent.split(' ').filter((m) => m).map((m) => parseInt(m));
+}
+
+let toggleMark = (td, mark) => {
+    let marks = cellMarks(td);
+    if (marks.includes(mark)) {
+        marks = marks.filter((m) => m !== mark);
+    } else {
+        marks.push(mark);
+        marks.sort();
+    }
+    renderMarks(td, marks);
+}
+
+let renderCoopPlayers = (players) => {
+    let list = document.querySelector('#coop');
+    list.textContent = '';
+    players.forEach((player) => {
+        let li = document.createElement('li');
+        li.style.color = player.color;
+        li.textContent = (player.username || 'anonymous') + (player.id === coop.player.id ? ' (you)' : '');
+        list.appendChild(li);
+    });
+}
+
+let renderCursors = () => {
+    sudoku.querySelectorAll('tr td').forEach((td) => {
+        td.style.boxShadow = '';
+    });
+    Object.values(cursors).forEach((cursor) => {
+        let td = sudoku.querySelector('#'+cursor.point);
+        if (td) td.style.boxShadow = 'inset 0 0 0 3px '+cursor.color;
+    });
+}
+
+let setWin = (body) => {
+    sudoku.classList.add('win');
+    setTimer(body.timer);
+    if (body.score) document.querySelector('#_score').textContent = 'Score: ' + body.score;
+}
+
+let renderRace = (race) => {
+    let list = document.querySelector('#race');
+    list.textContent = '';
+    race.players.forEach((player) => {
+        let li = document.createElement('li');
+        let text = (player.username || 'anonymous') + (player.sessionID === sessionID ? ' (you)' : '') + ': ' + player.progress + '%';
+        if (player.solved) text += ', ' + formatTime(player.solveTime);
+        if (player.sessionID === race.winner) {
+            text += ' - winner';
+            li.classList.add('winner');
+        }
+        li.textContent = text;
+        list.appendChild(li);
+    });
+}
+
+let setTimer = (t) => {
+    if (!t) return;
+    timer = {elapsed: t.elapsed, paused: t.paused, receivedAt: Date.now()};
+    sudoku.classList.toggle('paused', timer.paused && !sudoku.classList.contains('win'));
+    document.querySelector('#_pause').textContent = timer.paused ? 'Resume' : 'Pause';
+    renderTimer();
+}
+
+let renderTimer = () => {
+    let elapsed = timer.elapsed;
+    if (!timer.paused) elapsed += Date.now() - timer.receivedAt;
+    document.querySelector('#_timer').textContent = formatTime(elapsed);
+}
+
+let formatTime = (elapsed) => {
+    let seconds = Math.floor(elapsed / 1000);
+    let minutes = Math.floor(seconds / 60);
+    seconds %= 60;
+    return minutes + ':' + (seconds < 10 ? '0' : '') + seconds;
+}
+
 let apiMakeStep = () => {
     let state = '';
     sudoku.querySelectorAll('tr td').forEach((td) => {
@@ -189,6 +537,17 @@ let apiMakeStep = () => {
     })
 }
 
+let apiMakeMove = (td) => {
+    let digit = td.querySelector('.marks') ? 0 : (parseInt(td.textContent) || 0);
+    wsApi('makeMove', {
+        sessionID: sessionID,
+        point: td.id,
+        digit: digit,
+        marks: digit ? undefined : cellMarks(td),
+        version: cellVersions[td.id] || 0,
+    });
+}
+
 let wsApi = (method, body) => {
     if (!body || !method) return;
     let msg = JSON.stringify({